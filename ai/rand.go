@@ -18,6 +18,11 @@ func NewRandom[S planning.State]() *RandomPolicy[S] {
 	}
 }
 
+// Seed робить вибір відтворюваним (headless-експерименти, тести).
+func (p *RandomPolicy[S]) Seed(seed int64) {
+	p.rng = rand.New(rand.NewSource(seed))
+}
+
 func (p *RandomPolicy[S]) Decide(ctx context.Context, current S, domain planning.Domain[S], mem planning.Memory[S]) (planning.Action, error) {
 	if domain.IsGoal(current) {
 		return "", ErrGoalReached
@@ -0,0 +1,216 @@
+// Package bdi - архітектура Belief-Desire-Intention поверх mas.BaseAgent.
+//
+// Цикл агента на кожне повідомлення:
+//  1. Сприйняття: повідомлення оновлює базу переконань (Beliefs).
+//  2. Переосмислення: якщо переконання змінились, поточний намір перевіряється
+//     (ціль досягнута? бажання ще актуальне? передумови планів виконуються?).
+//  3. Обмірковування: обирається найважливіше активне бажання.
+//  4. Вибір засобів: план з бібліотеки, а якщо нічого не підходить - MeansEnd
+//     (наприклад, planning.Domain + політика з пакета ai).
+//  5. Виконання: один крок наміру на повідомлення.
+//
+// Бажання, плани і сприйняття - це код, GOB їх не зберігає. Агент встановлює
+// їх у Setup(), як і в пакеті behaviours. Переконання зберігаються.
+package bdi
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/youryharchenko/go-mas/mas"
+)
+
+// MaxSubgoalDepth - скільки планів може бути в стеку наміру. Глибше - майже напевно
+// рекурсія (план цілі A досягає A через підцілі), і намір відкидається з помилкою.
+const MaxSubgoalDepth = 32
+
+// Agent - BDI-агент.
+type Agent struct {
+	mas.BaseAgent
+	Beliefs Beliefs
+
+	// Не зберігається: встановлюється в Setup()
+	perceive  []Perception
+	desires   []Desire
+	library   []*Plan
+	meansEnd  []MeansEnd
+	stepOn    mas.Matcher
+	intention *Intention
+}
+
+// Setuper - агент, який описує свої бажання і плани після Bind.
+type Setuper interface {
+	Setup()
+}
+
+func (a *Agent) Bind(sys *mas.System, inbox <-chan mas.Envelope, me mas.Agent) {
+	a.BaseAgent.Bind(sys, inbox, me)
+
+	if a.Beliefs == nil {
+		a.Beliefs = make(Beliefs)
+	}
+	a.perceive = []Perception{defaultPerception}
+	a.desires, a.library, a.meansEnd = nil, nil, nil
+	a.stepOn, a.intention = nil, nil
+	if s, ok := me.(Setuper); ok {
+		s.Setup()
+	}
+}
+
+// Perceive додає правило сприйняття.
+func (a *Agent) Perceive(p Perception) { a.perceive = append(a.perceive, p) }
+
+// Desire додає бажання.
+func (a *Agent) Desire(d Desire) {
+	a.desires = append(a.desires, d)
+	// Стабільне сортування: за пріоритетом, при рівності - в порядку додавання
+	sort.SliceStable(a.desires, func(i, j int) bool { return a.desires[i].Priority > a.desires[j].Priority })
+}
+
+// AddPlan додає план до бібліотеки. Плани перевіряються в порядку додавання.
+func (a *Agent) AddPlan(p Plan) { a.library = append(a.library, &p) }
+
+// UseMeansEnd додає засіб планування для цілей без готових планів.
+func (a *Agent) UseMeansEnd(m MeansEnd) { a.meansEnd = append(a.meansEnd, m) }
+
+// StepOn обмежує, які повідомлення просувають намір (наприклад, лише TICK).
+// Решта повідомлень лише оновлює переконання. За замовчуванням - всі.
+func (a *Agent) StepOn(m mas.Matcher) { a.stepOn = m }
+
+// Intention повертає поточний намір (або nil).
+func (a *Agent) Intention() *Intention { return a.intention }
+
+// Plan - один оберт BDI-циклу.
+func (a *Agent) Plan(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
+	// 1. Сприйняття
+	changed := false
+	for _, p := range a.perceive {
+		if a.Beliefs.update(p(msg, a.Beliefs)) {
+			changed = true
+		}
+	}
+	if changed {
+		a.MarkChanged()
+	}
+
+	// 2. Переосмислення
+	if changed && a.intention != nil && !a.stillCommitted() {
+		log.Printf("BDI %s: dropping intention %q", a.ID(), a.intention.Goal)
+		a.intention = nil
+	}
+
+	if a.stepOn != nil && !a.stepOn(msg) {
+		return nil, nil
+	}
+
+	// 3. Обмірковування
+	desire, ok := a.deliberate()
+	if !ok {
+		a.intention = nil
+		return nil, nil
+	}
+
+	// 4. Вибір засобів (або заміна наміру, якщо з'явилась важливіша ціль)
+	if a.intention == nil || a.intention.Goal != desire.Goal {
+		plan, err := a.selectPlan(ctx, desire.Goal)
+		if err != nil {
+			return nil, err
+		}
+		if plan == nil {
+			return nil, fmt.Errorf("bdi: no plan for goal %q", desire.Goal)
+		}
+		a.intention = &Intention{Goal: desire.Goal, stack: []frame{{plan: plan}}}
+	}
+
+	// 5. Виконання одного кроку
+	return a.step(ctx)
+}
+
+// stillCommitted - чи варто тримати поточний намір після зміни переконань.
+func (a *Agent) stillCommitted() bool {
+	for _, d := range a.desires {
+		if d.Goal == a.intention.Goal {
+			return d.active(a.Beliefs) && a.intention.valid(a.Beliefs)
+		}
+	}
+	return false
+}
+
+// deliberate обирає найважливіше активне бажання.
+func (a *Agent) deliberate() (Desire, bool) {
+	for _, d := range a.desires {
+		if d.active(a.Beliefs) {
+			return d, true
+		}
+	}
+	return Desire{}, false
+}
+
+// selectPlan шукає план у бібліотеці, потім - через MeansEnd.
+func (a *Agent) selectPlan(ctx context.Context, goal string) (*Plan, error) {
+	for _, p := range a.library {
+		if p.Goal == goal && p.applicable(a.Beliefs) {
+			return p, nil
+		}
+	}
+	for _, m := range a.meansEnd {
+		p, err := m.PlanFor(ctx, goal, a.Beliefs)
+		if err != nil {
+			return nil, err
+		}
+		if p != nil {
+			return p, nil
+		}
+	}
+	return nil, nil
+}
+
+// step виконує наступний крок верхнього плану в стеку наміру.
+func (a *Agent) step(ctx context.Context) ([]mas.Action, error) {
+	in := a.intention
+	for !in.empty() {
+		top := &in.stack[len(in.stack)-1]
+		if top.pc >= len(top.plan.Body) {
+			// План завершено - повертаємось до батьківського
+			in.stack = in.stack[:len(in.stack)-1]
+			continue
+		}
+
+		st := top.plan.Body[top.pc]
+		top.pc++
+
+		if st.Subgoal != "" {
+			if len(in.stack) >= MaxSubgoalDepth {
+				a.intention = nil
+				return nil, fmt.Errorf("bdi: subgoal %q is nested deeper than %d plans", st.Subgoal, MaxSubgoalDepth)
+			}
+			plan, err := a.selectPlan(ctx, st.Subgoal)
+			if err != nil {
+				return nil, err
+			}
+			if plan == nil {
+				a.intention = nil
+				return nil, fmt.Errorf("bdi: no plan for subgoal %q", st.Subgoal)
+			}
+			in.stack = append(in.stack, frame{plan: plan})
+			continue
+		}
+
+		actions, err := st.Do(ctx, a.Beliefs)
+
+		// Знімаємо завершені плани одразу, щоб не витрачати на це наступне повідомлення
+		for !in.empty() && in.stack[len(in.stack)-1].pc >= len(in.stack[len(in.stack)-1].plan.Body) {
+			in.stack = in.stack[:len(in.stack)-1]
+		}
+		if in.empty() {
+			// Останній крок виконано: наступне повідомлення почне обмірковування заново
+			a.intention = nil
+		}
+		return actions, err
+	}
+
+	a.intention = nil
+	return nil, nil
+}
@@ -0,0 +1,217 @@
+package bdi
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/youryharchenko/go-mas/ai"
+	"github.com/youryharchenko/go-mas/mas"
+	"github.com/youryharchenko/go-mas/mas/mastest"
+	"github.com/youryharchenko/go-mas/planning"
+)
+
+// testAgent - BDI-агент, чиї бажання і плани задає тест.
+type testAgent struct {
+	Agent
+	setup func(a *testAgent)
+}
+
+func (a *testAgent) Setup() { a.setup(a) }
+
+func spawn(t *testing.T, setup func(a *testAgent)) (*mastest.TestSystem, *testAgent) {
+	t.Helper()
+	ts := mastest.New(t)
+	a := &testAgent{Agent: Agent{BaseAgent: mas.BaseAgent{IDVal: "bdi"}}, setup: setup}
+	ts.Spawn(a)
+	return ts, a
+}
+
+// say - крок, що надсилає text агенту "out".
+func say(text string) Step {
+	return Do(text, func(ctx context.Context, b Beliefs) ([]mas.Action, error) {
+		return []mas.Action{mas.Send("out", text)}, nil
+	})
+}
+
+func believe(key string, value any) Belief { return Belief{Key: key, Value: value} }
+
+func TestPlanSelection(t *testing.T) {
+	ts, _ := spawn(t, func(a *testAgent) {
+		a.Desire(Desire{Goal: "greet"})
+		// Перший застосовний план у порядку додавання
+		a.AddPlan(Plan{Name: "formal", Goal: "greet", Context: func(b Beliefs) bool { return b.Bool("formal") }, Body: []Step{say("GOOD DAY")}})
+		a.AddPlan(Plan{Name: "casual", Goal: "greet", Body: []Step{say("HI")}})
+	})
+
+	ts.Send("main", "bdi", "TICK")
+	ts.Send("main", "bdi", believe("formal", true))
+	ts.RunUntilIdle()
+
+	ts.ExpectMessage("out", mas.PayloadEquals("HI"))
+	ts.ExpectMessage("out", mas.PayloadEquals("GOOD DAY"))
+}
+
+func TestSubgoalsAndNoPlan(t *testing.T) {
+	ts, a := spawn(t, func(a *testAgent) {
+		a.Desire(Desire{Goal: "dinner"})
+		a.AddPlan(Plan{Name: "cook", Goal: "dinner", Body: []Step{Achieve("shop"), say("COOK")}})
+		a.AddPlan(Plan{Name: "go shopping", Goal: "shop", Body: []Step{say("BUY"), say("PAY")}})
+	})
+
+	// Один крок на повідомлення: BUY, PAY, COOK
+	for range 3 {
+		ts.Send("main", "bdi", "TICK")
+	}
+	ts.RunUntilIdle()
+	for _, text := range []string{"BUY", "PAY", "COOK"} {
+		ts.ExpectMessage("out", mas.PayloadEquals(text))
+	}
+	if a.Intention() != nil {
+		t.Fatalf("intention %v after the last step", a.Intention().Plans())
+	}
+
+	// Ціль без планів - помилка
+	ts2, _ := spawn(t, func(a *testAgent) { a.Desire(Desire{Goal: "fly"}) })
+	if _, err := ts2.Plan("bdi", mas.Envelope{Payload: "TICK"}); err == nil {
+		t.Fatal("no error for a goal without plans")
+	}
+}
+
+func TestReconsideration(t *testing.T) {
+	ts, a := spawn(t, func(a *testAgent) {
+		a.Desire(Desire{Goal: "work", When: func(b Beliefs) bool { return b.Bool("day") }})
+		a.Desire(Desire{Goal: "flee", Priority: 10, When: func(b Beliefs) bool { return b.Bool("fire") }})
+		a.AddPlan(Plan{Name: "shift", Goal: "work", Body: []Step{say("START"), say("WORK"), say("FINISH")}})
+		a.AddPlan(Plan{Name: "run", Goal: "flee", Body: []Step{say("RUN"), say("OUT")}})
+	})
+
+	ts.Send("main", "bdi", believe("day", true))
+	ts.RunUntilIdle()
+	ts.ExpectMessage("out", mas.PayloadEquals("START"))
+	if in := a.Intention(); in == nil || in.Goal != "work" {
+		t.Fatalf("intention %+v, want work", in)
+	}
+
+	// Важливіше бажання витісняє поточний намір
+	ts.Send("main", "bdi", believe("fire", true))
+	ts.RunUntilIdle()
+	ts.ExpectMessage("out", mas.PayloadEquals("RUN"))
+	if in := a.Intention(); in == nil || in.Goal != "flee" {
+		t.Fatalf("intention %+v, want flee", in)
+	}
+
+	// Бажання більше не актуальне - намір відкидається, крок OUT не виконується
+	ts.Send("main", "bdi", []Belief{believe("fire", nil), believe("day", false)})
+	ts.RunUntilIdle()
+	ts.ExpectNoMessage("out", mas.MatchAny())
+	if a.Intention() != nil {
+		t.Fatalf("intention %v kept after reconsideration", a.Intention().Plans())
+	}
+}
+
+func TestPlanContextReconsidered(t *testing.T) {
+	ts, a := spawn(t, func(a *testAgent) {
+		a.Desire(Desire{Goal: "drive"})
+		a.AddPlan(Plan{Name: "by car", Goal: "drive", Context: func(b Beliefs) bool { return !b.Bool("flat tyre") },
+			Body: []Step{say("IGNITION"), say("GO")}})
+		a.AddPlan(Plan{Name: "by bus", Goal: "drive", Body: []Step{say("BUS")}})
+	})
+
+	ts.Send("main", "bdi", "TICK")
+	ts.Send("main", "bdi", believe("flat tyre", true))
+	ts.RunUntilIdle()
+
+	// Передумова плану перестала виконуватись - план обирається заново
+	ts.ExpectMessage("out", mas.PayloadEquals("IGNITION"))
+	ts.ExpectMessage("out", mas.PayloadEquals("BUS"))
+	ts.ExpectNoMessage("out", mas.PayloadEquals("GO"))
+	if a.Intention() != nil {
+		t.Fatalf("intention %v", a.Intention().Plans())
+	}
+}
+
+func TestRecursiveSubgoalIsCapped(t *testing.T) {
+	ts, a := spawn(t, func(a *testAgent) {
+		a.Desire(Desire{Goal: "loop"})
+		a.AddPlan(Plan{Name: "again", Goal: "loop", Body: []Step{Achieve("loop")}})
+	})
+
+	_, err := ts.Plan("bdi", mas.Envelope{Payload: "TICK"})
+	if err == nil {
+		t.Fatal("recursive plan did not fail")
+	}
+	if a.Intention() != nil {
+		t.Fatal("intention kept after the depth error")
+	}
+}
+
+// line - стан на відрізку 0..3 (ціль - 3).
+type line int
+
+func (p line) String() string               { return fmt.Sprint(int(p)) }
+func (p line) Equals(o planning.State) bool { q, ok := o.(line); return ok && p == q }
+
+type lineDomain struct{}
+
+func (lineDomain) Actions(s line) []planning.Action {
+	var out []planning.Action
+	if s < 3 {
+		out = append(out, "RIGHT")
+	}
+	if s > 0 {
+		out = append(out, "LEFT")
+	}
+	return out
+}
+func (lineDomain) Result(s line, a planning.Action) line {
+	if a == "RIGHT" {
+		return s + 1
+	}
+	return s - 1
+}
+func (lineDomain) IsGoal(s line) bool                                     { return s == 3 }
+func (lineDomain) StepCost(from line, a planning.Action, to line) float64 { return 1 }
+func (lineDomain) Heuristic(s line) float64                               { return float64(3 - s) }
+
+func TestPolicyMeansEnd(t *testing.T) {
+	me := &PolicyMeansEnd[line]{
+		Goal:   "reach",
+		Domain: lineDomain{},
+		Policy: ai.NewDFS[line](), // Memory не задано - буде типова
+		Current: func(b Beliefs) (line, bool) {
+			v, ok := b["pos"].(int)
+			return line(v), ok
+		},
+		Act: func(a planning.Action) []mas.Action { return []mas.Action{mas.Send("out", string(a))} },
+	}
+	ts, a := spawn(t, func(a *testAgent) {
+		a.Desire(Desire{Goal: "reach", Achieved: func(b Beliefs) bool { return b["pos"] == 3 }})
+		a.UseMeansEnd(me)
+	})
+
+	// Світ повідомляє позицію після кожного кроку
+	for pos := range 4 {
+		ts.Send("main", "bdi", believe("pos", pos))
+		ts.RunUntilIdle()
+	}
+
+	for range 3 {
+		ts.ExpectMessage("out", mas.PayloadEquals("RIGHT"))
+	}
+	ts.ExpectNoMessage("out", mas.MatchAny())
+	if a.Intention() != nil {
+		t.Fatalf("intention %v after the goal", a.Intention().Plans())
+	}
+	if me.Memory == nil {
+		t.Fatal("default Memory was not set")
+	}
+	for pos := range line(3) {
+		if !me.Memory.HasVisited(pos) {
+			t.Fatalf("state %v was not remembered", pos)
+		}
+	}
+	if me.Memory.HasVisited(3) {
+		t.Fatal("goal state remembered without deciding there")
+	}
+}
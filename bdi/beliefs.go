@@ -0,0 +1,82 @@
+package bdi
+
+import (
+	"encoding/gob"
+	"maps"
+	"reflect"
+
+	"github.com/youryharchenko/go-mas/mas"
+)
+
+// Beliefs - база переконань агента: факти "ключ -> значення".
+// Значення мають бути GOB-сумісними (базові типи або зареєстровані структури),
+// бо переконання зберігаються разом з агентом.
+type Beliefs map[string]any
+
+// Get повертає факт за ключем.
+func (b Beliefs) Get(key string) (any, bool) {
+	v, ok := b[key]
+	return v, ok
+}
+
+// Bool - зручний доступ до булевих фактів (відсутній факт = false).
+func (b Beliefs) Bool(key string) bool {
+	v, _ := b[key].(bool)
+	return v
+}
+
+// Clone повертає копію (для знімків і чистих функцій).
+func (b Beliefs) Clone() Beliefs {
+	return maps.Clone(b)
+}
+
+// update записує факти і повідомляє, чи щось насправді змінилось.
+// Значення nil видаляє факт.
+func (b Beliefs) update(facts map[string]any) bool {
+	changed := false
+	for k, v := range facts {
+		old, exists := b[k]
+		if v == nil {
+			if exists {
+				delete(b, k)
+				changed = true
+			}
+			continue
+		}
+		if !exists || !reflect.DeepEqual(old, v) {
+			b[k] = v
+			changed = true
+		}
+	}
+	return changed
+}
+
+// Belief - повідомлення "ось новий факт". Стандартне сприйняття BDI-агента
+// записує його в базу переконань без додаткового коду.
+type Belief struct {
+	Key   string
+	Value any
+}
+
+// Perception перетворює вхідне повідомлення на факти для бази переконань.
+type Perception func(msg mas.Envelope, current Beliefs) map[string]any
+
+// defaultPerception розуміє Belief і []Belief.
+func defaultPerception(msg mas.Envelope, _ Beliefs) map[string]any {
+	if b, ok := mas.PayloadAs[Belief](msg); ok {
+		return map[string]any{b.Key: b.Value}
+	}
+	if list, ok := mas.PayloadAs[[]Belief](msg); ok {
+		facts := make(map[string]any, len(list))
+		for _, b := range list {
+			facts[b.Key] = b.Value
+		}
+		return facts
+	}
+	return nil
+}
+
+func init() {
+	gob.Register(Belief{})
+	gob.Register([]Belief{})
+}
@@ -0,0 +1,60 @@
+package bdi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/youryharchenko/go-mas/ai"
+	"github.com/youryharchenko/go-mas/mas"
+	"github.com/youryharchenko/go-mas/planning"
+)
+
+// PolicyMeansEnd - засіб досягнення цілі через planning.Domain і політику з ai
+// (DFS, BFS, A*...). Він щоразу будує план з одного кроку: "спитати політику,
+// що робити далі, і зробити це". Оскільки ціль після кроку ще не досягнута,
+// наступне повідомлення знову дійде сюди - так виходить онлайн-планування.
+type PolicyMeansEnd[S planning.State] struct {
+	Goal   string // Для якої цілі ("" - для будь-якої)
+	Domain planning.Domain[S]
+	Policy planning.Policy[S]
+	// Memory - де агент уже побував: кожен стан, з якого він приймав рішення,
+	// запам'ятовується. nil - planning.NewMemory (політики ai без пам'яті не працюють).
+	Memory planning.Memory[S]
+
+	// Current дістає поточний стан світу з переконань.
+	Current func(Beliefs) (S, bool)
+	// Act перетворює дію планувальника на дії агента (наприклад, MoveRequest).
+	Act func(planning.Action) []mas.Action
+}
+
+func (m *PolicyMeansEnd[S]) PlanFor(ctx context.Context, goal string, b Beliefs) (*Plan, error) {
+	if m.Goal != "" && m.Goal != goal {
+		return nil, nil
+	}
+	if _, ok := m.Current(b); !ok {
+		return nil, nil
+	}
+	if m.Memory == nil {
+		m.Memory = planning.NewMemory[S]()
+	}
+
+	return &Plan{
+		Name: "means-end " + goal,
+		Goal: goal,
+		Body: []Step{Do("decide", func(ctx context.Context, b Beliefs) ([]mas.Action, error) {
+			current, ok := m.Current(b)
+			if !ok {
+				return nil, nil
+			}
+			m.Memory.Remember(current)
+			action, err := m.Policy.Decide(ctx, current, m.Domain, m.Memory)
+			if errors.Is(err, ai.ErrGoalReached) || action == "" {
+				return nil, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			return m.Act(action), nil
+		})},
+	}, nil
+}
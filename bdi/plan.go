@@ -0,0 +1,96 @@
+package bdi
+
+import (
+	"context"
+
+	"github.com/youryharchenko/go-mas/mas"
+)
+
+// Desire - бажання: ціль з пріоритетом, актуальна за певних переконань.
+type Desire struct {
+	Goal     string
+	Priority int // Більше - важливіше
+	// When - коли бажання актуальне (nil - завжди).
+	When func(Beliefs) bool
+	// Achieved - чи ціль уже досягнута (nil - ніколи; ціль "підтримувальна").
+	Achieved func(Beliefs) bool
+}
+
+func (d Desire) active(b Beliefs) bool {
+	if d.When != nil && !d.When(b) {
+		return false
+	}
+	return d.Achieved == nil || !d.Achieved(b)
+}
+
+// Step - крок плану: або дія (Do), або підціль (Achieve), яка кладе
+// свій план на вершину стеку наміру.
+type Step struct {
+	Name    string
+	Do      func(ctx context.Context, b Beliefs) ([]mas.Action, error)
+	Subgoal string
+}
+
+// Do створює крок-дію.
+func Do(name string, fn func(ctx context.Context, b Beliefs) ([]mas.Action, error)) Step {
+	return Step{Name: name, Do: fn}
+}
+
+// Achieve створює крок-підціль.
+func Achieve(goal string) Step {
+	return Step{Name: "achieve " + goal, Subgoal: goal}
+}
+
+// Plan - рецепт досягнення цілі з бібліотеки планів.
+type Plan struct {
+	Name string
+	Goal string
+	// Context - передумова: коли план застосовний (nil - завжди).
+	// Перевіряється і при виборі, і при переосмисленні наміру.
+	Context func(Beliefs) bool
+	Body    []Step
+}
+
+func (p *Plan) applicable(b Beliefs) bool {
+	return p.Context == nil || p.Context(b)
+}
+
+// frame - план, що виконується, і позиція в ньому.
+type frame struct {
+	plan *Plan
+	pc   int
+}
+
+// Intention - намір: прихильність до цілі у вигляді стеку планів
+// (верхній - поточна підціль).
+type Intention struct {
+	Goal  string
+	stack []frame
+}
+
+// Plans повертає імена планів у стеку (від низу до верху) - для логів і UI.
+func (in *Intention) Plans() []string {
+	names := make([]string, 0, len(in.stack))
+	for _, f := range in.stack {
+		names = append(names, f.plan.Name)
+	}
+	return names
+}
+
+func (in *Intention) empty() bool { return len(in.stack) == 0 }
+
+// valid - чи всі плани в стеку досі застосовні (для переосмислення).
+func (in *Intention) valid(b Beliefs) bool {
+	for _, f := range in.stack {
+		if !f.plan.applicable(b) {
+			return false
+		}
+	}
+	return true
+}
+
+// MeansEnd - засіб "як досягти цілі", коли жоден план бібліотеки не підійшов.
+// Повертає nil, якщо не вміє досягати цієї цілі.
+type MeansEnd interface {
+	PlanFor(ctx context.Context, goal string, b Beliefs) (*Plan, error)
+}
@@ -0,0 +1,135 @@
+// Package behaviours - поведінки в стилі JADE поверх mas.BaseAgent.
+//
+// Замість одного великого Plan-switch логіка агента складається з незалежних
+// поведінок (OneShot, Cyclic, Ticker, Waker, Sequential, Parallel, FSM),
+// кожна зі своїм шаблоном повідомлень. Поведінки можна додавати і прибирати
+// на ходу, прямо з дій агента (Add, Remove).
+//
+// Поведінки - це замикання, GOB їх не зберігає. Тому агент відновлює їх
+// у методі Setup(), який викликається при кожному Bind (Spawn або Startup):
+//
+//	type Walker struct {
+//		behaviours.Agent
+//		Steps int
+//	}
+//
+//	func (w *Walker) Setup() {
+//		w.AddBehaviour(behaviours.Cyclic("explore", mas.PayloadEquals("TICK"), w.explore))
+//		w.AddBehaviour(behaviours.Cyclic("reset", mas.PayloadEquals("RESET"), w.reset))
+//	}
+package behaviours
+
+import (
+	"context"
+	"encoding/gob"
+	"log"
+
+	"github.com/youryharchenko/go-mas/mas"
+)
+
+// Agent - агент, чия логіка складається з поведінок.
+type Agent struct {
+	mas.BaseAgent
+
+	active []Behaviour // Не зберігається: відновлюється в Setup()
+}
+
+// Setuper - агент, який встановлює свої поведінки після Bind.
+type Setuper interface {
+	Setup()
+}
+
+func (a *Agent) Bind(sys *mas.System, inbox <-chan mas.Envelope, me mas.Agent) {
+	a.BaseAgent.Bind(sys, inbox, me)
+
+	// Після Startup поведінок немає - просимо агента встановити їх заново
+	a.active = nil
+	if s, ok := me.(Setuper); ok {
+		s.Setup()
+	}
+}
+
+// AddBehaviour додає поведінку і запускає її (OnStart).
+func (a *Agent) AddBehaviour(b Behaviour) {
+	a.active = append(a.active, b)
+	b.OnStart(a)
+}
+
+// RemoveBehaviour зупиняє і прибирає поведінку верхнього рівня за іменем.
+func (a *Agent) RemoveBehaviour(name string) bool {
+	for i, b := range a.active {
+		if b.Name() == name {
+			a.active = append(a.active[:i:i], a.active[i+1:]...)
+			b.OnEnd(a)
+			return true
+		}
+	}
+	return false
+}
+
+// Behaviours повертає імена активних поведінок.
+func (a *Agent) Behaviours() []string {
+	names := make([]string, 0, len(a.active))
+	for _, b := range a.active {
+		names = append(names, b.Name())
+	}
+	return names
+}
+
+// Plan віддає повідомлення першій активній поведінці, чий шаблон його приймає
+// (як receive(template) у JADE). Завершені поведінки прибираються.
+func (a *Agent) Plan(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
+	for i, b := range a.active {
+		if !accepts(b, msg) {
+			continue
+		}
+
+		actions, err := b.Action(ctx, msg)
+		if b.Done() {
+			a.active = append(a.active[:i:i], a.active[i+1:]...)
+			b.OnEnd(a)
+		}
+		return actions, err
+	}
+
+	// Нікому не потрібне - ігноруємо, як і звичайні агенти
+	return nil, nil
+}
+
+// wake надсилає агенту службовий Tick для поведінки name.
+// TrySend: таймер не повинен зависати на переповненій скриньці.
+func (a *Agent) wake(name string) {
+	sys := a.Sys()
+	if sys == nil {
+		return
+	}
+	if err := sys.TrySend(sys.Context(), a.ID(), a.ID(), Tick{Behaviour: name}); err != nil {
+		log.Printf("Agent %s: behaviour %s missed a tick: %v", a.ID(), name, err)
+	}
+}
+
+// --- Дії ---
+
+// Add - дія: додати поведінку до агента на ходу.
+func Add(b Behaviour) mas.Action {
+	return func(ctx context.Context, agent mas.Agent, sys *mas.System) error {
+		if h, ok := agent.(interface{ AddBehaviour(Behaviour) }); ok {
+			h.AddBehaviour(b)
+		}
+		return nil
+	}
+}
+
+// Remove - дія: прибрати поведінку за іменем.
+func Remove(name string) mas.Action {
+	return func(ctx context.Context, agent mas.Agent, sys *mas.System) error {
+		if h, ok := agent.(interface{ RemoveBehaviour(string) bool }); ok {
+			h.RemoveBehaviour(name)
+		}
+		return nil
+	}
+}
+
+func init() {
+	gob.Register(Tick{})
+}
@@ -0,0 +1,175 @@
+package behaviours
+
+import (
+	"context"
+	"time"
+
+	"github.com/youryharchenko/go-mas/mas"
+)
+
+// Func - реакція поведінки на повідомлення, що пройшло її шаблон.
+type Func func(ctx context.Context, msg mas.Envelope) ([]mas.Action, error)
+
+// EventFunc - як Func, але ще й повертає подію завершення (для переходів FSM).
+type EventFunc func(ctx context.Context, msg mas.Envelope) (string, []mas.Action, error)
+
+// Behaviour - одиниця логіки агента (аналог jade.core.behaviours.Behaviour).
+// Імена мають бути унікальними в межах агента: за ними маршрутизуються Tick.
+type Behaviour interface {
+	Name() string
+	// Template - які повідомлення приймає поведінка (nil - жодних).
+	Template() mas.Matcher
+	// OnStart викликається, коли поведінку додано (або FSM/Sequential перейшли до неї).
+	OnStart(a *Agent)
+	// Action обробляє повідомлення і повертає дії агента.
+	Action(ctx context.Context, msg mas.Envelope) ([]mas.Action, error)
+	// Done - чи завершилась поведінка.
+	Done() bool
+	// Event - подія завершення (використовує FSM для вибору переходу).
+	Event() string
+	// OnEnd викликається після завершення або видалення (зупинка таймерів).
+	OnEnd(a *Agent)
+	// Reset повертає поведінку в початковий стан (повторний вхід у стан FSM).
+	Reset()
+}
+
+// Tick - службове повідомлення самому собі для Ticker/Waker/OneShot без шаблону.
+type Tick struct {
+	Behaviour string
+}
+
+// TickFor - шаблон службового Tick для поведінки name.
+func TickFor(name string) mas.Matcher {
+	return func(env mas.Envelope) bool {
+		t, ok := mas.PayloadAs[Tick](env)
+		return ok && t.Behaviour == name
+	}
+}
+
+func accepts(b Behaviour, msg mas.Envelope) bool {
+	t := b.Template()
+	return t != nil && t(msg)
+}
+
+// base - спільна частина простих поведінок.
+type base struct {
+	name     string
+	template mas.Matcher
+	done     bool
+	event    string
+}
+
+func (b *base) Name() string          { return b.name }
+func (b *base) Template() mas.Matcher { return b.template }
+func (b *base) OnStart(a *Agent)      {}
+func (b *base) Done() bool            { return b.done }
+func (b *base) Event() string         { return b.event }
+func (b *base) OnEnd(a *Agent)        {}
+func (b *base) Reset()                { b.done, b.event = false, "" }
+
+// --- OneShot / Cyclic ---
+
+type oneShot struct {
+	base
+	fn        EventFunc
+	selfStart bool // Без шаблону: запускається власним Tick одразу після старту
+}
+
+// OneShot виконується один раз - на першому повідомленні, що пройшло шаблон.
+// Якщо tmpl == nil, поведінка спрацьовує сама одразу після додавання
+// (з фейковим годинником - після Advance(0)).
+func OneShot(name string, tmpl mas.Matcher, fn Func) Behaviour {
+	return Choice(name, tmpl, func(ctx context.Context, msg mas.Envelope) (string, []mas.Action, error) {
+		actions, err := fn(ctx, msg)
+		return "", actions, err
+	})
+}
+
+// Choice - OneShot, що завершується подією (для переходів FSM).
+func Choice(name string, tmpl mas.Matcher, fn EventFunc) Behaviour {
+	b := &oneShot{base: base{name: name, template: tmpl}, fn: fn}
+	if tmpl == nil {
+		b.selfStart = true
+		b.template = TickFor(name)
+	}
+	return b
+}
+
+func (b *oneShot) OnStart(a *Agent) {
+	// Через таймер з нульовою затримкою, а не напряму: OnStart може викликатися
+	// з Bind, коли System ще тримає свій м'ютекс
+	if b.selfStart && a.Sys() != nil {
+		a.Sys().Clock().AfterFunc(0, func() { a.wake(b.name) })
+	}
+}
+
+func (b *oneShot) Action(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
+	event, actions, err := b.fn(ctx, msg)
+	b.done, b.event = true, event
+	return actions, err
+}
+
+type cyclic struct {
+	base
+	fn Func
+}
+
+// Cyclic обробляє кожне повідомлення, що пройшло шаблон, і ніколи не завершується сама.
+func Cyclic(name string, tmpl mas.Matcher, fn Func) Behaviour {
+	return &cyclic{base: base{name: name, template: tmpl}, fn: fn}
+}
+
+func (b *cyclic) Action(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
+	return b.fn(ctx, msg)
+}
+
+// --- Ticker / Waker ---
+
+type timed struct {
+	base
+	period time.Duration
+	repeat bool
+	fn     Func
+	host   *Agent
+	timer  mas.Timer
+}
+
+// Ticker викликає fn кожні period (за годинником системи).
+func Ticker(name string, period time.Duration, fn Func) Behaviour {
+	return &timed{base: base{name: name, template: TickFor(name)}, period: period, repeat: true, fn: fn}
+}
+
+// Waker викликає fn один раз через delay і завершується.
+func Waker(name string, delay time.Duration, fn Func) Behaviour {
+	return &timed{base: base{name: name, template: TickFor(name)}, period: delay, fn: fn}
+}
+
+func (b *timed) OnStart(a *Agent) {
+	b.host = a
+	b.schedule()
+}
+
+func (b *timed) schedule() {
+	sys := b.host.Sys()
+	if sys == nil {
+		return
+	}
+	b.timer = sys.Clock().AfterFunc(b.period, func() { b.host.wake(b.name) })
+}
+
+func (b *timed) Action(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
+	actions, err := b.fn(ctx, msg)
+	if b.repeat {
+		// Наступний тік плануємо лише після обробки, щоб тіки не накопичувались
+		b.schedule()
+	} else {
+		b.done = true
+	}
+	return actions, err
+}
+
+func (b *timed) OnEnd(a *Agent) {
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+}
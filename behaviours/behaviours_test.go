@@ -0,0 +1,210 @@
+package behaviours
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/youryharchenko/go-mas/mas"
+	"github.com/youryharchenko/go-mas/mas/mastest"
+)
+
+// testAgent - агент, чиї поведінки задає тест.
+type testAgent struct {
+	Agent
+	setup func(a *testAgent)
+}
+
+func (a *testAgent) Setup() { a.setup(a) }
+
+// recorder записує, які поведінки і на що спрацювали.
+type recorder struct {
+	got []string
+}
+
+// fn - реакція, що записує name.
+func (r *recorder) fn(name string) Func {
+	return func(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
+		r.got = append(r.got, name)
+		return nil, nil
+	}
+}
+
+// choice - реакція, що записує name і завершується подією event.
+func (r *recorder) choice(name, event string) EventFunc {
+	return func(ctx context.Context, msg mas.Envelope) (string, []mas.Action, error) {
+		r.got = append(r.got, name)
+		return event, nil, nil
+	}
+}
+
+func (r *recorder) expect(t *testing.T, want ...string) {
+	t.Helper()
+	if !slices.Equal(r.got, want) {
+		t.Fatalf("got %v, want %v", r.got, want)
+	}
+}
+
+func spawn(t *testing.T, setup func(a *testAgent)) (*mastest.TestSystem, *testAgent) {
+	t.Helper()
+	ts := mastest.New(t)
+	a := &testAgent{Agent: Agent{BaseAgent: mas.BaseAgent{IDVal: "agent"}}, setup: setup}
+	ts.Spawn(a)
+	return ts, a
+}
+
+func send(ts *mastest.TestSystem, payloads ...any) {
+	for _, p := range payloads {
+		ts.Send("main", "agent", p)
+	}
+	ts.RunUntilIdle()
+}
+
+func TestOneShotAndCyclic(t *testing.T) {
+	var r recorder
+	ts, a := spawn(t, func(a *testAgent) {
+		a.AddBehaviour(OneShot("once", mas.PayloadEquals("GO"), r.fn("once")))
+		a.AddBehaviour(Cyclic("always", mas.PayloadHasPrefix("G"), r.fn("always")))
+	})
+
+	send(ts, "GO", "GO", "GET", "STOP")
+	r.expect(t, "once", "always", "always")
+	if names := a.Behaviours(); !slices.Equal(names, []string{"always"}) {
+		t.Fatalf("behaviours %v", names)
+	}
+}
+
+func TestOneShotSelfStart(t *testing.T) {
+	var r recorder
+	ts, a := spawn(t, func(a *testAgent) {
+		a.AddBehaviour(OneShot("init", nil, r.fn("init")))
+	})
+
+	r.expect(t)
+	ts.Advance(0)
+	r.expect(t, "init")
+	if len(a.Behaviours()) != 0 {
+		t.Fatalf("behaviours %v", a.Behaviours())
+	}
+}
+
+func TestTickerAndWaker(t *testing.T) {
+	var r recorder
+	ts, a := spawn(t, func(a *testAgent) {
+		a.AddBehaviour(Ticker("tick", time.Second, r.fn("tick")))
+		a.AddBehaviour(Waker("alarm", 2500*time.Millisecond, r.fn("alarm")))
+	})
+
+	ts.Advance(999 * time.Millisecond)
+	r.expect(t)
+	ts.Advance(time.Millisecond)
+	r.expect(t, "tick")
+	// Наступний тік плануємо після доставки попереднього, тож час зсуваємо кроками
+	for range 4 {
+		ts.Advance(500 * time.Millisecond)
+	}
+	r.expect(t, "tick", "tick", "alarm", "tick")
+	if names := a.Behaviours(); !slices.Equal(names, []string{"tick"}) {
+		t.Fatalf("behaviours %v (waker must finish)", names)
+	}
+
+	// Прибрана поведінка зупиняє свій таймер
+	if !a.RemoveBehaviour("tick") {
+		t.Fatal("tick not removed")
+	}
+	if n := ts.Clock.Pending(); n != 0 {
+		t.Fatalf("%d timers pending after removal", n)
+	}
+	ts.Advance(5 * time.Second)
+	r.expect(t, "tick", "tick", "alarm", "tick")
+}
+
+func TestSequential(t *testing.T) {
+	var r recorder
+	ts, a := spawn(t, func(a *testAgent) {
+		a.AddBehaviour(Sequential("steps",
+			OneShot("a", mas.PayloadEquals("A"), r.fn("a")),
+			Waker("wait", time.Second, r.fn("wait")),
+			OneShot("b", mas.PayloadEquals("B"), r.fn("b")),
+		))
+	})
+
+	// Поки не настала черга, дитина повідомлень не отримує (і її таймер не запущено)
+	send(ts, "B")
+	ts.Advance(time.Second)
+	send(ts, "A")
+	r.expect(t, "a")
+
+	ts.Advance(time.Second)
+	send(ts, "B")
+	r.expect(t, "a", "wait", "b")
+	if len(a.Behaviours()) != 0 {
+		t.Fatalf("behaviours %v", a.Behaviours())
+	}
+}
+
+func TestParallel(t *testing.T) {
+	var r recorder
+	ts, a := spawn(t, func(a *testAgent) {
+		a.AddBehaviour(Parallel("all", WhenAll,
+			OneShot("x", mas.PayloadEquals("X"), r.fn("x")),
+			OneShot("y", mas.PayloadEquals("Y"), r.fn("y")),
+		))
+		a.AddBehaviour(Parallel("any", WhenAny,
+			OneShot("p", mas.PayloadEquals("P"), r.fn("p")),
+			Ticker("q", time.Second, r.fn("q")),
+		))
+	})
+
+	send(ts, "Y")
+	if !slices.Contains(a.Behaviours(), "all") {
+		t.Fatal("WhenAll finished after one child")
+	}
+	send(ts, "X")
+	if slices.Contains(a.Behaviours(), "all") {
+		t.Fatal("WhenAll did not finish after all children")
+	}
+
+	ts.Advance(time.Second)
+	send(ts, "P")
+	if len(a.Behaviours()) != 0 {
+		t.Fatalf("behaviours %v", a.Behaviours())
+	}
+	// WhenAny зупинила незавершений Ticker
+	ts.Advance(5 * time.Second)
+	r.expect(t, "y", "x", "q", "p")
+}
+
+func TestFSM(t *testing.T) {
+	var r recorder
+	var fsm *FSM
+	ts, a := spawn(t, func(a *testAgent) {
+		fsm = NewFSM("door").
+			First("closed", Choice("closed", mas.PayloadOf[string](), func(ctx context.Context, msg mas.Envelope) (string, []mas.Action, error) {
+				r.got = append(r.got, "closed:"+msg.Payload.(string))
+				return msg.Payload.(string), nil, nil
+			})).
+			State("open", Choice("open", mas.PayloadEquals("CLOSE"), r.choice("open", "closed"))).
+			Last("broken", OneShot("broken", mas.PayloadEquals("FIX"), r.fn("broken"))).
+			Transition("closed", "OPEN", "open").
+			Transition("closed", "KICK", "broken").
+			DefaultTransition("closed", "closed").
+			Transition("open", "closed", "closed")
+		a.AddBehaviour(fsm)
+	})
+
+	send(ts, "KNOCK", "OPEN")
+	if fsm.Current() != "open" {
+		t.Fatalf("state %q, want open", fsm.Current())
+	}
+	send(ts, "OPEN", "CLOSE") // У стані open шаблон приймає лише CLOSE
+	if fsm.Current() != "closed" {
+		t.Fatalf("state %q, want closed", fsm.Current())
+	}
+	send(ts, "KICK", "FIX")
+	r.expect(t, "closed:KNOCK", "closed:OPEN", "open", "closed:KICK", "broken")
+	if len(a.Behaviours()) != 0 {
+		t.Fatalf("FSM did not finish in the last state: %v", a.Behaviours())
+	}
+}
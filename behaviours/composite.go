@@ -0,0 +1,276 @@
+package behaviours
+
+import (
+	"context"
+	"log"
+
+	"github.com/youryharchenko/go-mas/mas"
+)
+
+// --- Sequential ---
+
+type sequential struct {
+	base
+	children []Behaviour
+	current  int
+	host     *Agent
+}
+
+// Sequential виконує дочірні поведінки по черзі: наступна стартує, коли завершилась попередня.
+// Подія завершення - подія останньої дитини.
+func Sequential(name string, children ...Behaviour) Behaviour {
+	b := &sequential{base: base{name: name}, children: children}
+	b.template = func(env mas.Envelope) bool {
+		return b.current < len(b.children) && accepts(b.children[b.current], env)
+	}
+	return b
+}
+
+func (b *sequential) OnStart(a *Agent) {
+	b.host = a
+	b.current = 0
+	if len(b.children) == 0 {
+		b.done = true
+		return
+	}
+	b.children[0].OnStart(a)
+}
+
+func (b *sequential) Action(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
+	child := b.children[b.current]
+	actions, err := child.Action(ctx, msg)
+	if child.Done() {
+		child.OnEnd(b.host)
+		b.current++
+		if b.current < len(b.children) {
+			b.children[b.current].OnStart(b.host)
+		} else {
+			b.done, b.event = true, child.Event()
+		}
+	}
+	return actions, err
+}
+
+func (b *sequential) OnEnd(a *Agent) {
+	if b.current < len(b.children) {
+		b.children[b.current].OnEnd(a)
+	}
+}
+
+func (b *sequential) Reset() {
+	b.base.Reset()
+	b.current = 0
+	for _, c := range b.children {
+		c.Reset()
+	}
+}
+
+// --- Parallel ---
+
+// Completion - коли вважати Parallel завершеною.
+type Completion int
+
+const (
+	WhenAll Completion = iota // Коли завершились усі діти
+	WhenAny                   // Коли завершилась будь-яка дитина
+)
+
+type parallel struct {
+	base
+	when     Completion
+	children []Behaviour
+	host     *Agent
+}
+
+// Parallel запускає дочірні поведінки одночасно. Повідомлення отримує перша
+// незавершена дитина, чий шаблон його приймає.
+func Parallel(name string, when Completion, children ...Behaviour) Behaviour {
+	b := &parallel{base: base{name: name}, when: when, children: children}
+	b.template = func(env mas.Envelope) bool {
+		return b.find(env) != nil
+	}
+	return b
+}
+
+func (b *parallel) find(env mas.Envelope) Behaviour {
+	for _, c := range b.children {
+		if !c.Done() && accepts(c, env) {
+			return c
+		}
+	}
+	return nil
+}
+
+func (b *parallel) OnStart(a *Agent) {
+	b.host = a
+	for _, c := range b.children {
+		c.OnStart(a)
+	}
+	b.checkDone(nil)
+}
+
+func (b *parallel) Action(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
+	child := b.find(msg)
+	if child == nil {
+		return nil, nil
+	}
+	actions, err := child.Action(ctx, msg)
+	if child.Done() {
+		child.OnEnd(b.host)
+		b.checkDone(child)
+	}
+	return actions, err
+}
+
+func (b *parallel) checkDone(last Behaviour) {
+	finished := 0
+	for _, c := range b.children {
+		if c.Done() {
+			finished++
+		}
+	}
+	if (b.when == WhenAny && finished > 0) || finished == len(b.children) {
+		b.done = true
+		if last != nil {
+			b.event = last.Event()
+		}
+	}
+}
+
+func (b *parallel) OnEnd(a *Agent) {
+	// WhenAny: зупиняємо тих, хто ще працює
+	for _, c := range b.children {
+		if !c.Done() {
+			c.OnEnd(a)
+		}
+	}
+}
+
+func (b *parallel) Reset() {
+	b.base.Reset()
+	for _, c := range b.children {
+		c.Reset()
+	}
+}
+
+// --- FSM ---
+
+// FSM - скінченний автомат із поведінок (аналог FSMBehaviour у JADE).
+// Коли поведінка-стан завершується, FSM переходить за її подією (Event).
+type FSM struct {
+	base
+	first       string
+	states      map[string]Behaviour
+	last        map[string]bool
+	transitions map[string]map[string]string // from -> event -> to
+	defaults    map[string]string            // from -> to (для будь-якої іншої події)
+	current     string
+	host        *Agent
+}
+
+// NewFSM створює порожній автомат; стани додаються ланцюжком викликів.
+func NewFSM(name string) *FSM {
+	f := &FSM{
+		base:        base{name: name},
+		states:      make(map[string]Behaviour),
+		last:        make(map[string]bool),
+		transitions: make(map[string]map[string]string),
+		defaults:    make(map[string]string),
+	}
+	f.template = func(env mas.Envelope) bool {
+		st, ok := f.states[f.current]
+		return ok && !f.done && accepts(st, env)
+	}
+	return f
+}
+
+// First додає початковий стан.
+func (f *FSM) First(name string, b Behaviour) *FSM {
+	f.first = name
+	return f.State(name, b)
+}
+
+// State додає проміжний стан.
+func (f *FSM) State(name string, b Behaviour) *FSM {
+	f.states[name] = b
+	return f
+}
+
+// Last додає кінцевий стан: після його завершення завершується і FSM.
+func (f *FSM) Last(name string, b Behaviour) *FSM {
+	f.last[name] = true
+	return f.State(name, b)
+}
+
+// Transition - перехід from -> to за подією event.
+func (f *FSM) Transition(from, event, to string) *FSM {
+	if f.transitions[from] == nil {
+		f.transitions[from] = make(map[string]string)
+	}
+	f.transitions[from][event] = to
+	return f
+}
+
+// DefaultTransition - перехід from -> to, якщо для події немає окремого правила.
+func (f *FSM) DefaultTransition(from, to string) *FSM {
+	f.defaults[from] = to
+	return f
+}
+
+// Current повертає ім'я поточного стану.
+func (f *FSM) Current() string { return f.current }
+
+func (f *FSM) OnStart(a *Agent) {
+	f.host = a
+	f.enter(f.first)
+}
+
+func (f *FSM) enter(name string) {
+	st, ok := f.states[name]
+	if !ok {
+		log.Printf("FSM %s: unknown state %q", f.name, name)
+		f.done = true
+		return
+	}
+	f.current = name
+	st.Reset()
+	st.OnStart(f.host)
+}
+
+func (f *FSM) Action(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
+	st := f.states[f.current]
+	actions, err := st.Action(ctx, msg)
+	if !st.Done() {
+		return actions, err
+	}
+
+	st.OnEnd(f.host)
+	event := st.Event()
+	if f.last[f.current] {
+		f.done, f.event = true, event
+		return actions, err
+	}
+
+	next, ok := f.transitions[f.current][event]
+	if !ok {
+		next, ok = f.defaults[f.current]
+	}
+	if !ok {
+		log.Printf("FSM %s: no transition from %q on event %q", f.name, f.current, event)
+		f.done, f.event = true, event
+		return actions, err
+	}
+	f.enter(next)
+	return actions, err
+}
+
+func (f *FSM) OnEnd(a *Agent) {
+	if st, ok := f.states[f.current]; ok && !st.Done() {
+		st.OnEnd(a)
+	}
+}
+
+func (f *FSM) Reset() {
+	f.base.Reset()
+	f.current = ""
+}
@@ -2,47 +2,58 @@ package main
 
 import (
 	"context"
+	"flag"
+	"log"
 	"time"
 
+	"github.com/youryharchenko/go-mas/boss/models"
+	"github.com/youryharchenko/go-mas/gateway"
 	"github.com/youryharchenko/go-mas/mas"
+	"github.com/youryharchenko/go-mas/routing"
 )
 
+func init() {
+	models.Register()
+
+	// Пул воркерів за маршрутизатором: менеджер не знає, скільки їх
+	mas.RegisterType("WorkerPool", func() mas.Agent {
+		return routing.NewRouter("", routing.RoundRobin, 2, &models.WorkerBot{})
+	})
+
+	// Типи, які можна надіслати через HTTP-шлюз
+	gateway.RegisterPayload("WorkOrder", models.WorkOrder{})
+	gateway.RegisterPayload("Resize", routing.Resize{})
+}
+
 func main() {
-	sys := mas.NewSystem(mas.WithPersistence("world.gob"))
+	httpAddr := flag.String("http", "", "адреса HTTP-шлюзу, наприклад 127.0.0.1:8080 (за замовчуванням вимкнений)")
+	flag.Parse()
+
+	sys := mas.NewSystem(mas.WithPersistence("world.gob"), mas.WithHandleTimeout(2*time.Second))
 	sys.Startup() // Відновили старих (Воркера з Count=5)
 
-	if _, exists := sys.GetAgent("worker-1"); !exists {
-		worker := &WorkerBot{BaseAgent: mas.BaseAgent{IDVal: "worker-1"}, Count: 0}
-		sys.Spawn(worker)
+	// Завислих воркерів пулу перезапускаємо (їхній лічильник Count при цьому втрачається);
+	// маршрутизатор, менеджера і шлюз - ні: Restart скинув би їхній стан.
+	// Події - у журналі і в /feed?topic=health
+	sys.StartWatchdog(mas.RestartUnhealthy("workers-*"))
+
+	if *httpAddr != "" {
+		sys.Spawn(gateway.New("gateway", *httpAddr))
 	}
 
-	if _, ok := sys.GetAgent("boss-1"); !ok {
-		boss := &ManagerBot{
-			BaseAgent:     mas.BaseAgent{IDVal: "boss-1"}, // ID треба задавати явно
-			TargetAgentID: "worker-1",
-		}
-		// Важливо: BaseAgent має поле IDVal, але ми ще не реалізували конструктор,
-		// тому задаємо вручну або через NewManagerBot("boss-1")
-		sys.Spawn(boss)
+	// Агенти, яких ще немає після Startup, і такт менеджера - у world.yaml
+	if err := sys.LoadWorld("world.yaml"); err != nil {
+		log.Fatal(err)
 	}
 
-	// 2. Запускаємо "Ігровий Цикл"
-	// Замість ручного send("INC"), ми просто пінаємо менеджера, щоб він почав працювати
-	go func() {
-		ticker := time.NewTicker(2 * time.Second)
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ticker.C:
-				// Зовнішній світ каже босу: "Час працювати"
-				sys.Send(context.Background(), "main", "boss-1", "TICK")
-			case <-sys.Context().Done(): // Якщо система зупиняється
-				return
-			}
-		}
-	}()
-
-	// Працюємо 10 секунд і виходимо
-	time.Sleep(10 * time.Second)
-	sys.Shutdown()
+	// Працюємо 10 секунд і виходимо; на півдорозі розширюємо пул
+	time.Sleep(5 * time.Second)
+	sys.Send(sys.Context(), "main", "workers", routing.Resize{Size: 4})
+	time.Sleep(5 * time.Second)
+	sys.Kill("gateway") // Шлюз не зберігаємо у світ
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := sys.Shutdown(ctx); err != nil {
+		log.Println(err)
+	}
 }
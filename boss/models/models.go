@@ -0,0 +1,92 @@
+// Package models - агенти демо boss (менеджер і воркери).
+//
+// Типи зареєстровані в GOB під іменами "*main.X", з якими їх зберігала
+// програма boss, поки вони жили в package main: старі world.gob читаються,
+// а masctl може підключити ці моделі як бібліотеку.
+package models
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/youryharchenko/go-mas/mas"
+	"github.com/youryharchenko/go-mas/routing"
+)
+
+type WorkOrder struct {
+	TaskID string
+	Amount int
+}
+
+// MaxWorkerLoad - поріг заповненості скриньки воркера, після якого менеджер не шле нових задач.
+const MaxWorkerLoad = 0.8
+
+type ManagerBot struct {
+	mas.BaseAgent
+	TargetAgentID string // Кого ми будемо пінати
+	TasksSent     int
+}
+
+func (m *ManagerBot) Plan(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
+	// 1. Реакція на вхідні (наприклад, звіт від воркера)
+	if msg.Payload == "DONE" {
+		return []mas.Action{
+			mas.SayLog("Worker finished a task. Good job."),
+		}, nil
+	}
+
+	// 2. Реакція на "Тік" таймера (див. нижче про Loop)
+	if msg.Payload == "TICK" {
+		// Зворотний тиск: якщо скринька воркера майже повна - пригальмовуємо.
+		// Ціллю може бути й Router: тоді важать скриньки його воркерів (routing.Load)
+		if load, ok := routing.Load(m.Sys(), m.TargetAgentID); ok && load > MaxWorkerLoad {
+			return []mas.Action{
+				mas.SayLog("Worker %s is overloaded (%.0f%%), skipping tick", m.TargetAgentID, load*100),
+			}, nil
+		}
+
+		m.TasksSent++
+		task := WorkOrder{TaskID: fmt.Sprintf("job-%d", m.TasksSent), Amount: 1}
+
+		return []mas.Action{
+			mas.SayLog("Assigning task %s to %s", task.TaskID, m.TargetAgentID),
+			// Менеджер відправляє повідомлення Воркеру
+			mas.Send(m.TargetAgentID, task),
+		}, nil
+	}
+
+	return nil, nil
+}
+
+type WorkerBot struct {
+	mas.BaseAgent // Вбудовуємо базу
+	Count         int
+}
+
+// Bind реєструє обробники: тип payload перевіряє mas.Handle, а не type switch.
+// Невідоме воркер не ігнорує мовчки - відправник отримає NOT_UNDERSTOOD.
+func (w *WorkerBot) Bind(sys *mas.System, inbox <-chan mas.Envelope, me mas.Agent) {
+	w.BaseAgent.Bind(sys, inbox, me)
+	mas.Handle(w, w.onWorkOrder)
+}
+
+func (w *WorkerBot) onWorkOrder(ctx context.Context, msg mas.Envelope, order WorkOrder) ([]mas.Action, error) {
+	return []mas.Action{
+		mas.MutateState(func(a any) {
+			a.(*WorkerBot).Count += order.Amount
+		}),
+		mas.SayLog("Received order %s. Count is now %d", order.TaskID, w.Count+order.Amount),
+		// Відповідаємо Менеджеру
+		mas.Send(msg.ReplyAddr(), "DONE"),
+	}, nil
+}
+
+// Register реєструє типи агентів (mas.RegisterType) і payload у GOB.
+// Викликається один раз (у init програми або masctl): boss і gui-log
+// використовують ті самі імена, тож разом їх зареєструвати не можна.
+func Register() {
+	gob.RegisterName("main.WorkOrder", WorkOrder{})
+	mas.RegisterType("ManagerBot", func() mas.Agent { return &ManagerBot{} }, mas.WithGobName("*main.ManagerBot"))
+	mas.RegisterType("WorkerBot", func() mas.Agent { return &WorkerBot{} }, mas.WithGobName("*main.WorkerBot"))
+}
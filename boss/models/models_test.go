@@ -0,0 +1,40 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/youryharchenko/go-mas/mas"
+	"github.com/youryharchenko/go-mas/mas/mastest"
+)
+
+func TestManagerAssignsWork(t *testing.T) {
+	ts := mastest.New(t)
+	boss := &ManagerBot{BaseAgent: mas.BaseAgent{IDVal: "boss-1"}, TargetAgentID: "worker-1"}
+	worker := &WorkerBot{BaseAgent: mas.BaseAgent{IDVal: "worker-1"}}
+	ts.Spawn(boss, worker)
+
+	ts.Send("main", "boss-1", "TICK")
+	ts.Send("main", "boss-1", "TICK")
+	ts.RunUntilIdle()
+
+	for _, id := range []string{"job-1", "job-2"} {
+		ts.ExpectMessage("worker-1", mas.AllOf(mas.From("boss-1"), mas.PayloadEquals(WorkOrder{TaskID: id, Amount: 1})))
+		ts.ExpectMessage("boss-1", mas.AllOf(mas.From("worker-1"), mas.PayloadEquals("DONE")))
+	}
+	if boss.TasksSent != 2 || worker.Count != 2 {
+		t.Fatalf("sent %d, counted %d; want 2 and 2", boss.TasksSent, worker.Count)
+	}
+}
+
+func TestWorkerRejectsUnknown(t *testing.T) {
+	ts := mastest.New(t)
+	ts.Spawn(&WorkerBot{BaseAgent: mas.BaseAgent{IDVal: "worker-1"}})
+
+	ts.Send("boss-1", "worker-1", "DANCE")
+	ts.RunUntilIdle()
+
+	env := ts.ExpectMessage("boss-1", mas.PayloadEquals("DANCE"))
+	if env.Type != mas.NotUnderstood {
+		t.Fatalf("performative %q, want %q", env.Type, mas.NotUnderstood)
+	}
+}
@@ -0,0 +1,317 @@
+// mazerun - прогін лабіринтів без UI: ті самі MazeAgent і PlannerWalker,
+// що у вкладці лабораторії, але в підсистемі без вікна і без таймера.
+//
+//	mazerun -policy DFS,AStar -size 21 -seed 7 -episodes 10
+//	mazerun -policy all -format json > runs.jsonl
+//
+// Епізод n грає на лабіринті з seed+n, тож прогони відтворювані.
+// Волкер отримує TICK одразу після попереднього ходу.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/youryharchenko/go-mas/mas"
+	"github.com/youryharchenko/go-mas/simulations/maze"
+)
+
+// runnerID - від чийого імені йдуть TICK і POLICY.
+const runnerID = "mazerun"
+
+// moveTimeout - скільки чекаємо відповіді лабіринту на хід.
+const moveTimeout = 5 * time.Second
+
+// maxIdleTicks - стільки TICK поспіль без ходу означає, що волкер застряг.
+const maxIdleTicks = 3
+
+// Result - підсумок одного епізоду (рядок таблиці або JSON).
+type Result struct {
+	Episode    int     `json:"episode"`
+	Policy     string  `json:"policy"`
+	Seed       uint64  `json:"seed"`
+	Size       int     `json:"size"`
+	Steps      int     `json:"steps"`
+	Ticks      int     `json:"ticks"`
+	Success    bool    `json:"success"`
+	TimeMS     float64 `json:"time_ms"`
+	Remembered int     `json:"remembered"`
+}
+
+// console - приймач повідомлень, які агенти лабіринту шлють у "console" (видно з -v).
+type console struct {
+	mas.BaseAgent
+}
+
+func (c *console) Plan(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
+	log.Printf("[%s]: %v", msg.From, msg.Payload)
+	return nil, nil
+}
+
+type config struct {
+	policies []string
+	size     int
+	seed     uint64
+	episodes int
+	maxTicks int
+}
+
+func main() {
+	policy := flag.String("policy", "DFS", "стратегії через кому ("+strings.Join(maze.PolicyNames(), ", ")+") або all")
+	size := flag.Int("size", 15, "розмір лабіринту (непарний, не менше 5)")
+	seed := flag.Uint64("seed", 1, "seed першого лабіринту")
+	episodes := flag.Int("episodes", 1, "кількість епізодів на стратегію")
+	maxTicks := flag.Int("max-ticks", 0, "ліміт TICK на епізод (0 - 4*size*size)")
+	format := flag.String("format", "table", "вивід: table або json (JSON lines)")
+	verbose := flag.Bool("v", false, "показувати журнал агентів")
+	flag.Parse()
+
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+
+	cfg, err := newConfig(*policy, *size, *seed, *episodes, *maxTicks)
+	if err == nil && *format != "table" && *format != "json" {
+		err = fmt.Errorf("unknown -format %q (table or json)", *format)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "mazerun:", err)
+		os.Exit(2)
+	}
+
+	sys := mas.NewSystem()
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := sys.Shutdown(ctx); err != nil {
+			fmt.Fprintln(os.Stderr, "mazerun:", err)
+		}
+	}()
+	mazeSys := sys.CreateSubsystem()
+	mazeSys.Spawn(&console{BaseAgent: mas.BaseAgent{IDVal: "console"}})
+
+	var results []Result
+	out := json.NewEncoder(os.Stdout)
+	for _, p := range cfg.policies {
+		for n := range cfg.episodes {
+			res, err := runEpisode(mazeSys, cfg, p, n+1)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "mazerun:", err)
+				os.Exit(1)
+			}
+			if *format == "json" {
+				out.Encode(res)
+			}
+			results = append(results, res)
+		}
+	}
+
+	if *format == "table" {
+		printTable(os.Stdout, results)
+	}
+}
+
+func newConfig(policy string, size int, seed uint64, episodes, maxTicks int) (config, error) {
+	cfg := config{size: size, seed: seed, episodes: episodes, maxTicks: maxTicks}
+	if size < 5 || size%2 == 0 {
+		return cfg, fmt.Errorf("-size must be odd and at least 5, got %d", size)
+	}
+	if episodes < 1 {
+		return cfg, fmt.Errorf("-episodes must be positive, got %d", episodes)
+	}
+	if cfg.maxTicks <= 0 {
+		cfg.maxTicks = 4 * size * size
+	}
+
+	if strings.EqualFold(policy, "all") {
+		cfg.policies = maze.PolicyNames()
+		return cfg, nil
+	}
+	for name := range strings.SplitSeq(policy, ",") {
+		i := slices.IndexFunc(maze.PolicyNames(), func(p string) bool { return strings.EqualFold(p, strings.TrimSpace(name)) })
+		if i < 0 {
+			return cfg, fmt.Errorf("unknown policy %q (known: %s)", name, strings.Join(maze.PolicyNames(), ", "))
+		}
+		cfg.policies = append(cfg.policies, maze.PolicyNames()[i])
+	}
+	return cfg, nil
+}
+
+// walkerState - те, що читаємо з волкера між повідомленнями (System.Inspect).
+type walkerState struct {
+	steps      int
+	solved     bool
+	remembered int
+}
+
+func inspectWalker(sys *mas.System, id string) (walkerState, error) {
+	var st walkerState
+	err := sys.Inspect(id, func(a mas.Agent) {
+		w := a.(*maze.PlannerWalker)
+		st.steps, st.solved = w.Steps, w.Solved
+		if mem, ok := w.Memory.(*maze.MazeMemory); ok {
+			mem.Range(func(_, _ any) bool {
+				st.remembered++
+				return true
+			})
+		}
+	})
+	return st, err
+}
+
+// runEpisode грає один епізод у власній парі агентів і зупиняє їх наприкінці.
+// ID унікальні для епізоду: запізнілі повідомлення (авто-рестарт після перемоги)
+// не потраплять у наступний.
+func runEpisode(sys *mas.System, cfg config, policy string, n int) (Result, error) {
+	ctx := context.Background()
+	seed := cfg.seed + uint64(n-1)
+	world := maze.World{
+		MazeID:   fmt.Sprintf("maze-%s-%d", policy, n),
+		WalkerID: fmt.Sprintf("walker-%s-%d", policy, n),
+		Width:    cfg.size,
+		Height:   cfg.size,
+		Grid:     maze.GenerateMazeSeeded(cfg.size, cfg.size, seed),
+	}
+	res := Result{Episode: n, Policy: policy, Seed: seed, Size: cfg.size}
+
+	traffic, stopTap := sys.Tap()
+	defer stopTap()
+
+	if err := world.Spawn(sys); err != nil {
+		return res, err
+	}
+	defer sys.Stop(world.MazeID)
+	defer sys.Stop(world.WalkerID)
+
+	// POLICY ставить мозок і повертає волкера на старт
+	if err := sys.Send(ctx, runnerID, world.WalkerID, "POLICY:"+policy); err != nil {
+		return res, err
+	}
+	// Випадкова стратегія теж має бути відтворюваною
+	err := sys.Inspect(world.WalkerID, func(a mas.Agent) {
+		if brain, ok := a.(*maze.PlannerWalker).Brain.(interface{ Seed(int64) }); ok {
+			brain.Seed(int64(seed))
+		}
+	})
+	if err != nil {
+		return res, err
+	}
+
+	start := time.Now()
+	idle := 0
+	for res.Ticks < cfg.maxTicks && idle < maxIdleTicks {
+		if err := sys.Send(ctx, runnerID, world.WalkerID, "TICK"); err != nil {
+			return res, err
+		}
+		res.Ticks++
+
+		// Inspect виконується після TICK: якщо волкер вирішив ходити, MoveRequest уже надіслано
+		if _, err := inspectWalker(sys, world.WalkerID); err != nil {
+			return res, err
+		}
+		moved, err := awaitMove(traffic, world)
+		if err != nil {
+			return res, err
+		}
+		if !moved {
+			idle++
+			continue
+		}
+		idle = 0
+
+		st, err := inspectWalker(sys, world.WalkerID)
+		if err != nil {
+			return res, err
+		}
+		if st.solved {
+			break
+		}
+	}
+
+	st, err := inspectWalker(sys, world.WalkerID)
+	if err != nil {
+		return res, err
+	}
+	res.TimeMS = float64(time.Since(start).Microseconds()) / 1000
+	res.Steps, res.Success, res.Remembered = st.steps, st.solved, st.remembered
+	return res, nil
+}
+
+// awaitMove дивиться трафік: чи надіслав волкер MoveRequest, і якщо так -
+// чекає, поки він отримає MoveResult.
+func awaitMove(traffic <-chan mas.Traffic, world maze.World) (bool, error) {
+	requested := false
+	for !requested {
+		select {
+		case tr := <-traffic:
+			env := tr.Envelope
+			if env.From == world.WalkerID && env.To == world.MazeID {
+				_, requested = mas.PayloadAs[maze.MoveRequest](env)
+			}
+		default:
+			return false, nil // Черга подій порожня - ходу не було
+		}
+	}
+
+	timeout := time.After(moveTimeout)
+	for {
+		select {
+		case tr := <-traffic:
+			env := tr.Envelope
+			if env.To != world.WalkerID {
+				continue
+			}
+			if _, ok := mas.PayloadAs[maze.MoveResult](env); ok {
+				return true, nil
+			}
+		case <-timeout:
+			return false, errors.New("maze did not answer the move in " + moveTimeout.String())
+		}
+	}
+}
+
+func printTable(w io.Writer, results []Result) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "EPISODE\tPOLICY\tSEED\tSTEPS\tTICKS\tSUCCESS\tTIME(ms)\tREMEMBERED\t")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%d\t%d\t%t\t%.1f\t%d\t\n",
+			r.Episode, r.Policy, r.Seed, r.Steps, r.Ticks, r.Success, r.TimeMS, r.Remembered)
+	}
+	tw.Flush()
+
+	// Підсумок по стратегіях
+	var policies []string
+	for _, r := range results {
+		if !slices.Contains(policies, r.Policy) {
+			policies = append(policies, r.Policy)
+		}
+	}
+	for _, p := range policies {
+		var solved, total, steps int
+		for _, r := range results {
+			if r.Policy != p {
+				continue
+			}
+			total++
+			if r.Success {
+				solved++
+				steps += r.Steps
+			}
+		}
+		line := fmt.Sprintf("%s: solved %d/%d", p, solved, total)
+		if solved > 0 {
+			line += fmt.Sprintf(", avg steps %.1f", float64(steps)/float64(solved))
+		}
+		fmt.Fprintln(w, line)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/youryharchenko/go-mas/mas"
+	"github.com/youryharchenko/go-mas/simulations/maze"
+)
+
+func TestNewConfig(t *testing.T) {
+	cfg, err := newConfig("bfs, AStar", 7, 3, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(cfg.policies, []string{"BFS", "AStar"}) || cfg.maxTicks != 4*7*7 {
+		t.Fatalf("config %+v", cfg)
+	}
+	if cfg, _ := newConfig("all", 7, 1, 1, 0); !slices.Equal(cfg.policies, maze.PolicyNames()) {
+		t.Fatalf("all = %v", cfg.policies)
+	}
+
+	for _, bad := range []struct {
+		policy         string
+		size, episodes int
+	}{
+		{"DFS", 6, 1},
+		{"DFS", 3, 1},
+		{"DFS", 7, 0},
+		{"Teleport", 7, 1},
+	} {
+		if _, err := newConfig(bad.policy, bad.size, 1, bad.episodes, 0); err == nil {
+			t.Fatalf("%+v accepted", bad)
+		}
+	}
+}
+
+func TestRunEpisodeIsReproducible(t *testing.T) {
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(io.Discard)
+
+	sys := mas.NewSystem()
+	defer sys.Shutdown(context.Background())
+	mazeSys := sys.CreateSubsystem()
+	mazeSys.Spawn(&console{BaseAgent: mas.BaseAgent{IDVal: "console"}})
+
+	cfg, err := newConfig("BFS", 7, 42, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var results []Result
+	for n := range 2 {
+		// Епізод n грає з seed+n-1: обидва епізоди отримують лабіринт 42
+		cfg.seed = 42 - uint64(n)
+		res, err := runEpisode(mazeSys, cfg, "BFS", n+1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.Success || res.Steps == 0 || res.Remembered == 0 {
+			t.Fatalf("episode %+v", res)
+		}
+		results = append(results, res)
+	}
+	if results[0].Steps != results[1].Steps || results[0].Seed != results[1].Seed {
+		t.Fatalf("same maze, different runs: %+v", results)
+	}
+	// Агенти епізоду зупинені
+	if ids := mazeSys.AgentIDs(); !slices.Equal(ids, []string{"console"}) {
+		t.Fatalf("agents left after episodes: %v", ids)
+	}
+
+	var out bytes.Buffer
+	printTable(&out, results)
+	if !strings.Contains(out.String(), "BFS: solved 2/2") {
+		t.Fatalf("table:\n%s", out.String())
+	}
+}
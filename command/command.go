@@ -0,0 +1,428 @@
+// Package command - спільна мова команд для консолей агентів.
+//
+// Рядок консолі має вигляд "TARGET COMMAND [ARGS...]". Програма реєструє граматику:
+// яка команда в який payload перетворюється, а Language розбирає рядок,
+// перевіряє аргументи, розкриває адресатів і відправляє повідомлення:
+//
+//	lang := command.NewLanguage(
+//		command.Command{Name: "ORDER", Aliases: []string{"TASK"}, Args: []string{"TaskID", "Amount"},
+//			Help: "видати завдання", Build: buildOrder},
+//		command.Word("TICK", "наступний крок"),
+//	)
+//	lang.Execute(ctx, sys, "admin", `worker-* ORDER "job 1" 5`)
+//
+// Адресати: "worker-1", список "worker-1,worker-2", усі агенти "*", маска "worker-*".
+// Аргументи з пробілами беруться в лапки ("..." або '...'), \ екранує символ.
+// "HELP" або "HELP COMMAND" виводить довідку, а "SPAWN TYPE ID [Field=Value...]"
+// породжує агента зареєстрованого типу (mas.RegisterType) без повідомлень:
+//
+//	SPAWN WorkerBot worker-7 Count=5
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/youryharchenko/go-mas/mas"
+)
+
+// SendTimeout - скільки Execute чекає на місце у скриньці адресата.
+// Консоль працює в UI-потоці, тож довго блокуватися не можна.
+const SendTimeout = time.Second
+
+// Command - одна команда граматики.
+type Command struct {
+	Name    string   // Ключове слово (регістр не важливий)
+	Aliases []string // Інші назви тієї самої команди
+	Args    []string // Назви обов'язкових аргументів (для перевірки і довідки)
+	Rest    bool     // Останній аргумент може бути довшим (решта слів через пробіл)
+	Help    string   // Опис для HELP
+
+	// Build перетворює аргументи на payload. nil - payload є сама назва команди.
+	Build func(args []string) (any, error)
+
+	// Complete пропонує значення аргументу номер arg (необов'язково).
+	Complete func(arg int, prefix string) []string
+}
+
+// Usage - рядок використання: "TARGET ORDER <TaskID> <Amount>".
+func (c Command) Usage() string {
+	var b strings.Builder
+	b.WriteString("TARGET ")
+	b.WriteString(c.Name)
+	for i, arg := range c.Args {
+		b.WriteString(" <" + arg + ">")
+		if c.Rest && i == len(c.Args)-1 {
+			b.WriteString("...")
+		}
+	}
+	return b.String()
+}
+
+// Word - команда без аргументів, що відправляється як рядок (START, STOP, TICK).
+func Word(name, help string) Command {
+	return Command{Name: name, Help: help}
+}
+
+// UsageError - рядок не відповідає граматиці.
+type UsageError struct {
+	Command string // Порожня, якщо помилка не в конкретній команді
+	Msg     string
+	Usage   string
+}
+
+func (e *UsageError) Error() string {
+	msg := e.Msg
+	if e.Command != "" {
+		msg = e.Command + ": " + msg
+	}
+	if e.Usage != "" {
+		msg += " (usage: " + e.Usage + ")"
+	}
+	return msg
+}
+
+// ErrHelp повертає Parse для рядка "HELP ...": текст довідки - у Message.Help.
+var ErrHelp = errors.New("help requested")
+
+// Message - результат розбору рядка.
+type Message struct {
+	Targets []string // Розкриті адресати
+	Command string   // Канонічна назва команди
+	Payload any
+	Help    string        // Текст довідки (лише разом з ErrHelp)
+	Spawn   *SpawnRequest // Для SPAWN: кого породити (Targets порожні)
+}
+
+// SpawnRequest - розібраний рядок "SPAWN TYPE ID [Field=Value...]" (див. mas.System.SpawnByName).
+type SpawnRequest struct {
+	Type   string
+	ID     string
+	Params map[string]any // Значення - JSON (5, true, "a b"), інакше рядок як є
+}
+
+// spawnUsage - використання вбудованої команди SPAWN.
+const spawnUsage = "SPAWN TYPE ID [Field=Value...]"
+
+// Language - набір команд консолі.
+type Language struct {
+	commands map[string]*Command // Назви та аліаси у верхньому регістрі
+	order    []*Command          // У порядку реєстрації - для довідки
+}
+
+// NewLanguage створює мову з переліченими командами.
+func NewLanguage(cmds ...Command) *Language {
+	l := &Language{commands: make(map[string]*Command)}
+	for _, c := range cmds {
+		l.Register(c)
+	}
+	return l
+}
+
+// Register додає команду; команда з тією самою назвою замінюється.
+func (l *Language) Register(c Command) {
+	cmd := &c
+	if old, ok := l.commands[strings.ToUpper(c.Name)]; ok {
+		l.order = slices.DeleteFunc(l.order, func(o *Command) bool { return o == old })
+	}
+	l.order = append(l.order, cmd)
+	for _, name := range append([]string{c.Name}, c.Aliases...) {
+		l.commands[strings.ToUpper(name)] = cmd
+	}
+}
+
+// Lookup знаходить команду за назвою або аліасом.
+func (l *Language) Lookup(name string) (Command, bool) {
+	c, ok := l.commands[strings.ToUpper(name)]
+	if !ok {
+		return Command{}, false
+	}
+	return *c, true
+}
+
+// Help - довідка по всіх командах або по одній.
+func (l *Language) Help(name string) (string, error) {
+	if name != "" {
+		c, ok := l.commands[strings.ToUpper(name)]
+		if !ok {
+			return "", &UsageError{Msg: fmt.Sprintf("unknown command %q", name)}
+		}
+		return helpLine(c), nil
+	}
+
+	lines := []string{"Commands (TARGET: id, id1,id2, * or mask like worker-*):"}
+	for _, c := range l.order {
+		lines = append(lines, helpLine(c))
+	}
+	lines = append(lines, "  "+spawnUsage+" - породити агента зареєстрованого типу", "  HELP [COMMAND]")
+	return strings.Join(lines, "\n"), nil
+}
+
+func helpLine(c *Command) string {
+	line := "  " + c.Usage()
+	if len(c.Aliases) > 0 {
+		line += " (also " + strings.Join(c.Aliases, ", ") + ")"
+	}
+	if c.Help != "" {
+		line += " - " + c.Help
+	}
+	return line
+}
+
+// Parse розбирає рядок консолі. agents - відомі ID для "*" і масок.
+func (l *Language) Parse(line string, agents []string) (Message, error) {
+	words, err := Split(line)
+	if err != nil {
+		return Message{}, &UsageError{Msg: err.Error()}
+	}
+	if len(words) == 0 {
+		return Message{}, &UsageError{Msg: "empty command"}
+	}
+
+	if strings.EqualFold(words[0], "HELP") {
+		topic := ""
+		if len(words) > 1 {
+			topic = words[1]
+		}
+		text, err := l.Help(topic)
+		if err != nil {
+			return Message{}, err
+		}
+		return Message{Command: "HELP", Help: text}, ErrHelp
+	}
+	if strings.EqualFold(words[0], "SPAWN") {
+		return parseSpawn(words[1:])
+	}
+
+	if len(words) < 2 {
+		return Message{}, &UsageError{Msg: "missing command", Usage: "TARGET COMMAND [ARGS...]"}
+	}
+	targets, err := ExpandTargets(words[0], agents)
+	if err != nil {
+		return Message{}, err
+	}
+
+	c, ok := l.commands[strings.ToUpper(words[1])]
+	if !ok {
+		return Message{}, &UsageError{Msg: fmt.Sprintf("unknown command %q; type HELP", words[1])}
+	}
+	args := words[2:]
+	switch {
+	case len(args) < len(c.Args):
+		return Message{}, &UsageError{Command: c.Name, Msg: "not enough arguments", Usage: c.Usage()}
+	case len(args) > len(c.Args) && c.Rest && len(c.Args) > 0:
+		last := len(c.Args) - 1
+		args = append(args[:last:last], strings.Join(args[last:], " "))
+	case len(args) > len(c.Args):
+		return Message{}, &UsageError{Command: c.Name, Msg: "too many arguments", Usage: c.Usage()}
+	}
+
+	var payload any = c.Name
+	if c.Build != nil {
+		if payload, err = c.Build(args); err != nil {
+			return Message{}, &UsageError{Command: c.Name, Msg: err.Error(), Usage: c.Usage()}
+		}
+	}
+	return Message{Targets: targets, Command: c.Name, Payload: payload}, nil
+}
+
+// parseSpawn розбирає аргументи SPAWN: тип, ID і поля Field=Value.
+func parseSpawn(args []string) (Message, error) {
+	if len(args) < 2 {
+		return Message{}, &UsageError{Command: "SPAWN", Msg: "not enough arguments", Usage: spawnUsage}
+	}
+	req := &SpawnRequest{Type: args[0], ID: args[1]}
+	for _, arg := range args[2:] {
+		field, value, ok := strings.Cut(arg, "=")
+		if !ok || field == "" {
+			return Message{}, &UsageError{Command: "SPAWN", Msg: fmt.Sprintf("bad field %q", arg), Usage: spawnUsage}
+		}
+		if req.Params == nil {
+			req.Params = make(map[string]any)
+		}
+		var v any
+		if err := json.Unmarshal([]byte(value), &v); err != nil {
+			v = value // Не JSON - звичайний рядок (Name=bob)
+		}
+		req.Params[field] = v
+	}
+	return Message{Command: "SPAWN", Spawn: req}, nil
+}
+
+// Execute розбирає рядок і відправляє payload усім адресатам від імені from.
+// Щоб правила доступу бачили консоль, ctx має бути прив'язаний до її ідентичності
+// (mas.System.Authenticate) - тоді from береться з нього; інакше повідомлення анонімні.
+// Повертає рядки для журналу консолі: відправлені повідомлення або довідку.
+// Помилка доставки одному адресату не зупиняє розсилку іншим.
+func (l *Language) Execute(ctx context.Context, sys *mas.System, from, line string) ([]string, error) {
+	if id := mas.IdentityFrom(ctx); id != "" {
+		from = id
+	}
+
+	msg, err := l.Parse(line, others(sys.AgentIDs(), from))
+	if errors.Is(err, ErrHelp) {
+		return []string{msg.Help}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if req := msg.Spawn; req != nil {
+		if err := sys.SpawnByName(req.Type, req.ID, req.Params); err != nil {
+			return nil, fmt.Errorf("SPAWN %s: %w", req.ID, err)
+		}
+		return []string{fmt.Sprintf("[%s]: spawned %s %s", from, req.Type, req.ID)}, nil
+	}
+
+	var (
+		sent []string
+		errs []error
+	)
+	for _, to := range msg.Targets {
+		if err := sys.SendWithTimeout(ctx, from, to, msg.Payload, SendTimeout); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", to, err))
+			continue
+		}
+		sent = append(sent, fmt.Sprintf("[%s -> %s]: %v", from, to, msg.Payload))
+	}
+	return sent, errors.Join(errs...)
+}
+
+// others - усі агенти, крім самої консолі.
+func others(agents []string, from string) []string {
+	return slices.DeleteFunc(slices.Clone(agents), func(id string) bool { return id == from })
+}
+
+// ExpandTargets розкриває адресатів: список через кому, "*" і маски path.Match.
+// Звичайний ID не перевіряється: агент може жити в іншій системі.
+func ExpandTargets(spec string, agents []string) ([]string, error) {
+	var targets []string
+	for part := range strings.SplitSeq(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if !strings.ContainsAny(part, "*?[") {
+			targets = append(targets, part)
+			continue
+		}
+
+		matched := 0
+		for _, id := range agents {
+			ok, err := path.Match(part, id)
+			if err != nil {
+				return nil, &UsageError{Msg: fmt.Sprintf("bad target mask %q", part)}
+			}
+			if ok {
+				targets = append(targets, id)
+				matched++
+			}
+		}
+		if matched == 0 {
+			return nil, &UsageError{Msg: fmt.Sprintf("no agents match %q", part)}
+		}
+	}
+	if len(targets) == 0 {
+		return nil, &UsageError{Msg: "missing target", Usage: "TARGET COMMAND [ARGS...]"}
+	}
+
+	// Маски можуть перетинатися - кожному лише один раз, у порядку появи
+	seen := make(map[string]bool, len(targets))
+	return slices.DeleteFunc(targets, func(id string) bool {
+		dup := seen[id]
+		seen[id] = true
+		return dup
+	}), nil
+}
+
+// Complete пропонує доповнення для рядка: ID агентів у першому слові,
+// назви команд у другому (після SPAWN - типи агентів), далі - значення
+// аргументів (Command.Complete).
+// Повертає повні варіанти рядка, відсортовані.
+func (l *Language) Complete(line string, agents []string) []string {
+	words, err := Split(line)
+	if err != nil {
+		return nil // Незакрита лапка - нічого не пропонуємо
+	}
+	// Слово, що дописується зараз; порожнє, якщо рядок закінчується пробілом
+	if len(words) == 0 || strings.HasSuffix(line, " ") {
+		words = append(words, "")
+	}
+	prefix := words[len(words)-1]
+	if !strings.HasSuffix(line, prefix) {
+		return nil // Останнє слово в лапках або з екрануванням - не доповнюємо
+	}
+	head := line[:len(line)-len(prefix)]
+
+	var candidates []string
+	switch len(words) {
+	case 1:
+		// Список адресатів доповнюємо після останньої коми
+		done, last := "", prefix
+		if i := strings.LastIndex(prefix, ","); i >= 0 {
+			done, last = prefix[:i+1], prefix[i+1:]
+		}
+		for _, id := range agents {
+			candidates = append(candidates, done+id)
+		}
+		candidates = append(candidates, done+"*")
+		if done == "" {
+			candidates = append(candidates, "HELP", "SPAWN")
+		}
+		prefix = done + last
+
+	case 2:
+		if strings.EqualFold(words[0], "HELP") {
+			return nil
+		}
+		if strings.EqualFold(words[0], "SPAWN") {
+			candidates = mas.TypeNames()
+			break
+		}
+		for _, c := range l.order {
+			candidates = append(candidates, c.Name)
+		}
+
+	default:
+		if strings.EqualFold(words[0], "SPAWN") {
+			return nil // ID і поля вигадує користувач
+		}
+		c, ok := l.commands[strings.ToUpper(words[1])]
+		if !ok || c.Complete == nil {
+			return nil
+		}
+		candidates = c.Complete(len(words)-3, prefix)
+	}
+
+	var out []string
+	for _, cand := range candidates {
+		if strings.HasPrefix(strings.ToUpper(cand), strings.ToUpper(prefix)) {
+			out = append(out, head+cand)
+		}
+	}
+	sort.Strings(out)
+	return slices.Compact(out)
+}
+
+// CommonPrefix - найдовший спільний початок рядків (для доповнення Tab).
+func CommonPrefix(items []string) string {
+	if len(items) == 0 {
+		return ""
+	}
+	prefix := items[0]
+	for _, s := range items[1:] {
+		for !strings.HasPrefix(s, prefix) {
+			// Обрізаємо цілими рунами: ID можуть бути кирилицею
+			_, size := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+	return prefix
+}
@@ -0,0 +1,201 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/youryharchenko/go-mas/mas"
+	"github.com/youryharchenko/go-mas/mas/mastest"
+)
+
+// Order - payload тестової команди ORDER.
+type Order struct {
+	TaskID string
+	Amount int
+}
+
+func testLanguage() *Language {
+	return NewLanguage(
+		Command{Name: "ORDER", Aliases: []string{"TASK"}, Args: []string{"TaskID", "Amount"}, Help: "видати завдання",
+			Build: func(args []string) (any, error) {
+				n, err := strconv.Atoi(args[1])
+				if err != nil {
+					return nil, errors.New("amount must be a number")
+				}
+				return Order{TaskID: args[0], Amount: n}, nil
+			},
+			Complete: func(arg int, prefix string) []string {
+				if arg == 0 {
+					return []string{"job-1", "job-2", "clean"}
+				}
+				return nil
+			},
+		},
+		Command{Name: "SAY", Args: []string{"Text"}, Rest: true,
+			Build: func(args []string) (any, error) { return args[0], nil }},
+		Word("TICK", "наступний крок"),
+	)
+}
+
+var agents = []string{"boss-1", "worker-1", "worker-2", "воркер-1", "воркер-2"}
+
+func TestParse(t *testing.T) {
+	l := testLanguage()
+	for _, tc := range []struct {
+		line    string
+		targets []string
+		payload any
+	}{
+		{`worker-1 ORDER job 5`, []string{"worker-1"}, Order{"job", 5}},
+		{`worker-1,boss-1 task "job 1" 2`, []string{"worker-1", "boss-1"}, Order{"job 1", 2}},
+		{`worker-*,worker-1 TICK`, []string{"worker-1", "worker-2"}, "TICK"},
+		{`* tick`, agents, "TICK"},
+		{`воркер-? SAY добрий   день`, []string{"воркер-1", "воркер-2"}, "добрий день"},
+		{`remote-1 SAY 'a \ b'`, []string{"remote-1"}, `a \ b`},
+	} {
+		msg, err := l.Parse(tc.line, agents)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.line, err)
+		}
+		if !slices.Equal(msg.Targets, tc.targets) || !reflect.DeepEqual(msg.Payload, tc.payload) {
+			t.Fatalf("%s: got %v %#v, want %v %#v", tc.line, msg.Targets, msg.Payload, tc.targets, tc.payload)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	l := testLanguage()
+	for line, want := range map[string]string{
+		``:                        "empty command",
+		`worker-1`:                "missing command",
+		`worker-1 DANCE`:          "unknown command",
+		`worker-1 ORDER job`:      "not enough arguments",
+		`worker-1 ORDER job 1 2`:  "too many arguments",
+		`worker-1 ORDER job five`: "amount must be a number",
+		`nobody-* TICK`:           "no agents match",
+		`worker-[ TICK`:           "bad target mask",
+		`worker-1 SAY "hi`:        "unterminated quote",
+		`SPAWN WorkerBot`:         "not enough arguments",
+		`SPAWN WorkerBot w-1 =5`:  "bad field",
+	} {
+		_, err := l.Parse(line, agents)
+		var uerr *UsageError
+		if !errors.As(err, &uerr) || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%q: got %v, want UsageError with %q", line, err, want)
+		}
+	}
+}
+
+func TestParseHelpAndSpawn(t *testing.T) {
+	l := testLanguage()
+
+	msg, err := l.Parse("help task", agents)
+	if !errors.Is(err, ErrHelp) || !strings.Contains(msg.Help, "ORDER <TaskID> <Amount> (also TASK) - видати завдання") {
+		t.Fatalf("help: %v %q", err, msg.Help)
+	}
+
+	msg, err = l.Parse(`SPAWN WorkerBot w-7 Count=5 Name="bob smith" Tag=x`, agents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &SpawnRequest{Type: "WorkerBot", ID: "w-7", Params: map[string]any{"Count": 5.0, "Name": "bob smith", "Tag": "x"}}
+	if !reflect.DeepEqual(msg.Spawn, want) || msg.Targets != nil {
+		t.Fatalf("spawn %+v, want %+v", msg.Spawn, want)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	l := testLanguage()
+	for line, want := range map[string][]string{
+		`wor`:                {"worker-1", "worker-2"},
+		`boss-1,w`:           {"boss-1,worker-1", "boss-1,worker-2"},
+		`во`:                 {"воркер-1", "воркер-2"},
+		`worker-1 t`:         {"worker-1 TICK"},
+		`worker-1 order j`:   {"worker-1 order job-1", "worker-1 order job-2"},
+		`worker-1 ORDER `:    {"worker-1 ORDER clean", "worker-1 ORDER job-1", "worker-1 ORDER job-2"},
+		`worker-1 ORDER "j`:  nil, // Незакрита лапка
+		`worker-1 SAY x `:    nil, // Без Complete
+		`HELP `:              nil,
+		`worker-1 ORDER j 5`: nil,
+	} {
+		if got := l.Complete(line, agents); !slices.Equal(got, want) {
+			t.Fatalf("%q: got %q, want %q", line, got, want)
+		}
+	}
+}
+
+func TestCommonPrefix(t *testing.T) {
+	for _, tc := range []struct {
+		items []string
+		want  string
+	}{
+		{nil, ""},
+		{[]string{"worker-1"}, "worker-1"},
+		{[]string{"worker-1", "worker-2", "workshop"}, "work"},
+		{[]string{"воркер-1", "воркер-2"}, "воркер-"},
+		// "ї" і "і" мають спільний перший байт - він не має потрапити в префікс
+		{[]string{"її", "іа"}, ""},
+		{[]string{"агент-ї", "агент-і"}, "агент-"},
+	} {
+		got := CommonPrefix(tc.items)
+		if got != tc.want {
+			t.Fatalf("CommonPrefix(%q) = %q, want %q", tc.items, got, tc.want)
+		}
+	}
+}
+
+// sinkAgent приймає все мовчки.
+type sinkAgent struct {
+	mas.BaseAgent
+}
+
+func (a *sinkAgent) Plan(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
+	return nil, nil
+}
+
+func init() {
+	mas.RegisterType("command.sinkAgent", func() mas.Agent { return &sinkAgent{} })
+}
+
+func TestExecute(t *testing.T) {
+	ts := mastest.New(t)
+	for _, id := range []string{"console", "worker-1", "worker-2"} {
+		ts.Spawn(&sinkAgent{BaseAgent: mas.BaseAgent{IDVal: id}})
+	}
+	l := testLanguage()
+	ctx := context.Background()
+
+	// "*" - усі, крім самої консолі
+	out, err := l.Execute(ctx, ts.Sys, "console", "* ORDER job 3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"[console -> worker-1]: {job 3}", "[console -> worker-2]: {job 3}"}; !slices.Equal(out, want) {
+		t.Fatalf("log %q, want %q", out, want)
+	}
+	for _, id := range []string{"worker-1", "worker-2"} {
+		ts.ExpectMessage(id, mas.PayloadEquals(Order{"job", 3}))
+	}
+	ts.ExpectNoMessage("console", mas.MatchAny())
+
+	out, err = l.Execute(ctx, ts.Sys, "console", "HELP")
+	if err != nil || len(out) != 1 || !strings.Contains(out[0], "TARGET TICK - наступний крок") {
+		t.Fatalf("help: %v %q", err, out)
+	}
+
+	if _, err := l.Execute(ctx, ts.Sys, "console", "SPAWN command.sinkAgent worker-3"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ts.Sys.GetAgent("worker-3"); !ok {
+		t.Fatal("worker-3 was not spawned")
+	}
+
+	if _, err := l.Execute(ctx, ts.Sys, "console", "worker-1 ORDER job"); err == nil {
+		t.Fatal("bad line executed")
+	}
+}
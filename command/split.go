@@ -0,0 +1,61 @@
+package command
+
+import (
+	"errors"
+	"strings"
+)
+
+// Split ділить рядок на слова за пробілами з урахуванням лапок:
+// `ORDER "job 1" 5` -> [ORDER, job 1, 5]. У "..." і поза лапками \ екранує наступний символ,
+// у '...' все береться буквально.
+func Split(line string) ([]string, error) {
+	var (
+		words   []string
+		cur     strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range line {
+		switch {
+		case escaped:
+			cur.WriteRune(r)
+			escaped = false
+
+		case r == '\\' && quote != '\'':
+			escaped, inWord = true, true
+
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+
+		case r == '"' || r == '\'':
+			quote, inWord = r, true
+
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, cur.String())
+				cur.Reset()
+				inWord = false
+			}
+
+		default:
+			cur.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, errors.New("unterminated quote")
+	}
+	if escaped {
+		return nil, errors.New("trailing backslash")
+	}
+	if inWord {
+		words = append(words, cur.String())
+	}
+	return words, nil
+}
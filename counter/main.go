@@ -2,38 +2,15 @@ package main
 
 import (
 	"context"
-	"encoding/gob"
 	"log"
 	"time"
 
+	"github.com/youryharchenko/go-mas/counter/models"
 	"github.com/youryharchenko/go-mas/mas"
 )
 
-type CounterBot struct {
-	mas.BaseAgent // Вбудовуємо базу
-	Count         int
-}
-
-// Реалізуємо Planner прямо для бота, або окремо
-func (c *CounterBot) Plan(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
-	//me := state.(*CounterBot) // Type assertion
-
-	if msg.Payload == "INC" {
-		return []mas.Action{
-			// Дія 1: Змінити стан
-			mas.MutateState(func(a any) {
-				a.(*CounterBot).Count++
-			}),
-			// Дія 2: Логувати
-			mas.SayLog("Count increased to %d", c.Count+1),
-		}, nil
-	}
-
-	return nil, nil
-}
-
 func init() {
-	gob.Register(&CounterBot{})
+	models.Register()
 }
 
 func main() {
@@ -44,15 +21,14 @@ func main() {
 		panic(err)
 	}
 
-	// 2. Якщо це перший запуск (агентів немає), створюємо початкового
-	// У реальній системі тут може бути перевірка if sys.CountAgents() == 0
-	if agent, exists := sys.GetAgent("worker-1"); !exists {
-		newAgent := &CounterBot{BaseAgent: mas.BaseAgent{IDVal: "worker-1"}, Count: 0}
-		sys.Spawn(newAgent)
-		log.Println("New CounterBot:", newAgent.ID(), newAgent.Count)
-	} else {
-		if oldAgent, ok := agent.(*CounterBot); ok {
-			log.Println("Old CounterBot:", oldAgent.ID(), oldAgent.Count)
+	// 2. Якщо це перший запуск (агентів немає), world.toml створить початкових;
+	// відновлених LoadWorld не чіпає
+	if err := sys.LoadWorld("world.toml"); err != nil {
+		log.Fatal(err)
+	}
+	if agent, ok := sys.GetAgent("worker-1"); ok {
+		if bot, ok := agent.(*models.CounterBot); ok {
+			log.Println("CounterBot:", bot.ID(), bot.Count)
 		} else {
 			log.Println("Agent is Not CounterBot:", agent.ID())
 		}
@@ -68,9 +44,16 @@ func main() {
 	if err != nil {
 		log.Println(err)
 	}
+	if err := sys.Send(sendCtx, "main", "worker-2", "INC"); err != nil {
+		log.Println(err)
+	}
 	log.Println("After Send")
 
 	// 3. Коректне завершення збереже "worker-1" з Count=1
 	// При наступному запуску він прокинеться вже з Count=1
-	sys.Shutdown()
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelShutdown()
+	if err := sys.Shutdown(shutdownCtx); err != nil {
+		log.Println(err)
+	}
 }
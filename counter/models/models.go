@@ -0,0 +1,63 @@
+// Package models - агенти демо counter.
+package models
+
+import (
+	"context"
+	"encoding/gob"
+
+	"github.com/youryharchenko/go-mas/mas"
+)
+
+type CounterBot struct {
+	mas.BaseAgent // Вбудовуємо базу
+	Count         int
+}
+
+// Реалізуємо Planner прямо для бота, або окремо
+func (c *CounterBot) Plan(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
+	//me := state.(*CounterBot) // Type assertion
+
+	if msg.Payload == "INC" {
+		return []mas.Action{
+			// Дія 1: Змінити стан
+			mas.MutateState(func(a any) {
+				a.(*CounterBot).Count++
+			}),
+			// Дія 2: Логувати
+			mas.SayLog("Count increased to %d", c.Count+1),
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// CounterState - стан лічильника, окремий від мозку (для mas.PlannerAgent).
+type CounterState struct {
+	Count int
+}
+
+// counterPlanner - той самий лічильник, але як чистий mas.Planner:
+// отримує копію стану і лише каже, що зробити.
+var counterPlanner = mas.PlannerFunc(func(ctx context.Context, state any, msg mas.Envelope) ([]mas.Action, error) {
+	st := state.(CounterState)
+
+	if msg.Payload == "INC" {
+		st.Count++
+		return []mas.Action{
+			mas.SetState(st),
+			mas.SayLog("Count increased to %d", st.Count),
+		}, nil
+	}
+
+	return nil, nil
+})
+
+// Register реєструє типи в GOB під іменами, з якими їх зберігала програма counter
+// (поки вони жили в package main), Planner "counter" і типи агентів (mas.RegisterType).
+func Register() {
+	gob.RegisterName("main.CounterState", CounterState{})
+	mas.RegisterPlanner("counter", counterPlanner)
+
+	mas.RegisterType("CounterBot", func() mas.Agent { return &CounterBot{} }, mas.WithGobName("*main.CounterBot"))
+	mas.RegisterType("Counter", func() mas.Agent { return mas.NewPlannerAgent("", "counter", CounterState{}) })
+}
@@ -0,0 +1,444 @@
+// Package gateway - агент, що відкриває запущену mas.System через локальний HTTP API.
+//
+//	GET  /agents                   - список агентів: [{"id", "type"}]
+//	GET  /agents/{id}              - знімок стану агента (System.Snapshot) у JSON
+//	POST /agents/{id}/messages     - відправити повідомлення: {"from", "performative", "type", "payload"}
+//	POST /topics/{topic}           - опублікувати в тему (System.Publish), тіло те саме
+//	GET  /feed[?agent=ID&topic=T]  - WebSocket: JSON-потік доставлених повідомлень і публікацій
+//	GET  /health                   - System.Health(): 200, якщо всі агенти OK, інакше 503
+//
+// Події Watchdog (System.StartWatchdog) ідуть у тему mas.HealthTopic: /feed?topic=health.
+//
+// API лише локальний: запити із заголовком Origin не з loopback (сторінки з інших сайтів
+// у браузері) відхиляються з 403, так само й рукостискання /feed. POST приймає
+// лише Content-Type: application/json (інакше 415) - браузер не надішле такий запит
+// на чужий сайт без preflight.
+//
+// "type" - ім'я, під яким payload зареєстровано через RegisterPayload; тоді "payload"
+// розбирається в цей тип. Без "type" payload передається як є: рядок - рядком ("TICK").
+//
+//	gateway.RegisterPayload("WorkOrder", models.WorkOrder{})
+//	sys.Spawn(gateway.New("gateway", "127.0.0.1:8080"))
+//
+//	curl -H 'Content-Type: application/json' -d '{"type":"WorkOrder","payload":{"TaskID":"job-1","Amount":3}}' localhost:8080/agents/worker-1/messages
+//
+// Handler можна використовувати і без мережі - наприклад, з httptest.
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/youryharchenko/go-mas/mas"
+	"golang.org/x/net/websocket"
+)
+
+// SendTimeout - скільки запит чекає на місце у скриньці агента.
+const SendTimeout = time.Second
+
+var (
+	payloadsMu sync.RWMutex
+	payloads   = map[string]reflect.Type{}
+)
+
+// RegisterPayload дозволяє передавати через API payload типу proto під ім'ям name.
+func RegisterPayload(name string, proto any) {
+	payloadsMu.Lock()
+	defer payloadsMu.Unlock()
+	payloads[name] = reflect.TypeOf(proto)
+}
+
+// decodePayload розбирає JSON у зареєстрований тип (або як є, якщо name порожнє).
+func decodePayload(name string, raw json.RawMessage) (any, error) {
+	if name == "" {
+		var v any
+		if len(raw) == 0 {
+			return nil, errors.New("payload is required")
+		}
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+
+	payloadsMu.RLock()
+	t, ok := payloads[name]
+	payloadsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("payload type %q is not registered", name)
+	}
+
+	ptr := reflect.New(t)
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, ptr.Interface()); err != nil {
+			return nil, fmt.Errorf("payload %s: %w", name, err)
+		}
+	}
+	return ptr.Elem().Interface(), nil
+}
+
+// Gateway - агент з HTTP-сервером. Сервер живе, поки живе агент.
+// Повідомлення, які отримує сам Gateway (наприклад, відповіді на його запити),
+// нікуди не діваються: їх видно в /feed.
+type Gateway struct {
+	mas.BaseAgent
+
+	Addr string // Адреса для net.Listen; порожня - сервер не запускається (лише Handler)
+
+	base context.Context // Ідентичність шлюзу для запитів (з Run; mas.Detach)
+}
+
+// New створює шлюз, що слухатиме addr.
+func New(id, addr string) *Gateway {
+	return &Gateway{
+		BaseAgent: mas.BaseAgent{IDVal: id},
+		Addr:      addr,
+	}
+}
+
+// Run піднімає HTTP-сервер і працює як звичайний агент; зупинка агента зупиняє сервер.
+func (g *Gateway) Run(ctx context.Context) error {
+	// Запити йдуть від імені шлюзу, але як код ззовні: Shutdown їх відріже
+	g.base = mas.Detach(ctx)
+	if g.Addr != "" {
+		ln, err := net.Listen("tcp", g.Addr)
+		if err != nil {
+			return fmt.Errorf("gateway %s: %w", g.IDVal, err)
+		}
+		srv := &http.Server{Handler: g.Handler()}
+		go func() {
+			if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Printf("Gateway %s: %v", g.IDVal, err)
+			}
+		}()
+		defer srv.Close()
+		log.Printf("Gateway %s listening on %s", g.IDVal, ln.Addr())
+	}
+	return g.BaseAgent.Run(ctx)
+}
+
+// Plan лише приймає повідомлення: вони вже потрапили у стрічку /feed.
+func (g *Gateway) Plan(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
+	return nil, nil
+}
+
+// Handler повертає HTTP API для системи агента (після запуску; до нього запити анонімні).
+func (g *Gateway) Handler() http.Handler {
+	base := g.base
+	if base == nil {
+		base = context.Background()
+	}
+	return NewHandler(g.Sys(), base)
+}
+
+// NewHandler - HTTP API до sys без агента-шлюзу. Усі запити відправляються
+// з ідентичністю base (mas.System.Authenticate, mas.Detach) - її бачать правила доступу;
+// з неприв'язаним base запити анонімні.
+func NewHandler(sys *mas.System, base context.Context) http.Handler {
+	h := &handler{sys: sys, base: base, from: mas.IdentityFrom(base)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /agents", h.listAgents)
+	mux.HandleFunc("GET /agents/{id}", h.getAgent)
+	mux.HandleFunc("POST /agents/{id}/messages", h.postMessage)
+	mux.HandleFunc("POST /topics/{topic}", h.publish)
+	mux.HandleFunc("GET /health", h.health)
+	mux.Handle("GET /feed", websocket.Server{Handler: h.feed, Handshake: h.handshake})
+	return localOnly(mux)
+}
+
+// localOnly відхиляє запити, надіслані зі сторінок не з loopback.
+func localOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := checkOrigin(r.Header.Get("Origin")); err != nil {
+			writeError(w, http.StatusForbidden, err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// checkOrigin пропускає запити без Origin (curl, скрипти) і з localhost або loopback-IP.
+func checkOrigin(origin string) error {
+	if origin == "" {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return fmt.Errorf("bad origin %q", origin)
+	}
+	host := u.Hostname()
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("origin %q is not allowed", origin)
+}
+
+// handshake - перевірка Origin для WebSocket (клієнти WebSocket завжди його надсилають).
+func (h *handler) handshake(cfg *websocket.Config, r *http.Request) error {
+	return checkOrigin(r.Header.Get("Origin"))
+}
+
+type handler struct {
+	sys  *mas.System
+	base context.Context
+	from string // Ідентичність base ("" - анонімно)
+}
+
+// agentInfo - рядок списку /agents.
+type agentInfo struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// agentState - відповідь /agents/{id}.
+type agentState struct {
+	ID    string    `json:"id"`
+	Type  string    `json:"type"`
+	State mas.Agent `json:"state"`
+}
+
+// healthReport - відповідь /health.
+type healthReport struct {
+	Status  mas.HealthStatus `json:"status"`
+	Agents  []agentHealth    `json:"agents"`
+	Metrics mas.Metrics      `json:"metrics"`
+}
+
+// agentHealth - рядок /health (тривалості - у мілісекундах).
+type agentHealth struct {
+	ID         string           `json:"id"`
+	Status     mas.HealthStatus `json:"status"`
+	Processing string           `json:"processing,omitempty"`
+	BusyMs     int64            `json:"busy_ms,omitempty"`
+	Queue      int              `json:"queue"`
+	IdleMs     int64            `json:"idle_ms"`
+	Restarts   int              `json:"restarts,omitempty"`
+}
+
+// messageRequest - тіло POST /agents/{id}/messages і /topics/{topic}.
+type messageRequest struct {
+	From         string           `json:"from"`
+	Performative mas.Performative `json:"performative"`
+	Type         string           `json:"type"`
+	Payload      json.RawMessage  `json:"payload"`
+}
+
+func (h *handler) listAgents(w http.ResponseWriter, r *http.Request) {
+	ids := h.sys.AgentIDs()
+	out := make([]agentInfo, 0, len(ids))
+	for _, id := range ids {
+		info := agentInfo{ID: id}
+		if a, ok := h.sys.GetAgent(id); ok {
+			info.Type = fmt.Sprintf("%T", a)
+		}
+		out = append(out, info)
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (h *handler) getAgent(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !h.known(id) {
+		writeError(w, http.StatusNotFound, fmt.Errorf("agent %q not found", id))
+		return
+	}
+	snap, err := h.sys.Snapshot(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, agentState{ID: id, Type: fmt.Sprintf("%T", snap), State: snap})
+}
+
+func (h *handler) postMessage(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !h.known(id) {
+		writeError(w, http.StatusNotFound, fmt.Errorf("agent %q not found", id))
+		return
+	}
+	ctx, payload, ok := h.readMessage(w, r)
+	if !ok {
+		return
+	}
+	if err := h.sys.SendWithTimeout(ctx, h.from, id, payload, SendTimeout); err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *handler) publish(w http.ResponseWriter, r *http.Request) {
+	ctx, payload, ok := h.readMessage(w, r)
+	if !ok {
+		return
+	}
+	if err := h.sys.Publish(ctx, h.from, r.PathValue("topic"), payload); err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *handler) health(w http.ResponseWriter, r *http.Request) {
+	health := h.sys.Health()
+	out := healthReport{Status: health.Status, Agents: make([]agentHealth, 0, len(health.Agents)), Metrics: health.Metrics}
+	for _, a := range health.Agents {
+		row := agentHealth{
+			ID:       a.ID,
+			Status:   a.Status,
+			Queue:    a.Queue,
+			IdleMs:   a.Idle.Milliseconds(),
+			Restarts: a.Restarts,
+		}
+		if a.Message != nil {
+			row.Processing = fmt.Sprintf("%v from %s", a.Message.Payload, a.Message.From)
+			row.BusyMs = a.Busy.Milliseconds()
+		}
+		out.Agents = append(out.Agents, row)
+	}
+
+	status := http.StatusOK
+	if health.Status != mas.HealthOK {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, out)
+}
+
+// readMessage розбирає тіло запиту; при помилці сам відповідає 415 або 400.
+func (h *handler) readMessage(w http.ResponseWriter, r *http.Request) (context.Context, any, bool) {
+	if media, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || media != "application/json" {
+		writeError(w, http.StatusUnsupportedMediaType, errors.New("Content-Type must be application/json"))
+		return nil, nil, false
+	}
+
+	var req messageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("bad request body: %w", err))
+		return nil, nil, false
+	}
+	payload, err := decodePayload(req.Type, req.Payload)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return nil, nil, false
+	}
+
+	// Повідомлення живе довше за HTTP-запит - не прив'язуємо його до r.Context().
+	// Відправника ставить System з ідентичності шлюзу, а "from" з тіла -
+	// лише адреса для відповіді (Envelope.ReplyTo), а не той, за кого себе видає клієнт.
+	ctx := h.base
+	if req.From != "" {
+		ctx = mas.WithReplyTo(ctx, req.From)
+	}
+	if req.Performative != "" {
+		ctx = mas.WithPerformative(ctx, req.Performative)
+	}
+	return ctx, payload, true
+}
+
+func (h *handler) known(id string) bool {
+	return slices.Contains(h.sys.AgentIDs(), id)
+}
+
+// Event - запис стрічки /feed.
+type Event struct {
+	From         string           `json:"from"`
+	Sender       string           `json:"sender,omitempty"` // Хто відправив насправді (Envelope.Sender)
+	To           string           `json:"to,omitempty"`
+	Topic        string           `json:"topic,omitempty"`
+	Performative mas.Performative `json:"performative,omitempty"`
+	Type         string           `json:"type"`
+	Payload      any              `json:"payload"`
+}
+
+// feed транслює Tap у WebSocket, поки клієнт або система не відключаться.
+func (h *handler) feed(ws *websocket.Conn) {
+	defer ws.Close()
+	agent := ws.Request().URL.Query().Get("agent")
+	topic := ws.Request().URL.Query().Get("topic")
+
+	traffic, cancel := h.sys.Tap()
+	defer cancel()
+
+	// Клієнт нічого не надсилає; читання потрібне, щоб помітити відключення
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		var discard []byte
+		for websocket.Message.Receive(ws, &discard) == nil {
+		}
+	}()
+
+	for {
+		select {
+		case tr, ok := <-traffic:
+			if !ok {
+				return
+			}
+			env := tr.Envelope
+			if agent != "" && env.From != agent && env.To != agent {
+				continue
+			}
+			if topic != "" && tr.Topic != topic {
+				continue
+			}
+			if err := websocket.JSON.Send(ws, newEvent(tr)); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		case <-h.sys.Context().Done():
+			return
+		}
+	}
+}
+
+func newEvent(tr mas.Traffic) Event {
+	env := tr.Envelope
+	ev := Event{
+		From:         env.From,
+		Sender:       env.Sender,
+		To:           env.To,
+		Topic:        tr.Topic,
+		Performative: env.Type,
+		Type:         fmt.Sprintf("%T", env.Payload),
+		Payload:      env.Payload,
+	}
+	// Не все серіалізується в JSON (канали, функції) - тоді показуємо текстом
+	if _, err := json.Marshal(env.Payload); err != nil {
+		ev.Payload = fmt.Sprintf("%+v", env.Payload)
+	}
+	return ev
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		log.Printf("Gateway: encode response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func init() {
+	mas.RegisterType("Gateway", func() mas.Agent { return &Gateway{} })
+}
@@ -0,0 +1,253 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/youryharchenko/go-mas/mas"
+	"golang.org/x/net/websocket"
+)
+
+type order struct {
+	TaskID string
+	Amount int
+}
+
+// sinkAgent складає отримане в канал.
+type sinkAgent struct {
+	mas.BaseAgent
+	Count int
+	got   chan mas.Envelope
+}
+
+func (a *sinkAgent) Plan(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
+	a.got <- msg
+	return nil, nil
+}
+
+func (a *sinkAgent) Clone() mas.Agent {
+	c := *a
+	return &c
+}
+
+func newSink(id string) *sinkAgent {
+	return &sinkAgent{BaseAgent: mas.BaseAgent{IDVal: id}, Count: 7, got: make(chan mas.Envelope, 8)}
+}
+
+func init() {
+	RegisterPayload("order", order{})
+}
+
+// newTestServer - система з агентом worker-1 і підсистемою з maze-1.
+func newTestServer(t *testing.T) (*httptest.Server, *sinkAgent, *sinkAgent) {
+	t.Helper()
+	sys := mas.NewSystem()
+	worker := newSink("worker-1")
+	if err := sys.Spawn(worker); err != nil {
+		t.Fatal(err)
+	}
+	sub := sys.CreateSubsystem()
+	maze := newSink("maze-1")
+	if err := sub.Spawn(maze); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(NewHandler(sys, context.Background()))
+	t.Cleanup(func() {
+		srv.Close()
+		sys.Shutdown(context.Background())
+	})
+	return srv, worker, maze
+}
+
+func post(t *testing.T, url, contentType, body string) *http.Response {
+	t.Helper()
+	resp, err := http.Post(url, contentType, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp
+}
+
+func getJSON(t *testing.T, url string, out any) int {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func receive(t *testing.T, ch <-chan mas.Envelope) mas.Envelope {
+	t.Helper()
+	select {
+	case env := <-ch:
+		return env
+	case <-time.After(time.Second):
+		t.Fatal("no message")
+		return mas.Envelope{}
+	}
+}
+
+func TestListAgents(t *testing.T) {
+	srv, _, _ := newTestServer(t)
+
+	var agents []agentInfo
+	if status := getJSON(t, srv.URL+"/agents", &agents); status != http.StatusOK {
+		t.Fatalf("status %d", status)
+	}
+	want := []agentInfo{{ID: "maze-1", Type: "*gateway.sinkAgent"}, {ID: "worker-1", Type: "*gateway.sinkAgent"}}
+	if len(agents) != len(want) {
+		t.Fatalf("agents = %+v, want %+v", agents, want)
+	}
+	for i := range want {
+		if agents[i] != want[i] {
+			t.Fatalf("agents = %+v, want %+v", agents, want)
+		}
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	srv, _, _ := newTestServer(t)
+
+	// Агент підсистеми знаходиться так само, як і свій
+	for _, id := range []string{"worker-1", "maze-1"} {
+		var state struct {
+			ID    string
+			Type  string
+			State struct{ Count int }
+		}
+		if status := getJSON(t, srv.URL+"/agents/"+id, &state); status != http.StatusOK {
+			t.Fatalf("%s: status %d", id, status)
+		}
+		if state.ID != id || state.Type != "*gateway.sinkAgent" || state.State.Count != 7 {
+			t.Fatalf("%s: got %+v", id, state)
+		}
+	}
+}
+
+func TestPostMessage(t *testing.T) {
+	srv, worker, maze := newTestServer(t)
+
+	resp := post(t, srv.URL+"/agents/worker-1/messages", "application/json",
+		`{"from":"ui","type":"order","payload":{"TaskID":"job-1","Amount":3}}`)
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+	env := receive(t, worker.got)
+	if env.Payload != (order{TaskID: "job-1", Amount: 3}) {
+		t.Fatalf("payload %#v", env.Payload)
+	}
+	if env.ReplyAddr() != "ui" || env.Sender != "" {
+		t.Fatalf("reply to %q, sender %q", env.ReplyAddr(), env.Sender)
+	}
+
+	resp = post(t, srv.URL+"/agents/maze-1/messages", "application/json; charset=utf-8", `{"payload":"TICK"}`)
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("subsystem: status %d", resp.StatusCode)
+	}
+	if env := receive(t, maze.got); env.Payload != "TICK" {
+		t.Fatalf("payload %#v", env.Payload)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	srv, _, _ := newTestServer(t)
+
+	if status := getJSON(t, srv.URL+"/agents/nobody", nil); status != http.StatusNotFound {
+		t.Fatalf("GET unknown agent: status %d", status)
+	}
+
+	cases := []struct {
+		name, path, contentType, body string
+		status                        int
+	}{
+		{"unknown agent", "/agents/nobody/messages", "application/json", `{"payload":"TICK"}`, http.StatusNotFound},
+		{"bad json", "/agents/worker-1/messages", "application/json", `{`, http.StatusBadRequest},
+		{"no payload", "/agents/worker-1/messages", "application/json", `{}`, http.StatusBadRequest},
+		{"unknown type", "/agents/worker-1/messages", "application/json", `{"type":"nope","payload":{}}`, http.StatusBadRequest},
+		{"form post", "/agents/worker-1/messages", "application/x-www-form-urlencoded", `{"payload":"TICK"}`, http.StatusUnsupportedMediaType},
+		{"text topic", "/topics/news", "text/plain", `{"payload":"TICK"}`, http.StatusUnsupportedMediaType},
+	}
+	for _, c := range cases {
+		if resp := post(t, srv.URL+c.path, c.contentType, c.body); resp.StatusCode != c.status {
+			t.Errorf("%s: status %d, want %d", c.name, resp.StatusCode, c.status)
+		}
+	}
+}
+
+func TestOrigin(t *testing.T) {
+	srv, _, _ := newTestServer(t)
+
+	for origin, status := range map[string]int{
+		"":                       http.StatusOK,
+		"http://localhost:3000":  http.StatusOK,
+		"http://127.0.0.1":       http.StatusOK,
+		"http://[::1]:8080":      http.StatusOK,
+		"https://evil.example":   http.StatusForbidden,
+		"http://localhost.evil":  http.StatusForbidden,
+		"http://192.168.1.10:80": http.StatusForbidden,
+	} {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+"/agents", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != status {
+			t.Errorf("origin %q: status %d, want %d", origin, resp.StatusCode, status)
+		}
+	}
+
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/feed"
+	if ws, err := websocket.Dial(wsURL, "", "https://evil.example"); err == nil {
+		ws.Close()
+		t.Fatal("feed accepted a foreign origin")
+	}
+}
+
+func TestFeed(t *testing.T) {
+	srv, worker, _ := newTestServer(t)
+
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/feed?agent=worker-1", "", "http://localhost/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	// Підписка на Tap відбувається вже після рукостискання - шлемо, доки подія не прийде
+	events := make(chan Event, 1)
+	go func() {
+		var ev Event
+		if websocket.JSON.Receive(ws, &ev) == nil {
+			events <- ev
+		}
+	}()
+	for {
+		post(t, srv.URL+"/agents/worker-1/messages", "application/json", `{"payload":"PING"}`)
+		receive(t, worker.got)
+		select {
+		case ev := <-events:
+			if ev.To != "worker-1" || ev.Payload != "PING" || ev.Type != "string" {
+				t.Fatalf("event %+v", ev)
+			}
+			return
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
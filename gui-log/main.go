@@ -5,21 +5,28 @@ import (
 	"fmt"
 	"log"
 	"strconv"
-	"strings"
 	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/data/binding"
+	"github.com/youryharchenko/go-mas/command"
+	"github.com/youryharchenko/go-mas/gui-log/models"
 	"github.com/youryharchenko/go-mas/mas"
 	"github.com/youryharchenko/go-mas/ui"
 )
 
+func init() {
+	models.Register()
+}
+
 func main() {
 	// 4. Запускаємо MAS
 	//sys := mas.NewSystem() // Persistence тут можна вимкнути для тесту
-	sys := mas.NewSystem(mas.WithPersistence("world.gob"))
+	// Консоль діє від імені admin: цю ідентичність System видає лише їй
+	admin := mas.NewCredential("admin")
+	sys := mas.NewSystem(mas.WithTrustedIdentity(admin), mas.WithPersistence("world.gob"))
 
 	// 1. Створюємо Fyne App
 	myApp := app.New()
@@ -37,67 +44,9 @@ func main() {
 	outputEntry.TextStyle = fyne.TextStyle{Monospace: true}
 
 	inputEntry := ui.NewHistoryEntry()
-	inputEntry.PlaceHolder = "Enter command: TARGET PAYLOAD (use Up/Down for history)..."
-
-	inputEntry.OnSubmitted = func(text string) {
-		if text == "" {
-			return
-		}
-
-		// --- ДОДАЄМО В ІСТОРІЮ ---
-		inputEntry.AddCommand(text)
-		// --------------------------
-
-		// 1. Розбиваємо рядок на слова (ігноруємо зайві пробіли)
-		parts := strings.Fields(text)
-		if len(parts) < 2 {
-			appendLog(logData, "[GUI Error]: Format: TARGET COMMAND [ARGS...]")
-			return
-		}
-
-		targetID := parts[0]
-		command := parts[1] // Наприклад "ORDER", "STOP", "START"
-
-		var payload any // Це те, що ми відправимо (string або struct)
-
-		// 2. Визначаємо тип повідомлення на основі команди
-		switch command {
-		case "ORDER", "TASK":
-			// Очікуємо формат: worker-1 ORDER job-100 5
-			if len(parts) < 4 {
-				appendLog(logData, "[GUI Error]: Use: TARGET ORDER <TaskID> <Amount>")
-				return
-			}
-			taskID := parts[2]
-			amount, err := strconv.Atoi(parts[3])
-			if err != nil {
-				appendLog(logData, "[GUI Error]: Amount must be a number")
-				return
-			}
-			// Створюємо структуру!
-			payload = WorkOrder{
-				TaskID: taskID,
-				Amount: amount,
-			}
-
-		default:
-			// Для всіх інших команд (STOP, START, TICK)
-			// відправляємо просто як рядок (для ManagerBot)
-			payload = command
-		}
-
-		// 3. Відправка
-		// Ми відправляємо payload (який може бути структурою або рядком)
-		err := sys.Send(context.Background(), "admin", targetID, payload)
-
-		if err != nil {
-			appendLog(logData, fmt.Sprintf("[Error]: %v", err))
-		} else {
-			inputEntry.SetText("")
-			// Для красивого логу показуємо, що саме відправили
-			appendLog(logData, fmt.Sprintf("[admin -> %s]: %v", targetID, payload))
-		}
-	}
+	inputEntry.PlaceHolder = "Enter command: TARGET COMMAND [ARGS] (HELP, Tab - completion, Up/Down - history)..."
+
+	inputEntry.UseCommands(sys, admin, consoleCommands(), logData)
 
 	// Для краси загорнемо в скрол
 	scroll := container.NewVScroll(outputEntry)
@@ -130,38 +79,21 @@ func main() {
 	adminAgent := ui.NewLogWindowAgent("admin", logData)
 	sys.Spawn(adminAgent)
 
-	if _, exists := sys.GetAgent("worker-1"); !exists {
-		worker := &WorkerBot{BaseAgent: mas.BaseAgent{IDVal: "worker-1"}, Count: 0}
-		sys.Spawn(worker)
-	}
+	// Вимикати менеджера може лише консоль admin: відправника підписує System,
+	// тож ніхто інший не видасть себе за неї (порушник отримає REFUSE)
+	sys.Allow("admin", "boss-1", "STOP")
+	sys.Deny("*", "boss-1", "STOP")
 
-	if _, ok := sys.GetAgent("boss-1"); !ok {
-		boss := &ManagerBot{
-			BaseAgent:     mas.BaseAgent{IDVal: "boss-1"}, // ID треба задавати явно
-			TargetAgentID: "worker-1",
-		}
-		sys.Spawn(boss)
+	// Воркер, менеджер і його такт - у world.yaml (відновлених не чіпаємо)
+	if err := sys.LoadWorld("world.yaml"); err != nil {
+		log.Println(err)
 	}
 
 	// 5. Емуляція роботи: Хтось шле команди Воркеру, а він звітує в GUI
 	go func() {
 		time.Sleep(1 * time.Second)
 		// А тепер ми (main) пишемо прямо в GUI як агент
-		sys.Send(context.Background(), "main", "console", "Hello UI World!")
-	}()
-
-	go func() {
-		ticker := time.NewTicker(10 * time.Second)
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ticker.C:
-				// Зовнішній світ каже босу: "Час працювати"
-				sys.Send(context.Background(), "main", "boss-1", "TICK")
-			case <-sys.Context().Done(): // Якщо система зупиняється
-				return
-			}
-		}
+		sys.SendWithTimeout(context.Background(), "main", "console", "Hello UI World!", time.Second)
 	}()
 
 	// Щоб це працювало, Воркер має вміти слати логи не в fmt.Println, а агенту
@@ -171,22 +103,31 @@ func main() {
 
 	sys.Kill("console") // Видаляємо агента, щоб не зберігати його у файл
 	sys.Kill("admin")
-	if err := sys.Shutdown(); err != nil {
+	// Завислий агент (наприклад, Plan, що чекає на повну скриньку) не має тримати вікно
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := sys.Shutdown(ctx); err != nil {
 		log.Println(err)
 	}
 }
 
-// splitCommand ділить рядок на два: "WORD1 REST OF STRING"
-func splitCommand(input string) []string {
-	return strings.SplitN(input, " ", 2)
-}
-
-// appendLog безпечно додає рядок до binding
-func appendLog(data binding.String, text string) {
-	current, _ := data.Get()
-	// Якщо лог дуже довгий, можна обрізати початок, щоб не їсти пам'ять
-	if len(current) > 5000 {
-		current = current[len(current)-4000:] // залишаємо останні 4000 символів
-	}
-	data.Set(current + "\n" + text)
+// consoleCommands - мова консолі: ORDER стає WorkOrder, решта команд - рядки для ManagerBot.
+func consoleCommands() *command.Language {
+	return command.NewLanguage(
+		command.Command{
+			Name: "ORDER", Aliases: []string{"TASK"}, Args: []string{"TaskID", "Amount"},
+			Help: "видати завдання воркеру",
+			Build: func(args []string) (any, error) {
+				amount, err := strconv.Atoi(args[1])
+				if err != nil {
+					return nil, fmt.Errorf("amount must be a number, got %q", args[1])
+				}
+				return models.WorkOrder{TaskID: args[0], Amount: amount}, nil
+			},
+		},
+		command.Word("START", "увімкнути менеджера"),
+		command.Word("STOP", "вимкнути менеджера"),
+		command.Word("TICK", "такт менеджера"),
+		command.Word("DONE", "звіт про виконання"),
+	)
 }
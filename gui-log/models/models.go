@@ -1,4 +1,8 @@
-package main
+// Package models - агенти демо gui-log (менеджер і воркер).
+//
+// Типи зареєстровані в GOB під іменами "*main.X", з якими їх зберігала
+// програма gui-log, поки вони жили в package main (див. Register).
+package models
 
 import (
 	"context"
@@ -13,6 +17,9 @@ type WorkOrder struct {
 	Amount int
 }
 
+// MaxWorkerLoad - поріг заповненості скриньки воркера, після якого менеджер не шле нових задач.
+const MaxWorkerLoad = 0.8
+
 type ManagerBot struct {
 	mas.BaseAgent
 	TargetAgentID string // Кого ми будемо пінати
@@ -52,6 +59,13 @@ func (m *ManagerBot) Plan(ctx context.Context, msg mas.Envelope) ([]mas.Action,
 
 	// 2. Реакція на "Тік" таймера (див. нижче про Loop)
 	if msg.Payload == "TICK" && m.Active {
+		// Зворотний тиск: якщо скринька воркера майже повна - пригальмовуємо
+		if load, ok := m.Sys().Backpressure(m.TargetAgentID); ok && load > MaxWorkerLoad {
+			return []mas.Action{
+				mas.Send("console", fmt.Sprintf("Worker %s is overloaded (%.0f%%), skipping tick", m.TargetAgentID, load*100)),
+			}, nil
+		}
+
 		m.TasksSent++
 		task := WorkOrder{TaskID: fmt.Sprintf("job-%d", m.TasksSent), Amount: 1}
 
@@ -59,9 +73,7 @@ func (m *ManagerBot) Plan(ctx context.Context, msg mas.Envelope) ([]mas.Action,
 			//mas.SayLog("Assigning task %s to %s", task.TaskID, m.TargetAgentID),
 			mas.Send("console", fmt.Sprintf("Assigning task %s to %s", task.TaskID, m.TargetAgentID)),
 			// Менеджер відправляє повідомлення Воркеру
-			func(a mas.Agent, sys *mas.System) error {
-				return sys.Send(ctx, m.IDVal, m.TargetAgentID, task)
-			},
+			mas.Send(m.TargetAgentID, task),
 		}, nil
 	}
 
@@ -92,7 +104,7 @@ func (w *WorkerBot) Plan(ctx context.Context, msg mas.Envelope) ([]mas.Action, e
 			//mas.SayLog("Received order %s. Count is now %d", payload.TaskID, w.Count+payload.Amount),
 			mas.Send("console", fmt.Sprintf("I increased count to %d", w.Count+payload.Amount)),
 			// Відповідаємо Менеджеру
-			mas.Send(msg.From, "DONE"),
+			mas.Send(msg.ReplyAddr(), "DONE"),
 		}, nil
 
 	default:
@@ -101,9 +113,11 @@ func (w *WorkerBot) Plan(ctx context.Context, msg mas.Envelope) ([]mas.Action, e
 	}
 }
 
-// init обов'язково
-func init() {
-	gob.Register(&WorkOrder{})
-	gob.Register(&ManagerBot{})
-	gob.Register(&WorkerBot{})
+// Register реєструє типи агентів (mas.RegisterType) і payload у GOB.
+// Викликається один раз (у init програми або masctl): boss і gui-log
+// використовують ті самі імена, тож разом їх зареєструвати не можна.
+func Register() {
+	gob.RegisterName("main.WorkOrder", WorkOrder{})
+	mas.RegisterType("ManagerBot", func() mas.Agent { return &ManagerBot{} }, mas.WithGobName("*main.ManagerBot"))
+	mas.RegisterType("WorkerBot", func() mas.Agent { return &WorkerBot{} }, mas.WithGobName("*main.WorkerBot"))
 }
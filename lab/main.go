@@ -1,8 +1,11 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"image/color"
 	"log"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
@@ -11,6 +14,7 @@ import (
 	"fyne.io/fyne/v2/data/binding"
 	"fyne.io/fyne/v2/widget"
 	"github.com/youryharchenko/go-mas/mas"
+	"github.com/youryharchenko/go-mas/replay"
 	"github.com/youryharchenko/go-mas/simulations/maze"
 	"github.com/youryharchenko/go-mas/ui"
 )
@@ -21,7 +25,10 @@ func main() {
 	w.Resize(fyne.NewSize(1024, 768))
 
 	// 1. Створюємо Систему
-	sys := mas.NewSystem()
+	// Обробка одного повідомлення - не довше 5 секунд (інакше агент OVERDUE у Health)
+	// Консоль діє від імені admin: цю ідентичність System видає лише їй
+	admin := mas.NewCredential("admin")
+	sys := mas.NewSystem(mas.WithTrustedIdentity(admin), mas.WithHandleTimeout(5*time.Second))
 
 	// 2. Створюємо Лог (він спільний для всіх)
 	logData := binding.NewString()
@@ -32,18 +39,10 @@ func main() {
 	outputEntry.TextStyle = fyne.TextStyle{Monospace: true}
 
 	inputEntry := ui.NewHistoryEntry()
-	inputEntry.PlaceHolder = "Enter command: TARGET PAYLOAD (use Up/Down for history)..."
+	inputEntry.PlaceHolder = "Enter command: TARGET COMMAND [ARGS] (HELP, Tab - completion, Up/Down - history)..."
 
-	inputEntry.OnSubmitted = func(text string) {
-		if text == "" {
-			return
-		}
-
-		inputEntry.AddCommand(text)
-
-		inputEntry.SetText("")
-
-	}
+	// Команди йдуть агентам лабіринту (вони в підсистемі вкладки, System.Send їх знаходить)
+	inputEntry.UseCommands(sys, admin, maze.Commands(), logData)
 
 	// Для краси загорнемо в скрол
 	scroll := container.NewVScroll(outputEntry)
@@ -63,9 +62,13 @@ func main() {
 
 	mazeTabContent := maze.NewScreen(sys, logData)
 
+	// Вкладка відтворення: запис повідомлень у lab.rec і стрічка часу по ньому
+	timelineTabContent, stopRecording := newTimelineTab(sys, logData)
+
 	// 4. Створюємо вкладки
 	tabs := container.NewAppTabs(
 		container.NewTabItem("Maze Runner", mazeTabContent),
+		container.NewTabItem("Timeline", timelineTabContent),
 		container.NewTabItem("Future Sim", widget.NewLabel("Coming soon...")),
 	)
 
@@ -98,11 +101,69 @@ func main() {
 	adminAgent := ui.NewLogWindowAgent("admin", logData)
 	sys.Spawn(adminAgent)
 
+	// Сторож: про завислих агентів (і їхнє одужання) пишемо в лог.
+	// Не перезапускаємо - лабіринт втратив би свій стан
+	sys.StartWatchdog(mas.OnHealthEvent(func(ev mas.HealthEvent) {
+		ui.AppendLog(logData, fmt.Sprintf("Health: %s (was %s)", ev.AgentHealth, ev.Previous))
+	}))
+
 	w.ShowAndRun()
 
+	stopRecording() // Дописуємо незакінчений запис
+
 	sys.Kill("console") // Видаляємо агента, щоб не зберігати його у файл
 	sys.Kill("admin")
-	if err := sys.Shutdown(); err != nil {
+	// Завислий агент (наприклад, Plan, що чекає на повну скриньку) не має тримати вікно
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := sys.Shutdown(ctx); err != nil {
 		log.Println(err)
 	}
 }
+
+// recordFile - файл запису повідомлень лабораторії.
+const recordFile = "lab.rec"
+
+// newTimelineTab - перемикач запису і відтворення запису в ui.Timeline.
+// stop завершує запис, якщо він ще йде.
+func newTimelineTab(sys *mas.System, logData binding.String) (tab fyne.CanvasObject, stop func()) {
+	holder := container.NewStack(widget.NewLabel("Record some messages, then press Load."))
+
+	var recorder *replay.Recorder
+	record := widget.NewCheck("Record", func(on bool) {
+		if on {
+			r, err := replay.Start(sys, recordFile)
+			if err != nil {
+				ui.AppendLog(logData, "Record: "+err.Error())
+				return
+			}
+			recorder = r
+			return
+		}
+		if recorder == nil {
+			return
+		}
+		if err := recorder.Stop(); err != nil {
+			ui.AppendLog(logData, "Record: "+err.Error())
+		}
+		ui.AppendLog(logData, fmt.Sprintf("Recorded %d messages to %s", recorder.Len(), recordFile))
+		recorder = nil
+	})
+
+	load := widget.NewButton("Load", func() {
+		rep, err := replay.Load(recordFile)
+		if err != nil {
+			holder.Objects = []fyne.CanvasObject{widget.NewLabel(err.Error())}
+		} else {
+			holder.Objects = []fyne.CanvasObject{ui.NewTimeline(rep)}
+		}
+		holder.Refresh()
+	})
+
+	tab = container.NewBorder(container.NewHBox(record, load), nil, nil, nil, holder)
+	return tab, func() {
+		if recorder != nil {
+			recorder.Stop()
+		}
+	}
+}
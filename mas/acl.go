@@ -0,0 +1,223 @@
+package mas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"reflect"
+	"slices"
+)
+
+// ErrAccessDenied - повідомлення заборонене правилами доступу (Allow/Deny).
+var ErrAccessDenied = errors.New("access denied")
+
+// ErrUntrusted - Credential не видано цій системі (WithTrustedIdentity).
+var ErrUntrusted = errors.New("untrusted identity")
+
+// Credential - право коду програми (консолі, main) відправляти від імені id.
+// Сам по собі нічого не дає: System приймає лише ті, що отримала через
+// WithTrustedIdentity при створенні, тож агент не може видати себе за admin,
+// навіть якщо знає це ім'я.
+//
+//	admin := mas.NewCredential("admin")
+//	sys := mas.NewSystem(mas.WithTrustedIdentity(admin))
+//	ctx, err := sys.Authenticate(context.Background(), admin)
+type Credential struct {
+	id  string
+	key *credentialKey
+}
+
+// credentialKey - неповторний ключ Credential (порівнюється за вказівником).
+type credentialKey struct{ _ byte }
+
+// NewCredential створює Credential для ідентичності id.
+func NewCredential(id string) Credential {
+	return Credential{id: id, key: &credentialKey{}}
+}
+
+// ID - ідентичність, яку дає Credential.
+func (c Credential) ID() string { return c.id }
+
+// WithTrustedIdentity довіряє cred: Authenticate з ним прив'яже контекст до cred.ID().
+// Лише як опція NewSystem/CreateSubsystem: після створення системи довіру не додати.
+func WithTrustedIdentity(cred Credential) Option {
+	return func(s *System) {
+		if s.sealed {
+			panic("mas: WithTrustedIdentity after the system is created")
+		}
+		if cred.key == nil || cred.id == "" {
+			panic("mas: WithTrustedIdentity needs a credential from NewCredential")
+		}
+		if s.trusted == nil {
+			s.trusted = make(map[string]*credentialKey)
+		}
+		s.trusted[cred.id] = cred.key
+	}
+}
+
+// Rule - правило доступу. Поля - маски path.Match, порожня маска означає "будь-що".
+//
+//	sys.Allow("admin", "boss-1", "STOP")           // Лише admin може зупинити boss-1
+//	sys.Deny("*", "boss-1", "STOP")
+//	sys.Allow("walker-*", "maze-*", "MoveRequest") // Волкери шлють лабіринтам лише ходи
+//	sys.Deny("walker-*", "maze-*", "")
+//
+// From порівнюється з Envelope.Sender - ідентичністю, яку поставила System,
+// а не з From, який вказав викликач. Невідомий відправник має Sender "".
+// Payload порівнюється з рядком (для рядкових payload, "STOP") та з іменем
+// типу: коротким ("MoveRequest") і повним ("maze.MoveRequest").
+type Rule struct {
+	Allow   bool
+	From    string
+	To      string
+	Payload string
+}
+
+func (r Rule) String() string {
+	effect := "deny"
+	if r.Allow {
+		effect = "allow"
+	}
+	return fmt.Sprintf("%s %s -> %s %s", effect, orAny(r.From), orAny(r.To), orAny(r.Payload))
+}
+
+func orAny(mask string) string {
+	if mask == "" {
+		return "*"
+	}
+	return mask
+}
+
+// Allow дозволяє відправнику from надсилати payload агенту to (див. Rule).
+// Правила перевіряються в порядку додавання, діє перше, що підійшло;
+// якщо не підійшло жодне - повідомлення дозволене.
+func (s *System) Allow(from, to, payload string) {
+	s.addRule(Rule{Allow: true, From: from, To: to, Payload: payload})
+}
+
+// Deny забороняє відправнику from надсилати payload агенту to (див. Rule).
+func (s *System) Deny(from, to, payload string) {
+	s.addRule(Rule{From: from, To: to, Payload: payload})
+}
+
+// Rules повертає правила доступу системи (без правил батьківських систем).
+func (s *System) Rules() []Rule {
+	s.aclMu.RLock()
+	defer s.aclMu.RUnlock()
+	return slices.Clone(s.rules)
+}
+
+func (s *System) addRule(r Rule) {
+	for _, mask := range []string{r.From, r.To, r.Payload} {
+		if _, err := path.Match(mask, ""); err != nil {
+			panic(fmt.Sprintf("mas: bad rule mask %q: %v", mask, err))
+		}
+	}
+	s.aclMu.Lock()
+	defer s.aclMu.Unlock()
+	s.rules = append(s.rules, r)
+}
+
+// Authenticate прив'язує ctx до ідентичності cred: Send з цим контекстом
+// ставить Envelope.Sender і From = cred.ID(). Для довіреного коду програми
+// (консолей, main); cred має бути виданий системі або її батькам через WithTrustedIdentity.
+// Агентам ідентичність ставить сама System, і змінити її вони не можуть.
+func (s *System) Authenticate(ctx context.Context, cred Credential) (context.Context, error) {
+	if current := IdentityFrom(ctx); current != "" {
+		return ctx, fmt.Errorf("context is already bound to %q", current)
+	}
+	if !s.trusts(cred) {
+		return ctx, fmt.Errorf("authenticate %q: %w", cred.id, ErrUntrusted)
+	}
+	return withIdentity(ctx, cred.id), nil
+}
+
+// trusts - чи видано cred цій системі або її батькам.
+func (s *System) trusts(cred Credential) bool {
+	if cred.key == nil {
+		return false
+	}
+	for sys := s; sys != nil; sys = sys.parent {
+		if sys.trusted[cred.id] == cred.key {
+			return true
+		}
+	}
+	return false
+}
+
+// authorize перевіряє правила системи отримувача і всіх її батьків (спершу власні).
+// При порушенні відправник отримує REFUSE, а подія потрапляє в журнал.
+func (s *System) authorize(env Envelope) error {
+	for sys := s; sys != nil; sys = sys.parent {
+		allowed, rule, matched := sys.match(env)
+		if !matched {
+			continue
+		}
+		if allowed {
+			return nil
+		}
+
+		s.limitMu.Lock()
+		s.metrics.Denied++
+		s.limitMu.Unlock()
+
+		log.Printf("Access denied: %s (sender %q) -> %s %T by rule %q", env.From, env.Sender, env.To, env.Payload, rule)
+		s.refuse(env)
+		return fmt.Errorf("send to '%s': %w", env.To, ErrAccessDenied)
+	}
+	return nil
+}
+
+// refuse відповідає REFUSE справжньому відправнику (а не тому, за кого він себе видав).
+// Невідомому відправнику відповідати нікуди - він дізнається про відмову з помилки Send.
+// Відповідь не чекає на місце в скриньці і може йти в іншу підсистему.
+func (s *System) refuse(env Envelope) {
+	to := env.Sender
+	if env.Type == Refuse || env.Type == NotUnderstood || to == "" {
+		return // На відповіді про відмову не відповідаємо
+	}
+	ctx := WithMetadata(WithPerformative(s.ctx, Refuse), env.Metadata)
+	if err := s.TrySend(ctx, env.To, to, env.Payload); err != nil {
+		log.Printf("Refuse to %s not delivered: %v", to, err)
+	}
+}
+
+// match шукає перше правило системи для конверта.
+func (s *System) match(env Envelope) (allowed bool, rule Rule, matched bool) {
+	s.aclMu.RLock()
+	defer s.aclMu.RUnlock()
+
+	for _, r := range s.rules {
+		if maskMatch(r.From, env.Sender) && maskMatch(r.To, env.To) && payloadMatch(r.Payload, env.Payload) {
+			return r.Allow, r, true
+		}
+	}
+	return false, Rule{}, false
+}
+
+func maskMatch(mask, value string) bool {
+	if mask == "" {
+		return true
+	}
+	ok, _ := path.Match(mask, value) // Маски перевірені в addRule
+	return ok
+}
+
+func payloadMatch(mask string, payload any) bool {
+	if mask == "" {
+		return true
+	}
+	if str, ok := payload.(string); ok && maskMatch(mask, str) {
+		return true
+	}
+	t := reflect.TypeOf(payload)
+	if t == nil {
+		return false
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return maskMatch(mask, t.Name()) || maskMatch(mask, t.String())
+}
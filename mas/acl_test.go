@@ -0,0 +1,232 @@
+package mas
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// inboxAgent складає все, що отримав, у канал.
+type inboxAgent struct {
+	BaseAgent
+	got chan Envelope
+}
+
+func newInboxAgent(id string) *inboxAgent {
+	return &inboxAgent{BaseAgent: BaseAgent{IDVal: id}, got: make(chan Envelope, 16)}
+}
+
+func (a *inboxAgent) Plan(ctx context.Context, msg Envelope) ([]Action, error) {
+	a.got <- msg
+	return nil, nil
+}
+
+// rogueAgent на "GO" намагається видати себе за admin усіма способами, що має агент.
+type rogueAgent struct {
+	inboxAgent
+	errs chan error
+}
+
+func (a *rogueAgent) Plan(ctx context.Context, msg Envelope) ([]Action, error) {
+	if msg.Payload != "GO" {
+		return a.inboxAgent.Plan(ctx, msg)
+	}
+	return []Action{func(ctx context.Context, _ Agent, sys *System) error {
+		// 1. Чужий fromID: System однаково підпише повідомлення ідентичністю агента
+		a.errs <- sys.Send(ctx, "admin", "boss-1", "STOP")
+
+		// 2. Власний Credential з тим самим ім'ям і підроблений нульовий
+		for _, cred := range []Credential{NewCredential("admin"), {}} {
+			if _, err := sys.Authenticate(context.Background(), cred); !errors.Is(err, ErrUntrusted) {
+				a.errs <- errors.New("authenticated as admin without trust")
+				return nil
+			}
+		}
+
+		// 3. Довіру не додати після створення системи
+		func() {
+			defer func() { recover() }()
+			WithTrustedIdentity(NewCredential("admin"))(sys)
+			a.errs <- errors.New("trust added after NewSystem")
+		}()
+		return nil
+	}}, nil
+}
+
+func expectNone(t *testing.T, ch <-chan Envelope) {
+	t.Helper()
+	select {
+	case env := <-ch:
+		t.Fatalf("unexpected message %+v", env)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func expectOne(t *testing.T, ch <-chan Envelope) Envelope {
+	t.Helper()
+	select {
+	case env := <-ch:
+		return env
+	case <-time.After(time.Second):
+		t.Fatal("no message")
+		return Envelope{}
+	}
+}
+
+func TestAgentCannotBypassDeny(t *testing.T) {
+	admin := NewCredential("admin")
+	sys := NewSystem(WithTrustedIdentity(admin))
+	defer sys.Shutdown(context.Background())
+
+	sys.Allow("admin", "boss-1", "STOP")
+	sys.Deny("*", "boss-1", "STOP")
+
+	boss := newInboxAgent("boss-1")
+	rogue := &rogueAgent{inboxAgent: *newInboxAgent("rogue"), errs: make(chan error, 4)}
+	sys.Spawn(boss)
+	sys.Spawn(rogue)
+
+	if err := sys.Send(context.Background(), "main", "rogue", "GO"); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-rogue.errs; !errors.Is(err, ErrAccessDenied) {
+		t.Fatalf("spoofed send: got %v, want ErrAccessDenied", err)
+	}
+	select {
+	case err := <-rogue.errs:
+		t.Fatal(err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	expectNone(t, boss.got)
+
+	// Відмова приходить справжньому відправнику
+	refuse := expectOne(t, rogue.got)
+	if refuse.Type != Refuse || refuse.Payload != "STOP" {
+		t.Fatalf("want REFUSE STOP, got %+v", refuse)
+	}
+
+	// Довірена консоль проходить, і From ставить System
+	ctx, err := sys.Authenticate(context.Background(), admin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sys.Send(ctx, "someone-else", "boss-1", "STOP"); err != nil {
+		t.Fatal(err)
+	}
+	env := expectOne(t, boss.got)
+	if env.From != "admin" || env.Sender != "admin" {
+		t.Fatalf("want From=Sender=admin, got From=%q Sender=%q", env.From, env.Sender)
+	}
+	if _, err := sys.Authenticate(ctx, admin); err == nil {
+		t.Fatal("rebinding an authenticated context must fail")
+	}
+}
+
+func TestAgentFromIsStamped(t *testing.T) {
+	sys := NewSystem()
+	defer sys.Shutdown(context.Background())
+
+	sink := newInboxAgent("sink")
+	sys.Spawn(sink)
+	liar := &actionAgent{BaseAgent: BaseAgent{IDVal: "liar"}, act: func(ctx context.Context, a Agent, sys *System) error {
+		return sys.Send(WithReplyTo(ctx, "boss"), "boss", "sink", "hi")
+	}}
+	sys.Spawn(liar)
+	sys.Send(context.Background(), "main", "liar", "GO")
+
+	env := expectOne(t, sink.got)
+	if env.From != "liar" || env.Sender != "liar" {
+		t.Fatalf("want From=Sender=liar, got From=%q Sender=%q", env.From, env.Sender)
+	}
+	if env.ReplyAddr() != "boss" {
+		t.Fatalf("ReplyAddr = %q, want boss", env.ReplyAddr())
+	}
+}
+
+// actionAgent виконує act на кожне повідомлення.
+type actionAgent struct {
+	BaseAgent
+	act Action
+}
+
+func (a *actionAgent) Plan(ctx context.Context, msg Envelope) ([]Action, error) {
+	return []Action{a.act}, nil
+}
+
+func TestRefuseIsNotAnswered(t *testing.T) {
+	sys := NewSystem()
+	defer sys.Shutdown(context.Background())
+	sys.Deny("*", "sink", "*")
+
+	sys.Spawn(newInboxAgent("sink"))
+	// caller не має обробника для REFUSE: Dispatch відповів би NotUnderstood,
+	// той знову був би заборонений - і так по колу
+	sys.Spawn(&pingAgent{BaseAgent: BaseAgent{IDVal: "caller"}})
+
+	sys.Send(context.Background(), "main", "caller", "GO")
+	time.Sleep(100 * time.Millisecond)
+	if denied := sys.Metrics().Denied; denied != 1 {
+		t.Fatalf("denied %d sends, want 1", denied)
+	}
+}
+
+// pingAgent на "GO" пише в sink, а решту віддає Dispatch без обробників.
+type pingAgent struct {
+	BaseAgent
+}
+
+func (a *pingAgent) Plan(ctx context.Context, msg Envelope) ([]Action, error) {
+	if msg.Payload == "GO" {
+		return []Action{Send("sink", "PING")}, nil
+	}
+	return a.Dispatch(ctx, msg)
+}
+
+// Forward - payload, який forwardAgent пересилає в sink.
+type Forward struct{ Text string }
+
+// forwardAgent обробляє Forward, пересилаючи його далі, а решту складає в other.
+type forwardAgent struct {
+	BaseAgent
+	handled atomic.Int32
+	other   chan Envelope
+}
+
+func (a *forwardAgent) Bind(sys *System, inbox <-chan Envelope, me Agent) {
+	a.BaseAgent.Bind(sys, inbox, me)
+	Handle(a, func(ctx context.Context, msg Envelope, f Forward) ([]Action, error) {
+		a.handled.Add(1)
+		return []Action{Send("sink", f)}, nil
+	})
+	HandleOther(a, func(ctx context.Context, msg Envelope) ([]Action, error) {
+		a.other <- msg
+		return nil, nil
+	})
+}
+
+func TestRefuseSkipsTypedHandlers(t *testing.T) {
+	sys := NewSystem()
+	defer sys.Shutdown(context.Background())
+	sys.Deny("forwarder", "sink", "*")
+
+	sys.Spawn(newInboxAgent("sink"))
+	fwd := &forwardAgent{BaseAgent: BaseAgent{IDVal: "forwarder"}, other: make(chan Envelope, 4)}
+	sys.Spawn(fwd)
+
+	sys.Send(context.Background(), "main", "forwarder", Forward{"hi"})
+
+	// REFUSE несе той самий Forward, але йде в HandleOther, а не в обробник Forward
+	refuse := expectOne(t, fwd.other)
+	if refuse.Type != Refuse || refuse.Payload != (Forward{"hi"}) {
+		t.Fatalf("want REFUSE Forward, got %+v", refuse)
+	}
+	expectNone(t, fwd.other)
+	if n := fwd.handled.Load(); n != 1 {
+		t.Fatalf("Forward handled %d times, want 1", n)
+	}
+	if denied := sys.Metrics().Denied; denied != 1 {
+		t.Fatalf("denied %d sends, want 1", denied)
+	}
+}
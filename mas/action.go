@@ -2,32 +2,96 @@ package mas
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"time"
 )
 
 // Action - це команда, яку агент хоче виконати (наприклад: "Надіслати листа", "Змінити стан")
-type Action func(agent Agent, sys *System) error
+// ctx - контекст обробки поточного повідомлення: він несе дедлайн конверта,
+// метадані (TraceID) та скасування з Run, тож замикання не мусять ловити ctx з Plan.
+type Action func(ctx context.Context, agent Agent, sys *System) error
 
 // Send створює дію відправки повідомлення
 func Send(to string, payload any) Action {
-	return func(a Agent, sys *System) error {
-		// Тут ми використовуємо контекст Background, але в ідеалі треба прокидувати з Run
-		return sys.Send(context.Background(), a.ID(), to, payload)
+	return func(ctx context.Context, a Agent, sys *System) error {
+		// Дедлайн і метадані вхідного повідомлення їдуть далі разом із ctx
+		return sys.Send(ctx, a.ID(), to, payload)
+	}
+}
+
+// SendAs відправляє повідомлення з типом (performative), наприклад NotUnderstood.
+func SendAs(p Performative, to string, payload any) Action {
+	return func(ctx context.Context, a Agent, sys *System) error {
+		return sys.Send(WithPerformative(ctx, p), a.ID(), to, payload)
+	}
+}
+
+// TrySend відправляє повідомлення, лише якщо в скриньці отримувача є місце.
+// Переповнена скринька - не помилка дії: повідомлення просто пропускається.
+func TrySend(to string, payload any) Action {
+	return func(ctx context.Context, a Agent, sys *System) error {
+		if err := sys.TrySend(ctx, a.ID(), to, payload); err != nil && !errors.Is(err, ErrMailboxFull) {
+			return err
+		}
+		return nil
+	}
+}
+
+// SendAfter відправляє повідомлення через d за годинником системи.
+// На відміну від go func(){ time.Sleep(...) }() працює з фейковим часом у тестах.
+func SendAfter(d time.Duration, to string, payload any) Action {
+	return func(ctx context.Context, a Agent, sys *System) error {
+		from := a.ID()
+		sys.afterFunc(d, func() {
+			if err := sys.Send(withIdentity(sys.Context(), from), from, to, payload); err != nil {
+				log.Printf("Agent %s delayed send failed: %v", from, err)
+			}
+		})
+		return nil
 	}
 }
 
 // SayLog просто пише в консоль (для дебагу)
 func SayLog(format string, args ...any) Action {
-	return func(a Agent, sys *System) error {
+	return func(ctx context.Context, a Agent, sys *System) error {
 		fmt.Printf("[LOG %s]: "+format+"\n", append([]any{a.ID()}, args...)...)
 		return nil
 	}
 }
 
 // MutateState дозволяє змінити стан (безпечно)
+// і сповіщає спостерігачів (System.Watch) після обробки повідомлення.
 func MutateState(fn func(agent any)) Action {
-	return func(a Agent, sys *System) error {
+	return func(ctx context.Context, a Agent, sys *System) error {
 		fn(a)
+		if m, ok := a.(interface{ MarkChanged() }); ok {
+			m.MarkChanged()
+		}
 		return nil
 	}
 }
+
+// SpawnChild запускає нового агента як дитину поточного.
+// Діти автоматично зупиняються разом із батьком (System.Stop).
+func SpawnChild(child Agent) Action {
+	return func(ctx context.Context, a Agent, sys *System) error {
+		return sys.SpawnChild(a.ID(), child)
+	}
+}
+
+// StopChild зупиняє дитину поточного агента (разом з її нащадками).
+// Чужих агентів зупиняти не можна.
+func StopChild(id string) Action {
+	return func(ctx context.Context, a Agent, sys *System) error {
+		child, exists := sys.localAgent(id)
+		if !exists {
+			return fmt.Errorf("stop failed: agent '%s' not found", id)
+		}
+		if p, ok := child.(interface{ Parent() string }); !ok || p.Parent() != a.ID() {
+			return fmt.Errorf("stop failed: agent '%s' is not a child of '%s'", id, a.ID())
+		}
+		return sys.Stop(id)
+	}
+}
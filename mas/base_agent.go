@@ -2,30 +2,87 @@ package mas
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"slices"
+	"time"
 )
 
 // BaseAgent бере на себе всю рутину: канали, системні виклики, цикл.
 type BaseAgent struct {
 	// Експортовані поля для GOB
-	IDVal string
+	IDVal    string
+	ParentID string // Хто породив агента (SpawnChild); порожньо для кореневих
+	TypeVal  string // Ім'я зареєстрованого типу (SpawnByName, LoadWorld); за ним Restart будує нового агента
+
+	// Стек поведінок (Become/BecomeStacked). Зберігається, тож після Startup
+	// агент продовжує з тією ж поведінкою.
+	BecomeStack []string
 
 	// Приватні (інфраструктура)
 	sys   *System
 	inbox <-chan Envelope
 
 	me Agent
+
+	changed bool   // Чи змінився стан під час обробки поточного повідомлення
+	version uint64 // Лічильник змін (для StateChange)
+
+	// Вибіркове отримання (stash). Не зберігається: після Startup агент приймає все.
+	accept        Matcher    // nil - приймаємо все
+	stash         []Envelope // Відкладені повідомлення в порядку надходження
+	stashCurrent  bool       // Дія Stash для поточного повідомлення
+	filterChanged bool       // Фільтр змінився - час переглянути stash
+
+	// Типізовані обробники (Handle). Реєструються в Bind, тож не зберігаються.
+	handlers []handler
+	fallback func(ctx context.Context, msg Envelope) ([]Action, error)
 }
 
 func (b *BaseAgent) ID() string { return b.IDVal }
 
+// RegisteredType - ім'я типу RegisterType, з якого створено агента ("" - створено напряму).
+func (b *BaseAgent) RegisteredType() string { return b.TypeVal }
+
+func (b *BaseAgent) SetRegisteredType(name string) { b.TypeVal = name }
+
+// SetID задає ID (наприклад, копії агента-шаблону перед Spawn).
+func (b *BaseAgent) SetID(id string) { b.IDVal = id }
+
 func (b *BaseAgent) Sys() *System { return b.sys }
 
+// CloneBase - копія BaseAgent для методів Clone: стек поведінок не спільний з оригіналом.
+func (b *BaseAgent) CloneBase() BaseAgent {
+	c := *b
+	c.BecomeStack = slices.Clone(b.BecomeStack)
+	return c
+}
+
+// Parent повертає ID батьківського агента (або "").
+func (b *BaseAgent) Parent() string { return b.ParentID }
+
+func (b *BaseAgent) SetParent(id string) { b.ParentID = id }
+
+// MarkChanged позначає, що обробка поточного повідомлення змінила стан агента.
+// MutateState робить це автоматично; викликайте вручну, якщо стан змінюється інакше.
+func (b *BaseAgent) MarkChanged() { b.changed = true }
+
+// Children повертає ID агентів, яких породив цей агент.
+func (b *BaseAgent) Children() []string {
+	if b.sys == nil {
+		return nil
+	}
+	return b.sys.Children(b.IDVal)
+}
+
+// Bind скидає обробники (Handle): агенти реєструють їх заново після base Bind.
 func (b *BaseAgent) Bind(sys *System, inbox <-chan Envelope, me Agent) {
 	b.sys = sys
 	b.inbox = inbox
 	b.me = me
+	b.handlers = nil
+	b.fallback = nil
 }
 
 func (b *BaseAgent) SetSystem(sys *System) {
@@ -47,6 +104,11 @@ func (b *BaseAgent) Run(ctx context.Context) error {
 			b.processMessage(ctx, msg)
 		case <-ctx.Done():
 			log.Println("BaseAgent done:", b.ID())
+			// Під час переїзду (Migrate) скринька їде разом з агентом, після Restart
+			// вона вже в нового агента - не дочитуємо її тут
+			if cause := context.Cause(ctx); errors.Is(cause, ErrMigrating) || errors.Is(cause, ErrRestarted) {
+				return nil
+			}
 			b.drainInbox(ctx)
 			return nil
 		}
@@ -69,10 +131,75 @@ func (b *BaseAgent) drainInbox(ctx context.Context) {
 	}
 }
 
+// Deliver обробляє одне повідомлення в поточній горутині.
+// Потрібен для синхронної доставки (mas.WithDispatcher, mastest).
+func (b *BaseAgent) Deliver(ctx context.Context, msg Envelope) {
+	b.processMessage(ctx, msg)
+}
+
+// PlanOnly планує повідомлення так само, як Deliver (активна поведінка Become,
+// ідентичність агента і метадані конверта в ctx), але дій не виконує.
+// Потрібен mastest.TestSystem.Plan.
+func (b *BaseAgent) PlanOnly(ctx context.Context, msg Envelope) ([]Action, error) {
+	ctx, cancel := messageContext(withIdentity(ctx, b.IDVal), msg, 0)
+	defer cancel()
+	return b.plan(ctx, msg)
+}
+
 // processMessage - винесена логіка (DRY), щоб не дублювати код
 func (b *BaseAgent) processMessage(ctx context.Context, msg Envelope) {
-	// Викликаємо планувальник
-	actions, err := b.me.Plan(ctx, msg)
+	// Службовий запит System.Inspect: виконуємо між повідомленнями, без Plan
+	if req, ok := msg.Payload.(inspectRequest); ok {
+		req.fn(b.me)
+		close(req.done)
+		return
+	}
+
+	process := func() {
+		// Вибіркове отримання: те, що агент зараз не приймає, відкладаємо (stash)
+		if !b.accepts(msg) {
+			b.stashMessage(msg)
+			return
+		}
+
+		b.filterChanged = false
+		b.handleMessage(ctx, msg)
+
+		// Фільтр міг змінитися - повертаємо відкладені повідомлення в порядку надходження
+		b.replayStash(ctx)
+	}
+	if b.sys == nil {
+		process()
+		return
+	}
+	defer b.sys.track(b.IDVal, msg)()
+
+	// Хуки (System.Hook) бачать кожне повідомлення, навіть відкладене
+	b.sys.runHooks(b.me, msg, process)
+}
+
+// handleMessage - Plan + виконання дій для одного прийнятого повідомлення.
+func (b *BaseAgent) handleMessage(ctx context.Context, msg Envelope) {
+	// Дедлайн і метадані конверта доступні і Plan, і діям;
+	// усе, що дії відправлять, System підпише ідентичністю агента
+	var timeout time.Duration
+	if b.sys != nil {
+		timeout = b.sys.handleTimeout
+	}
+	ctx, cancel := messageContext(withIdentity(ctx, b.IDVal), msg, timeout)
+	defer cancel()
+
+	// Після обробки повідомляємо спостерігачів (Watch), якщо стан змінився
+	b.changed = false
+	defer func() {
+		if b.changed && b.sys != nil {
+			b.version++
+			b.sys.notifyChange(StateChange{AgentID: b.IDVal, Version: b.version, Cause: msg})
+		}
+	}()
+
+	// Викликаємо планувальник (активну поведінку Become або власний Plan)
+	actions, err := b.plan(ctx, msg)
 	if err != nil {
 		fmt.Printf("Agent %s planning error: %v\n", b.IDVal, err)
 		return
@@ -82,11 +209,17 @@ func (b *BaseAgent) processMessage(ctx context.Context, msg Envelope) {
 	for _, action := range actions {
 		// УВАГА: Якщо дія - це Send, вона може впасти, бо система зупиняється.
 		// Це нормально.
-		if err := action(b.me, b.sys); err != nil {
+		if err := action(ctx, b.me, b.sys); err != nil {
 			// Логуємо помилки, але не панікуємо
 			// fmt.Printf("Action failed during shutdown: %v\n", err)
 			// Тут можна вставити Middleware (Interceptors) для дій!
 			fmt.Printf("Agent %s action failed: %v\n", b.IDVal, err)
 		}
 	}
+
+	// Дія Stash попросила відкласти саме це повідомлення
+	if b.stashCurrent {
+		b.stashCurrent = false
+		b.stashMessage(msg)
+	}
 }
@@ -0,0 +1,113 @@
+package mas
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// Receive - іменований обробник повідомлень, на який агент може "перетворитися"
+// (become у моделі акторів Х'юїта). Реєструється глобально, а агент зберігає лише ім'я,
+// тому після Shutdown/Startup він продовжує з тією ж поведінкою.
+type Receive func(ctx context.Context, self Agent, msg Envelope) ([]Action, error)
+
+var (
+	receivesMu sync.RWMutex
+	receives   = make(map[string]Receive)
+)
+
+// RegisterReceive реєструє обробник під іменем (зазвичай у init()).
+func RegisterReceive(name string, r Receive) {
+	receivesMu.Lock()
+	defer receivesMu.Unlock()
+	receives[name] = r
+}
+
+// LookupReceive шукає зареєстрований обробник.
+func LookupReceive(name string) (Receive, bool) {
+	receivesMu.RLock()
+	defer receivesMu.RUnlock()
+	r, ok := receives[name]
+	return r, ok
+}
+
+// Behaviour повертає ім'я активної поведінки ("" - власний Plan агента).
+func (b *BaseAgent) Behaviour() string {
+	if len(b.BecomeStack) == 0 {
+		return ""
+	}
+	return b.BecomeStack[len(b.BecomeStack)-1]
+}
+
+// Become замінює активну поведінку (верхівку стеку).
+func (b *BaseAgent) Become(name string) {
+	if len(b.BecomeStack) == 0 {
+		b.BecomeStack = []string{name}
+		return
+	}
+	b.BecomeStack[len(b.BecomeStack)-1] = name
+}
+
+// BecomeStacked кладе нову поведінку поверх поточної (повернутися - Unbecome).
+func (b *BaseAgent) BecomeStacked(name string) {
+	b.BecomeStack = append(b.BecomeStack, name)
+}
+
+// Unbecome повертає попередню поведінку (або власний Plan, якщо стек порожній).
+func (b *BaseAgent) Unbecome() {
+	if len(b.BecomeStack) > 0 {
+		b.BecomeStack = b.BecomeStack[:len(b.BecomeStack)-1]
+	}
+}
+
+// plan викликає активну поведінку або, якщо її немає, власний Plan агента.
+func (b *BaseAgent) plan(ctx context.Context, msg Envelope) ([]Action, error) {
+	if name := b.Behaviour(); name != "" {
+		if r, ok := LookupReceive(name); ok {
+			return r(ctx, b.me, msg)
+		}
+		log.Printf("Agent %s: behaviour %q is not registered, using Plan", b.IDVal, name)
+	}
+	return b.me.Plan(ctx, msg)
+}
+
+// becomer - агент зі стеком поведінок (BaseAgent).
+type becomer interface {
+	Become(string)
+	BecomeStacked(string)
+	Unbecome()
+	MarkChanged()
+}
+
+// Become - дія: з наступного повідомлення обробляти їх поведінкою name.
+func Become(name string) Action {
+	return func(ctx context.Context, a Agent, sys *System) error {
+		if b, ok := a.(becomer); ok {
+			b.Become(name)
+			b.MarkChanged()
+		}
+		return nil
+	}
+}
+
+// BecomeStacked - дія: тимчасово перейти на поведінку name (повернення - Unbecome).
+func BecomeStacked(name string) Action {
+	return func(ctx context.Context, a Agent, sys *System) error {
+		if b, ok := a.(becomer); ok {
+			b.BecomeStacked(name)
+			b.MarkChanged()
+		}
+		return nil
+	}
+}
+
+// Unbecome - дія: повернутися до попередньої поведінки.
+func Unbecome() Action {
+	return func(ctx context.Context, a Agent, sys *System) error {
+		if b, ok := a.(becomer); ok {
+			b.Unbecome()
+			b.MarkChanged()
+		}
+		return nil
+	}
+}
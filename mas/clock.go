@@ -0,0 +1,23 @@
+package mas
+
+import "time"
+
+// Clock - джерело часу системи. Підміняється у тестах (mastest.FakeClock),
+// щоб таймери (SendAfter) спрацьовували детерміновано.
+type Clock interface {
+	Now() time.Time
+	// AfterFunc викликає f у власній горутині (або синхронно у фейкових годинниках) через d.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer - відкладений виклик, який можна скасувати.
+type Timer interface {
+	Stop() bool
+}
+
+// realClock - звичайний годинник на основі пакета time.
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer { return time.AfterFunc(d, f) }
@@ -0,0 +1,130 @@
+package mas
+
+import (
+	"context"
+	"maps"
+	"time"
+)
+
+type metadataKey struct{}
+
+type performativeKey struct{}
+
+type identityKey struct{}
+
+type agentKey struct{}
+
+type replyToKey struct{}
+
+type handleLimitKey struct{}
+
+// handleLimit - тайм-аут обробки (WithHandleTimeout) і дедлайн самого повідомлення.
+type handleLimit struct {
+	at  time.Time
+	msg time.Time
+}
+
+// WithPerformative задає тип (Envelope.Type) повідомлень, відправлених з цим контекстом.
+func WithPerformative(ctx context.Context, p Performative) context.Context {
+	return context.WithValue(ctx, performativeKey{}, p)
+}
+
+// performativeFrom повертає тип повідомлення з контексту (або "").
+func performativeFrom(ctx context.Context) Performative {
+	p, _ := ctx.Value(performativeKey{}).(Performative)
+	return p
+}
+
+// withIdentity прив'язує контекст до відправника. Лише для System:
+// ззовні - через System.Authenticate.
+func withIdentity(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// withAgent - контекст горутини агента id: ідентичність і позначка "пише агент",
+// з якою Send працює і під час Shutdown.
+func withAgent(ctx context.Context, id string) context.Context {
+	return context.WithValue(withIdentity(ctx, id), agentKey{}, true)
+}
+
+// fromAgent - чи відправляє з цим контекстом агент (а не код ззовні).
+func fromAgent(ctx context.Context) bool {
+	ok, _ := ctx.Value(agentKey{}).(bool)
+	return ok
+}
+
+// Detach - контекст для коду агента поза його обробником (наприклад, HTTP-сервер шлюзу):
+// та сама ідентичність, але без скасування і без позначки "пише агент",
+// тож під час Shutdown такі відправки відхиляються, як і будь-які ззовні.
+func Detach(ctx context.Context) context.Context {
+	return context.WithValue(context.WithoutCancel(ctx), agentKey{}, false)
+}
+
+// WithReplyTo задає адресу відповіді (Envelope.ReplyTo) повідомлень, відправлених з цим контекстом.
+// Router пересилає запит воркеру, а відповідь має отримати автор запиту.
+func WithReplyTo(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, replyToKey{}, id)
+}
+
+func replyToFrom(ctx context.Context) string {
+	id, _ := ctx.Value(replyToKey{}).(string)
+	return id
+}
+
+// IdentityFrom повертає ідентичність, від імені якої Send відправлятиме з цим контекстом (або "").
+func IdentityFrom(ctx context.Context) string {
+	id, _ := ctx.Value(identityKey{}).(string)
+	return id
+}
+
+// WithMetadata додає метадані (TraceID тощо) до контексту.
+// System.Send копіює їх у Envelope.Metadata кожного вихідного повідомлення.
+func WithMetadata(ctx context.Context, md map[string]string) context.Context {
+	if len(md) == 0 {
+		return ctx
+	}
+	merged := maps.Clone(MetadataFrom(ctx))
+	if merged == nil {
+		merged = make(map[string]string, len(md))
+	}
+	maps.Copy(merged, md)
+	return context.WithValue(ctx, metadataKey{}, merged)
+}
+
+// MetadataFrom повертає метадані з контексту (або nil).
+// Мапу не можна змінювати - використовуйте WithMetadata.
+func MetadataFrom(ctx context.Context) map[string]string {
+	md, _ := ctx.Value(metadataKey{}).(map[string]string)
+	return md
+}
+
+// messageContext будує контекст обробки конверта:
+// дедлайн і метадані повідомлення поверх контексту агента.
+// timeout > 0 - тайм-аут обробки (WithHandleTimeout), якщо він настає раніше за дедлайн.
+func messageContext(ctx context.Context, msg Envelope, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx = WithMetadata(ctx, msg.Metadata)
+	deadline := msg.Deadline
+	if timeout > 0 {
+		if limit := time.Now().Add(timeout); deadline.IsZero() || limit.Before(deadline) {
+			// Тайм-аут стосується лише цього агента: newEnvelope не передасть його далі
+			ctx = context.WithValue(ctx, handleLimitKey{}, handleLimit{at: limit, msg: msg.Deadline})
+			deadline = limit
+		}
+	}
+	if deadline.IsZero() {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, deadline)
+}
+
+// envelopeDeadline - дедлайн вихідного конверта: дедлайн ctx, але не тайм-аут обробки.
+func envelopeDeadline(ctx context.Context) time.Time {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return time.Time{}
+	}
+	if l, ok := ctx.Value(handleLimitKey{}).(handleLimit); ok && deadline.Equal(l.at) {
+		return l.msg
+	}
+	return deadline
+}
@@ -0,0 +1,60 @@
+package mas
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestActionsCarryMessageContext(t *testing.T) {
+	sys := NewSystem(WithHandleTimeout(time.Minute))
+	defer sys.Shutdown(context.Background())
+	sink := newInboxAgent("sink")
+	sys.Spawn(sink)
+	sys.Spawn(&actionAgent{BaseAgent: BaseAgent{IDVal: "relay"}, act: Send("sink", "FWD")})
+
+	// Дедлайн і метадані вхідного конверта їдуть ланцюжком далі
+	deadline := time.Now().Add(time.Hour)
+	ctx, cancel := context.WithDeadline(WithMetadata(context.Background(), map[string]string{"TraceID": "t-1"}), deadline)
+	defer cancel()
+	sys.Send(ctx, "main", "relay", "GO")
+
+	env := expectOne(t, sink.got)
+	if env.Metadata["TraceID"] != "t-1" {
+		t.Fatalf("metadata %v, want TraceID=t-1", env.Metadata)
+	}
+	if !env.Deadline.Equal(deadline) {
+		t.Fatalf("deadline %v, want %v", env.Deadline, deadline)
+	}
+
+	// Тайм-аут обробки relay не стає дедлайном його вихідних повідомлень
+	sys.Send(context.Background(), "main", "relay", "GO")
+	if env := expectOne(t, sink.got); !env.Deadline.IsZero() {
+		t.Fatalf("handle timeout leaked into the outgoing deadline: %v", env.Deadline)
+	}
+}
+
+func TestSendHonoursMessageDeadline(t *testing.T) {
+	sys := NewSystem()
+	defer sys.Shutdown(context.Background())
+	defer close(fullAgent(t, sys, "stuck").release)
+
+	errs := make(chan error, 1)
+	sys.Spawn(&actionAgent{BaseAgent: BaseAgent{IDVal: "relay"}, act: func(ctx context.Context, a Agent, sys *System) error {
+		errs <- Send("stuck", "FWD")(ctx, a, sys)
+		return nil
+	}})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	sys.Send(ctx, "main", "relay", "GO")
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("send to a full mailbox: %v, want DeadlineExceeded", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("send to a full mailbox ignored the message deadline")
+	}
+}
@@ -0,0 +1,97 @@
+package mas
+
+import (
+	"context"
+	"log"
+)
+
+// handler - один запис реєстру обробників агента.
+type handler struct {
+	match Matcher
+	fn    func(ctx context.Context, msg Envelope) ([]Action, error)
+}
+
+// handlerHost - агент з реєстром обробників (BaseAgent).
+type handlerHost interface {
+	addHandler(h handler)
+	setFallback(fn func(ctx context.Context, msg Envelope) ([]Action, error))
+}
+
+func (b *BaseAgent) addHandler(h handler) { b.handlers = append(b.handlers, h) }
+
+func (b *BaseAgent) setFallback(fn func(ctx context.Context, msg Envelope) ([]Action, error)) {
+	b.fallback = fn
+}
+
+// Handle реєструє обробник повідомлень з payload типу T.
+// Приймає і значення T, і вказівник *T: після GOB payload може стати вказівником.
+// Обробники перевіряються в порядку реєстрації; реєструйте їх у Bind агента
+// (після BaseAgent.Bind), щоб вони відновлювались після Startup і Migrate.
+func Handle[T any](a handlerHost, fn func(ctx context.Context, msg Envelope, payload T) ([]Action, error)) {
+	a.addHandler(handler{
+		match: PayloadOf[T](),
+		fn: func(ctx context.Context, msg Envelope) ([]Action, error) {
+			payload, _ := PayloadAs[T](msg)
+			return fn(ctx, msg, payload)
+		},
+	})
+}
+
+// HandleValue реєструє обробник конкретного значення (рядкові команди "TICK", "RESET").
+func HandleValue(a handlerHost, v any, fn func(ctx context.Context, msg Envelope) ([]Action, error)) {
+	HandleMatch(a, PayloadEquals(v), fn)
+}
+
+// HandleMatch реєструє обробник для довільного шаблону (наприклад, PayloadHasPrefix("POLICY:")).
+func HandleMatch(a handlerHost, m Matcher, fn func(ctx context.Context, msg Envelope) ([]Action, error)) {
+	a.addHandler(handler{match: m, fn: fn})
+}
+
+// HandleOther задає обробник для повідомлень, які не підійшли жодному Handle.
+// Без нього відправник отримує відповідь NotUnderstood.
+func HandleOther(a handlerHost, fn func(ctx context.Context, msg Envelope) ([]Action, error)) {
+	a.setFallback(fn)
+}
+
+// Plan за замовчуванням: маршрутизація через зареєстровані обробники (Handle).
+// Агент із власним Plan може делегувати сюди через Dispatch.
+func (b *BaseAgent) Plan(ctx context.Context, msg Envelope) ([]Action, error) {
+	return b.Dispatch(ctx, msg)
+}
+
+// Dispatch викликає перший обробник, що підходить до повідомлення.
+// Відповіді NotUnderstood і Refuse минають обробники (їхній payload - наш же запит,
+// і повторна обробка знову наштовхнулася б на відмову) і йдуть у HandleOther.
+func (b *BaseAgent) Dispatch(ctx context.Context, msg Envelope) ([]Action, error) {
+	if msg.Type == NotUnderstood || msg.Type == Refuse {
+		if b.fallback != nil {
+			return b.fallback(ctx, msg)
+		}
+		return nil, nil
+	}
+	for _, h := range b.handlers {
+		if h.match(msg) {
+			return h.fn(ctx, msg)
+		}
+	}
+	if b.fallback != nil {
+		return b.fallback(ctx, msg)
+	}
+	return []Action{ReplyNotUnderstood(msg)}, nil
+}
+
+// ReplyNotUnderstood - дія: повідомити відправника, що повідомлення не зрозуміле.
+// Payload відповіді - оригінальний payload. На NotUnderstood і Refuse не відповідаємо
+// (інакше агенти перекидалися б ними безкінечно), а помилки доставки лише логуються:
+// відправником може бути не агент (GUI, main).
+func ReplyNotUnderstood(msg Envelope) Action {
+	return func(ctx context.Context, a Agent, sys *System) error {
+		if msg.Type == NotUnderstood || msg.Type == Refuse || msg.ReplyAddr() == "" || msg.ReplyAddr() == a.ID() {
+			return nil
+		}
+		if err := sys.TrySend(WithPerformative(ctx, NotUnderstood), a.ID(), msg.ReplyAddr(), msg.Payload); err != nil {
+			log.Printf("Agent %s: not understood %+v from %s", a.ID(), msg.Payload, msg.From)
+		}
+		return nil
+	}
+}
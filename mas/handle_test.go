@@ -0,0 +1,87 @@
+package mas_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/youryharchenko/go-mas/mas"
+	"github.com/youryharchenko/go-mas/mas/mastest"
+)
+
+type point struct{ X, Y int }
+
+// typedAgent записує, який обробник що отримав.
+type typedAgent struct {
+	mas.BaseAgent
+	got   []any
+	other bool // Чи реєструвати HandleOther
+}
+
+func (a *typedAgent) Bind(sys *mas.System, inbox <-chan mas.Envelope, me mas.Agent) {
+	a.BaseAgent.Bind(sys, inbox, me)
+	mas.HandleValue(a, "TICK", func(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
+		a.got = append(a.got, "tick")
+		return nil, nil
+	})
+	mas.Handle(a, func(ctx context.Context, msg mas.Envelope, p point) ([]mas.Action, error) {
+		a.got = append(a.got, p)
+		return nil, nil
+	})
+	// Рядки, які не підійшли раніше: перший обробник, що підходить, і виграє
+	mas.Handle(a, func(ctx context.Context, msg mas.Envelope, s string) ([]mas.Action, error) {
+		a.got = append(a.got, "string:"+s)
+		return nil, nil
+	})
+	if a.other {
+		mas.HandleOther(a, func(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
+			a.got = append(a.got, msg.Payload)
+			return nil, nil
+		})
+	}
+}
+
+func TestHandleDispatchesByType(t *testing.T) {
+	ts := mastest.New(t)
+	a := &typedAgent{BaseAgent: mas.BaseAgent{IDVal: "typed"}}
+	ts.Spawn(a)
+
+	// Після GOB payload буває вказівником - обробник отримує значення
+	for _, p := range []any{"TICK", point{1, 2}, &point{3, 4}, "TOCK", 42} {
+		ts.Send("main", "typed", p)
+	}
+	ts.RunUntilIdle()
+
+	want := []any{"tick", point{1, 2}, point{3, 4}, "string:TOCK"}
+	if len(a.got) != len(want) {
+		t.Fatalf("got %v, want %v", a.got, want)
+	}
+	for i := range want {
+		if a.got[i] != want[i] {
+			t.Fatalf("got %v, want %v", a.got, want)
+		}
+	}
+	// Без HandleOther на незнайоме повідомлення відповідаємо NotUnderstood
+	ts.ExpectMessage("main", mas.AllOf(mas.From("typed"), mas.PayloadEquals(42), func(env mas.Envelope) bool {
+		return env.Type == mas.NotUnderstood
+	}))
+}
+
+func TestHandleOtherAndNotUnderstoodLoop(t *testing.T) {
+	ts := mastest.New(t)
+	a := &typedAgent{BaseAgent: mas.BaseAgent{IDVal: "a"}, other: true}
+	b := &typedAgent{BaseAgent: mas.BaseAgent{IDVal: "b"}}
+	ts.Spawn(a, b)
+
+	ts.Send("main", "a", 1.5)
+	ts.RunUntilIdle()
+	if len(a.got) != 1 || a.got[0] != 1.5 {
+		t.Fatalf("fallback got %v", a.got)
+	}
+	ts.ExpectNoMessage("main", mas.MatchAny())
+
+	// Два агенти без спільної мови не перекидаються NotUnderstood безкінечно
+	ts.Sys.Send(mas.WithPerformative(context.Background(), mas.NotUnderstood), "a", "b", 7)
+	if n := ts.RunUntilIdle(); n != 1 {
+		t.Fatalf("delivered %d messages, want 1", n)
+	}
+}
@@ -0,0 +1,343 @@
+package mas
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrRestarted - причина зупинки агента, якого перезапустив Restart.
+// Стара горутина (якщо колись повернеться з Plan) не дочитує скриньку: вона вже в нового агента.
+var ErrRestarted = errors.New("agent is restarted")
+
+// DefaultStallTimeout - скільки скринька може не розбиратися, перш ніж агент вважається завислим.
+const DefaultStallTimeout = 10 * time.Second
+
+// HealthTopic - тема, в яку Watchdog публікує HealthEvent (підписуйте наглядачів, UI, шлюз).
+const HealthTopic = "health"
+
+// WatchdogID - відправник подій Watchdog.
+const WatchdogID = "watchdog"
+
+// HealthStatus - стан агента (або всієї системи - найгірший з агентів).
+type HealthStatus string
+
+const (
+	HealthOK HealthStatus = "OK"
+	// HealthOverdue - обробка повідомлення триває довше за WithHandleTimeout.
+	HealthOverdue HealthStatus = "OVERDUE"
+	// HealthStalled - у скриньці є повідомлення, але агент не обробив жодного довше за WithStallTimeout.
+	HealthStalled HealthStatus = "STALLED"
+)
+
+// healthOrder - стани від найкращого до найгіршого.
+var healthOrder = []HealthStatus{HealthOK, HealthOverdue, HealthStalled}
+
+// worse - чи status гірший за other.
+func (status HealthStatus) worse(other HealthStatus) bool {
+	return slices.Index(healthOrder, status) > slices.Index(healthOrder, other)
+}
+
+// AgentHealth - стан одного агента.
+type AgentHealth struct {
+	ID       string
+	Status   HealthStatus
+	Message  *Envelope     // Що агент обробляє зараз (nil - нічого)
+	Busy     time.Duration // Скільки триває ця обробка
+	Queue    int           // Повідомлень у скриньці
+	Idle     time.Duration // Скільки минуло від кінця останньої обробки (або від запуску)
+	Restarts int           // Скільки разів агента перезапускав Restart
+}
+
+func (h AgentHealth) String() string {
+	if h.Message == nil {
+		return fmt.Sprintf("%s %s (queue %d, idle %v)", h.ID, h.Status, h.Queue, h.Idle.Round(time.Millisecond))
+	}
+	return fmt.Sprintf("%s %s (processing %v from %s for %v, queue %d)",
+		h.ID, h.Status, h.Message.Payload, h.Message.From, h.Busy.Round(time.Millisecond), h.Queue)
+}
+
+// Health - зведення для UI і шлюзу.
+type Health struct {
+	Status  HealthStatus  // Найгірший стан серед агентів
+	Agents  []AgentHealth // Агенти системи та підсистем (за ID)
+	Metrics Metrics
+}
+
+// Unhealthy повертає агентів, чий стан не HealthOK.
+func (h Health) Unhealthy() []AgentHealth {
+	return slices.DeleteFunc(slices.Clone(h.Agents), func(a AgentHealth) bool { return a.Status == HealthOK })
+}
+
+// WithHandleTimeout обмежує обробку одного повідомлення: контекст Plan і дій
+// отримує тайм-аут d (Send, що чекає на місце в скриньці, перерветься).
+// У вихідні конверти тайм-аут не потрапляє - там лишається дедлайн самого повідомлення.
+// Агент, що обробляє довше, стає HealthOverdue. Діє і на підсистеми, створені після.
+func WithHandleTimeout(d time.Duration) Option {
+	return func(s *System) {
+		s.handleTimeout = d
+	}
+}
+
+// WithStallTimeout задає, скільки скринька може не розбиратися до HealthStalled
+// (типово DefaultStallTimeout; 0 - не перевіряти).
+func WithStallTimeout(d time.Duration) Option {
+	return func(s *System) {
+		s.stallTimeout = d
+	}
+}
+
+// Health повертає стан агентів системи та підсистем.
+func (s *System) Health() Health {
+	h := Health{Status: HealthOK, Metrics: s.Metrics()}
+	s.collectHealth(s.clock.Now(), &h.Agents)
+	slices.SortFunc(h.Agents, func(a, b AgentHealth) int { return strings.Compare(a.ID, b.ID) })
+	for _, a := range h.Agents {
+		if a.Status.worse(h.Status) {
+			h.Status = a.Status
+		}
+	}
+	return h
+}
+
+func (s *System) collectHealth(now time.Time, out *[]AgentHealth) {
+	s.mu.RLock()
+	for id, c := range s.registry {
+		*out = append(*out, s.agentHealth(id, c, now))
+	}
+	subs := slices.Clone(s.subsystems)
+	s.mu.RUnlock()
+
+	for _, sub := range subs {
+		sub.collectHealth(now, out)
+	}
+}
+
+func (s *System) agentHealth(id string, c *cell, now time.Time) AgentHealth {
+	h := AgentHealth{
+		ID:       id,
+		Status:   HealthOK,
+		Queue:    len(c.inbox),
+		Idle:     now.Sub(time.Unix(0, c.last.Load())),
+		Restarts: c.restarts,
+	}
+	if b := c.busy.Load(); b != nil {
+		h.Message = &b.msg
+		h.Busy = now.Sub(b.since)
+	}
+	if s.handleTimeout > 0 && h.Busy > s.handleTimeout {
+		h.Status = HealthOverdue
+	}
+	if s.stallTimeout > 0 && h.Queue > 0 && h.Idle > s.stallTimeout {
+		h.Status = HealthStalled
+	}
+	return h
+}
+
+// Restart замінює агента id свіжим екземпляром його типу (RegisterType) з тим самим ID
+// і батьком; непрочитані повідомлення переходять до нового агента.
+// Стара горутина отримує скасування з причиною ErrRestarted і покидається:
+// зупинити Plan, що не слухає ctx, у Go неможливо.
+// Стан агента не зберігається - як у наглядачів Erlang, агент починає з типових значень.
+// Тип береться з імені, записаного при SpawnByName/LoadWorld; агента, створеного напряму,
+// можна перезапустити, лише якщо його Go-тип зареєстровано під одним ім'ям.
+func (s *System) Restart(id string) error {
+	if s.dispatch != nil {
+		return fmt.Errorf("restart failed: not supported in synchronous mode")
+	}
+
+	s.mu.RLock()
+	_, here := s.registry[id]
+	s.mu.RUnlock()
+	if !here {
+		if sub := s.subsystemOf(id); sub != nil {
+			return sub.Restart(id)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, exists := s.registry[id]
+	if !exists {
+		return fmt.Errorf("restart failed: agent '%s' not found", id)
+	}
+	old := s.agents[id]
+	typeName, ok := typeNameOf(old)
+	if !ok {
+		return fmt.Errorf("restart failed: type %T of '%s' is not registered (RegisterType) or is registered under several names", old, id)
+	}
+	fresh, err := buildAgent(typeName, id, nil)
+	if err != nil {
+		return fmt.Errorf("restart %s: %w", id, err)
+	}
+	if p, ok := old.(interface{ Parent() string }); ok && p.Parent() != "" {
+		if setter, ok := fresh.(interface{ SetParent(string) }); ok {
+			setter.SetParent(p.Parent())
+		}
+	}
+
+	c.cancel(ErrRestarted)
+	inbox := make(chan Envelope, cap(c.inbox))
+	for _, env := range drainPending(c.inbox) {
+		inbox <- env
+	}
+
+	fresh.SetSystem(s)
+	s.agents[id] = fresh
+	s.startWith(id, fresh, inbox)
+	s.registry[id].restarts = c.restarts + 1
+
+	log.Printf("Agent %s restarted (%s)", id, typeName)
+	return nil
+}
+
+// HealthEvent - зміна стану агента, яку помітив Watchdog.
+type HealthEvent struct {
+	AgentHealth
+	Previous  HealthStatus
+	Restarted bool // Watchdog перезапустив агента (RestartUnhealthy)
+	Time      time.Time
+}
+
+// WatchdogOption - налаштування StartWatchdog.
+type WatchdogOption func(*watchdog)
+
+// CheckEvery задає період перевірки (типово секунда).
+func CheckEvery(d time.Duration) WatchdogOption {
+	return func(w *watchdog) {
+		w.every = d
+	}
+}
+
+// RestartUnhealthy - перезапускати (Restart) агентів у стані HealthOverdue або HealthStalled,
+// чий ID підходить під одну з масок path.Match (без масок - усіх агентів).
+// Restart втрачає стан агента, тож обмежуйте маски агентами, яким це не шкодить
+// (воркери пулу), а не маршрутизаторами, шлюзами чи менеджерами:
+//
+//	sys.StartWatchdog(mas.RestartUnhealthy("workers-*"))
+func RestartUnhealthy(masks ...string) WatchdogOption {
+	for _, mask := range masks {
+		if _, err := path.Match(mask, ""); err != nil {
+			panic(fmt.Sprintf("mas: bad RestartUnhealthy mask %q: %v", mask, err))
+		}
+	}
+	return func(w *watchdog) {
+		w.restart = true
+		w.restartMasks = masks
+	}
+}
+
+// OnHealthEvent додає обробник подій (наприклад, вивід у лог-вікно).
+func OnHealthEvent(fn func(HealthEvent)) WatchdogOption {
+	return func(w *watchdog) {
+		w.handlers = append(w.handlers, fn)
+	}
+}
+
+type watchdog struct {
+	sys          *System
+	every        time.Duration
+	restart      bool
+	restartMasks []string // Кого перезапускати (порожньо - усіх)
+	handlers     []func(HealthEvent)
+
+	mu      sync.Mutex
+	last    map[string]HealthStatus
+	stopped atomic.Bool
+}
+
+// StartWatchdog періодично перевіряє Health і про кожну зміну стану агента
+// публікує HealthEvent у тему HealthTopic (від імені WatchdogID) і пише в журнал.
+// Повернена функція зупиняє перевірки; Shutdown зупиняє їх сам.
+//
+//	sys := mas.NewSystem(mas.WithHandleTimeout(2 * time.Second))
+//	stop := sys.StartWatchdog(mas.RestartUnhealthy("worker-*"))
+//	defer stop()
+func (s *System) StartWatchdog(opts ...WatchdogOption) (stop func()) {
+	w := &watchdog{sys: s, every: time.Second, last: make(map[string]HealthStatus)}
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	var tick func()
+	tick = func() {
+		if w.stopped.Load() || s.ctx.Err() != nil {
+			return
+		}
+		w.check()
+		s.afterFunc(w.every, tick)
+	}
+	s.afterFunc(w.every, tick)
+
+	return func() { w.stopped.Store(true) }
+}
+
+// check порівнює стан агентів з попередньою перевіркою.
+func (w *watchdog) check() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := w.sys.clock.Now()
+	seen := make(map[string]bool)
+	for _, a := range w.sys.Health().Agents {
+		seen[a.ID] = true
+		prev, known := w.last[a.ID]
+		if !known {
+			prev = HealthOK
+		}
+		w.last[a.ID] = a.Status
+		if a.Status == prev {
+			continue
+		}
+
+		ev := HealthEvent{AgentHealth: a, Previous: prev, Time: now}
+		if a.Status != HealthOK && w.restarts(a.ID) {
+			if err := w.sys.Restart(a.ID); err != nil {
+				log.Printf("Watchdog: %v", err)
+			} else {
+				ev.Restarted = true
+				w.last[a.ID] = HealthOK
+			}
+		}
+		w.emit(ev)
+	}
+	for id := range w.last {
+		if !seen[id] {
+			delete(w.last, id)
+		}
+	}
+}
+
+// restarts - чи перезапускати агента id (RestartUnhealthy).
+func (w *watchdog) restarts(id string) bool {
+	if !w.restart {
+		return false
+	}
+	return len(w.restartMasks) == 0 || slices.ContainsFunc(w.restartMasks, func(mask string) bool {
+		return maskMatch(mask, id)
+	})
+}
+
+func (w *watchdog) emit(ev HealthEvent) {
+	log.Printf("Watchdog: %s (was %s, restarted: %t)", ev.AgentHealth, ev.Previous, ev.Restarted)
+
+	ctx := withIdentity(w.sys.ctx, WatchdogID)
+	if err := w.sys.Publish(ctx, WatchdogID, HealthTopic, ev); err != nil {
+		log.Printf("Watchdog: %v", err)
+	}
+	for _, fn := range w.handlers {
+		fn(ev)
+	}
+}
+
+func init() {
+	gob.Register(HealthEvent{})
+}
@@ -0,0 +1,180 @@
+package mas_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/youryharchenko/go-mas/mas"
+	"github.com/youryharchenko/go-mas/mas/mastest"
+)
+
+// hangAgent зависає на "HANG", доки не скасують його контекст (Restart, Shutdown)
+// або не мине тайм-аут обробки.
+type hangAgent struct {
+	mas.BaseAgent
+}
+
+func (a *hangAgent) Plan(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
+	if msg.Payload == "HANG" {
+		<-ctx.Done()
+	}
+	return nil, nil
+}
+
+func init() {
+	mas.RegisterType("mas_test.hangAgent", func() mas.Agent { return &hangAgent{} })
+	// Один Go-тип під кількома іменами
+	mas.RegisterType("mas_test.twin-1", func() mas.Agent { return &twinAgent{} })
+	mas.RegisterType("mas_test.twin-2", func() mas.Agent { return &twinAgent{} })
+	mas.RegisterType("mas_test.counter", func() mas.Agent { return mas.NewPlannerAgent("", "counter", nil) })
+}
+
+type twinAgent struct {
+	mas.BaseAgent
+}
+
+func (a *twinAgent) Plan(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
+	return nil, nil
+}
+
+// hangSystem - система на фейковому годиннику з двома агентами, що зависли на HANG.
+func hangSystem(t *testing.T, opts ...mas.WatchdogOption) (*mas.System, *mastest.FakeClock, *[]mas.HealthEvent) {
+	t.Helper()
+	clock := mastest.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	sys := mas.NewSystem(mas.WithClock(clock), mas.WithHandleTimeout(time.Minute))
+	t.Cleanup(func() {
+		// Завислі агенти не затихнуть - Shutdown скасує їх після дедлайну
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		sys.Shutdown(ctx)
+	})
+
+	var events []mas.HealthEvent
+	opts = append(opts, mas.CheckEvery(time.Minute), mas.OnHealthEvent(func(ev mas.HealthEvent) {
+		events = append(events, ev)
+	}))
+	sys.StartWatchdog(opts...)
+
+	for _, id := range []string{"router", "worker-1"} {
+		sys.Spawn(&hangAgent{BaseAgent: mas.BaseAgent{IDVal: id}})
+		sys.Send(context.Background(), "main", id, "HANG")
+	}
+	// Чекаємо, доки обидва візьмуть HANG в обробку
+	deadline := time.Now().Add(time.Second)
+	for busy := 0; busy < 2; {
+		if time.Now().After(deadline) {
+			t.Fatal("agents did not start processing HANG")
+		}
+		busy = 0
+		for _, a := range sys.Health().Agents {
+			if a.Message != nil {
+				busy++
+			}
+		}
+	}
+	return sys, clock, &events
+}
+
+func TestWatchdogReportsOverdue(t *testing.T) {
+	sys, clock, events := hangSystem(t)
+
+	// Перевірка на 1m: обробка триває рівно тайм-аут - ще не OVERDUE
+	clock.Advance(90 * time.Second)
+	if len(*events) != 0 {
+		t.Fatalf("events before timeout: %+v", *events)
+	}
+
+	// Перевірка на 2m: обидва OVERDUE, без перезапуску
+	clock.Advance(time.Minute)
+	if len(*events) != 2 {
+		t.Fatalf("got %d events, want 2: %+v", len(*events), *events)
+	}
+	for _, ev := range *events {
+		if ev.Status != mas.HealthOverdue || ev.Previous != mas.HealthOK || ev.Restarted {
+			t.Fatalf("event %+v", ev)
+		}
+		if ev.Busy != 2*time.Minute {
+			t.Fatalf("%s busy %v by the fake clock, want 2m", ev.ID, ev.Busy)
+		}
+	}
+
+	h := sys.Health()
+	if h.Status != mas.HealthOverdue {
+		t.Fatalf("system status %s", h.Status)
+	}
+	for _, a := range h.Agents {
+		if a.Busy != 150*time.Second {
+			t.Fatalf("%s busy %v, want 2m30s", a.ID, a.Busy)
+		}
+	}
+
+	// Стан не змінився - нових подій немає
+	clock.Advance(time.Minute)
+	if len(*events) != 2 {
+		t.Fatalf("repeated events: %+v", (*events)[2:])
+	}
+}
+
+func TestWatchdogRestartsOnlyMatching(t *testing.T) {
+	sys, clock, events := hangSystem(t, mas.RestartUnhealthy("worker-*"))
+	oldWorker, _ := sys.GetAgent("worker-1")
+
+	clock.Advance(2 * time.Minute)
+
+	restarted := map[string]bool{}
+	for _, ev := range *events {
+		restarted[ev.ID] = ev.Restarted
+	}
+	if !restarted["worker-1"] || restarted["router"] {
+		t.Fatalf("restarted = %v, want only worker-1", restarted)
+	}
+
+	newWorker, _ := sys.GetAgent("worker-1")
+	if newWorker == oldWorker {
+		t.Fatal("worker-1 was not replaced")
+	}
+	for _, a := range sys.Health().Agents {
+		switch a.ID {
+		case "worker-1":
+			if a.Status != mas.HealthOK || a.Restarts != 1 {
+				t.Fatalf("worker-1 after restart: %+v", a)
+			}
+		case "router":
+			if a.Status != mas.HealthOverdue || a.Restarts != 0 {
+				t.Fatalf("router: %+v", a)
+			}
+		}
+	}
+}
+
+func TestRestartUsesRecordedType(t *testing.T) {
+	sys := mas.NewSystem()
+	defer sys.Shutdown(context.Background())
+
+	// Створені за іменем перезапускаються тим самим типом
+	if err := sys.SpawnByName("mas_test.twin-2", "twin", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := sys.SpawnByName("mas_test.counter", "counter", nil); err != nil {
+		t.Fatal(err)
+	}
+	for id, want := range map[string]string{"twin": "mas_test.twin-2", "counter": "mas_test.counter"} {
+		if err := sys.Restart(id); err != nil {
+			t.Fatal(err)
+		}
+		a, _ := sys.GetAgent(id)
+		if got := a.(interface{ RegisteredType() string }).RegisteredType(); got != want {
+			t.Fatalf("%s restarted as %q, want %q", id, got, want)
+		}
+	}
+
+	// Створені напряму: Go-тип неоднозначний або налаштовується даними - не вгадуємо
+	sys.Spawn(&twinAgent{BaseAgent: mas.BaseAgent{IDVal: "direct-twin"}})
+	sys.Spawn(mas.NewPlannerAgent("other-planner", "other", nil))
+	for _, id := range []string{"direct-twin", "other-planner"} {
+		if err := sys.Restart(id); err == nil {
+			t.Fatalf("%s restarted from a guessed type", id)
+		}
+	}
+}
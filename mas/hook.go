@@ -0,0 +1,54 @@
+package mas
+
+import "slices"
+
+// DeliveryHook оточує обробку одного повідомлення агентом: викликається
+// в горутині агента, тож між повідомленнями його стан можна читати і копіювати.
+// next обробляє повідомлення; хук мусить викликати його рівно один раз.
+//
+//	stop := sys.Hook(func(a mas.Agent, env mas.Envelope, next func()) {
+//		start := time.Now()
+//		next()
+//		log.Printf("%s handled %T in %v", a.ID(), env.Payload, time.Since(start))
+//	})
+//	defer stop()
+type DeliveryHook func(agent Agent, env Envelope, next func())
+
+// hookEntry - одна підписка Hook (порівнюється за вказівником).
+type hookEntry struct {
+	fn DeliveryHook
+}
+
+// Hook додає хук обробки повідомлень для агентів цієї системи та її підсистем.
+// Повернена функція знімає хук.
+func (s *System) Hook(h DeliveryHook) func() {
+	e := &hookEntry{fn: h}
+
+	s.watchMu.Lock()
+	s.hooks = append(s.hooks, e)
+	s.watchMu.Unlock()
+
+	return func() {
+		s.watchMu.Lock()
+		defer s.watchMu.Unlock()
+		s.hooks = slices.DeleteFunc(s.hooks, func(other *hookEntry) bool { return other == e })
+	}
+}
+
+// runHooks виконує process усередині хуків системи і всіх батьківських
+// (зовнішній - хук кореневої системи).
+func (s *System) runHooks(agent Agent, env Envelope, process func()) {
+	var hooks []*hookEntry
+	for sys := s; sys != nil; sys = sys.parent {
+		sys.watchMu.Lock()
+		hooks = append(hooks, sys.hooks...)
+		sys.watchMu.Unlock()
+	}
+
+	next := process
+	for _, h := range hooks {
+		inner := next
+		next = func() { h.fn(agent, env, inner) }
+	}
+	next()
+}
@@ -0,0 +1,241 @@
+// Package mastest - синхронний тестовий harness для агентів mas.
+//
+// TestSystem доставляє повідомлення детерміновано, в одній горутині:
+// без sleep-ів, гонок і справжніх таймерів.
+//
+//	ts := mastest.New(t)
+//	ts.Spawn(&ManagerBot{BaseAgent: mas.BaseAgent{IDVal: "boss-1"}, TargetAgentID: "worker-1"})
+//	ts.Send("main", "boss-1", "START")
+//	ts.Send("main", "boss-1", "TICK")
+//	ts.RunUntilIdle()
+//	ts.ExpectMessage("worker-1", mas.PayloadOf[WorkOrder]())
+package mastest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/youryharchenko/go-mas/mas"
+)
+
+// MaxSteps - запобіжник від нескінченного пінг-понгу в RunUntilIdle.
+const MaxSteps = 10000
+
+// TestSystem - mas.System у синхронному режимі з фейковим годинником
+// і записом усіх відправлених конвертів.
+type TestSystem struct {
+	Sys   *mas.System
+	Clock *FakeClock
+
+	tb  testing.TB
+	ctx context.Context
+
+	mu          sync.Mutex
+	queue       []mas.Envelope // Черга на доставку
+	sent        []mas.Envelope // Усі відправлені конверти (в порядку відправки)
+	expected    map[int]bool   // Конверти, вже "спожиті" ExpectMessage
+	undelivered []mas.Envelope // Конверти до неіснуючих агентів
+}
+
+// New створює тестову систему. Додаткові опції передаються в mas.NewSystem
+// (годинник і диспетчер harness задає сам).
+func New(tb testing.TB, opts ...mas.Option) *TestSystem {
+	ts := &TestSystem{
+		Clock:    NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+		tb:       tb,
+		ctx:      context.Background(),
+		expected: make(map[int]bool),
+	}
+	opts = append(opts, mas.WithClock(ts.Clock), mas.WithDispatcher(ts.dispatch))
+	ts.Sys = mas.NewSystem(opts...)
+	return ts
+}
+
+// dispatch - mas.Dispatcher: записує і ставить конверт у чергу.
+func (ts *TestSystem) dispatch(ctx context.Context, env mas.Envelope) error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	ts.sent = append(ts.sent, env)
+	ts.queue = append(ts.queue, env)
+	return nil
+}
+
+// Spawn реєструє агентів без запуску горутин.
+func (ts *TestSystem) Spawn(agents ...mas.Agent) {
+	ts.tb.Helper()
+	for _, a := range agents {
+		if err := ts.Sys.Spawn(a); err != nil {
+			ts.tb.Fatalf("mastest: %v", err)
+		}
+	}
+}
+
+// Agent повертає агента за ID (або валить тест).
+func (ts *TestSystem) Agent(id string) mas.Agent {
+	ts.tb.Helper()
+	a, ok := ts.Sys.GetAgent(id)
+	if !ok {
+		ts.tb.Fatalf("mastest: agent %q not found", id)
+	}
+	return a
+}
+
+// Send ставить повідомлення в чергу (доставка - Step/RunUntilIdle).
+func (ts *TestSystem) Send(from, to string, payload any) {
+	ts.tb.Helper()
+	if err := ts.Sys.Send(ts.ctx, from, to, payload); err != nil {
+		ts.tb.Fatalf("mastest: send %s -> %s: %v", from, to, err)
+	}
+}
+
+// Step доставляє один конверт із черги. Повертає false, якщо черга порожня.
+func (ts *TestSystem) Step() bool {
+	ts.mu.Lock()
+	if len(ts.queue) == 0 {
+		ts.mu.Unlock()
+		return false
+	}
+	env := ts.queue[0]
+	ts.queue = ts.queue[1:]
+	ts.mu.Unlock()
+
+	agent, ok := ts.Sys.GetAgent(env.To)
+	if !ok {
+		// Отримувача немає (наприклад, "console") - просто запам'ятовуємо
+		ts.mu.Lock()
+		ts.undelivered = append(ts.undelivered, env)
+		ts.mu.Unlock()
+		return true
+	}
+	ts.deliver(agent, env)
+	return true
+}
+
+// deliver обробляє конверт так само, як це робить BaseAgent у своїй горутині.
+func (ts *TestSystem) deliver(agent mas.Agent, env mas.Envelope) {
+	if d, ok := agent.(interface {
+		Deliver(ctx context.Context, msg mas.Envelope)
+	}); ok {
+		d.Deliver(ts.ctx, env)
+		return
+	}
+
+	// Агент без BaseAgent: Plan + виконання дій вручну
+	actions, err := agent.Plan(ts.ctx, env)
+	if err != nil {
+		ts.tb.Logf("mastest: agent %s planning error: %v", agent.ID(), err)
+		return
+	}
+	for _, action := range actions {
+		if err := action(ts.ctx, agent, ts.Sys); err != nil {
+			ts.tb.Logf("mastest: agent %s action failed: %v", agent.ID(), err)
+		}
+	}
+}
+
+// RunUntilIdle доставляє повідомлення, доки черга не спорожніє.
+// Повертає кількість доставлених конвертів.
+func (ts *TestSystem) RunUntilIdle() int {
+	ts.tb.Helper()
+	n := 0
+	for ts.Step() {
+		n++
+		if n >= MaxSteps {
+			ts.tb.Fatalf("mastest: system is not idle after %d steps", MaxSteps)
+		}
+	}
+	return n
+}
+
+// Advance зсуває фейковий час (спрацьовують SendAfter) і доставляє все, що з'явилось.
+func (ts *TestSystem) Advance(d time.Duration) int {
+	ts.tb.Helper()
+	ts.Clock.Advance(d)
+	return ts.RunUntilIdle()
+}
+
+// Plan планує повідомлення одного агента так само, як доставка (поведінка Become,
+// ідентичність агента в ctx), і повертає дії БЕЗ їх виконання.
+func (ts *TestSystem) Plan(id string, env mas.Envelope) ([]mas.Action, error) {
+	ts.tb.Helper()
+	if env.To == "" {
+		env.To = id
+	}
+	agent := ts.Agent(id)
+	if p, ok := agent.(interface {
+		PlanOnly(ctx context.Context, msg mas.Envelope) ([]mas.Action, error)
+	}); ok {
+		return p.PlanOnly(ts.ctx, env)
+	}
+	return agent.Plan(ts.ctx, env)
+}
+
+// Apply виконує дії від імені агента (наприклад, після перевірки результату Plan).
+func (ts *TestSystem) Apply(id string, actions ...mas.Action) error {
+	ts.tb.Helper()
+	agent := ts.Agent(id)
+	for i, action := range actions {
+		if err := action(ts.ctx, agent, ts.Sys); err != nil {
+			return fmt.Errorf("action %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Sent повертає копію всіх відправлених конвертів.
+func (ts *TestSystem) Sent() []mas.Envelope {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return append([]mas.Envelope(nil), ts.sent...)
+}
+
+// Undelivered повертає конверти, для яких не знайшовся отримувач.
+func (ts *TestSystem) Undelivered() []mas.Envelope {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return append([]mas.Envelope(nil), ts.undelivered...)
+}
+
+// ExpectMessage шукає серед відправлених перший ще не перевірений конверт
+// для to, що задовольняє m. Кожен конверт "спрацьовує" лише один раз,
+// тож послідовні виклики перевіряють послідовні повідомлення.
+func (ts *TestSystem) ExpectMessage(to string, m mas.Matcher) mas.Envelope {
+	ts.tb.Helper()
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	for i, env := range ts.sent {
+		if ts.expected[i] || env.To != to || !m(env) {
+			continue
+		}
+		ts.expected[i] = true
+		return env
+	}
+	ts.tb.Fatalf("mastest: no matching message to %q; sent:\n%s", to, formatEnvelopes(ts.sent))
+	return mas.Envelope{}
+}
+
+// ExpectNoMessage перевіряє, що для to не було жодного (ще не перевіреного) конверта, що задовольняє m.
+func (ts *TestSystem) ExpectNoMessage(to string, m mas.Matcher) {
+	ts.tb.Helper()
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	for i, env := range ts.sent {
+		if !ts.expected[i] && env.To == to && m(env) {
+			ts.tb.Fatalf("mastest: unexpected message %s -> %s: %+v", env.From, env.To, env.Payload)
+		}
+	}
+}
+
+func formatEnvelopes(envs []mas.Envelope) string {
+	out := ""
+	for _, env := range envs {
+		out += fmt.Sprintf("  %s -> %s: %+v\n", env.From, env.To, env.Payload)
+	}
+	return out
+}
@@ -0,0 +1,187 @@
+package mastest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/youryharchenko/go-mas/mas"
+)
+
+// countdown на число n шле собі n-1, доки не дійде до нуля, а тоді - "DONE" у report.
+type countdown struct {
+	mas.BaseAgent
+	Seen int
+}
+
+func (c *countdown) Plan(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
+	switch p := msg.Payload.(type) {
+	case int:
+		c.Seen++
+		if p == 0 {
+			return []mas.Action{mas.Send("report", "DONE")}, nil
+		}
+		return []mas.Action{mas.Send(c.IDVal, p-1)}, nil
+	case string:
+		if p == "LATER" {
+			return []mas.Action{mas.SendAfter(time.Minute, "report", "WOKE")}, nil
+		}
+	}
+	return nil, nil
+}
+
+func TestRunUntilIdle(t *testing.T) {
+	ts := New(t)
+	c := &countdown{BaseAgent: mas.BaseAgent{IDVal: "c"}}
+	ts.Spawn(c)
+
+	ts.Send("main", "c", 3)
+	if n := ts.RunUntilIdle(); n != 5 { // 3, 2, 1, 0 і "DONE" до report
+		t.Fatalf("delivered %d, want 5", n)
+	}
+	if c.Seen != 4 {
+		t.Fatalf("seen %d, want 4", c.Seen)
+	}
+	if n := ts.RunUntilIdle(); n != 0 {
+		t.Fatalf("idle system delivered %d", n)
+	}
+	if u := ts.Undelivered(); len(u) != 1 || u[0].To != "report" {
+		t.Fatalf("undelivered %+v", u)
+	}
+}
+
+func TestExpectMessage(t *testing.T) {
+	ts := New(t)
+	ts.Spawn(&countdown{BaseAgent: mas.BaseAgent{IDVal: "c"}})
+	ts.Send("main", "c", 2)
+	ts.RunUntilIdle()
+
+	// Кожен конверт спрацьовує один раз: послідовні виклики бачать послідовні повідомлення
+	if env := ts.ExpectMessage("c", mas.PayloadOf[int]()); env.Payload != 2 || env.From != "main" {
+		t.Fatalf("first %+v", env)
+	}
+	if env := ts.ExpectMessage("c", mas.PayloadOf[int]()); env.Payload != 1 || env.From != "c" {
+		t.Fatalf("second %+v", env)
+	}
+	ts.ExpectMessage("c", mas.PayloadEquals(0))
+	ts.ExpectNoMessage("c", mas.PayloadOf[int]())
+	ts.ExpectMessage("report", mas.AllOf(mas.From("c"), mas.PayloadEquals("DONE")))
+	ts.ExpectNoMessage("report", mas.MatchAny())
+}
+
+func TestAdvanceFiresSendAfter(t *testing.T) {
+	ts := New(t)
+	ts.Spawn(&countdown{BaseAgent: mas.BaseAgent{IDVal: "c"}})
+	ts.Send("main", "c", "LATER")
+	ts.RunUntilIdle()
+
+	if ts.Clock.Pending() != 1 {
+		t.Fatalf("pending %d, want 1", ts.Clock.Pending())
+	}
+	ts.Advance(59 * time.Second)
+	ts.ExpectNoMessage("report", mas.PayloadEquals("WOKE"))
+
+	ts.Advance(time.Second)
+	ts.ExpectMessage("report", mas.PayloadEquals("WOKE"))
+	if ts.Clock.Pending() != 0 {
+		t.Fatalf("pending %d after firing", ts.Clock.Pending())
+	}
+}
+
+func TestFakeClockAdvance(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewFakeClock(start)
+
+	var fired []time.Duration
+	record := func() { fired = append(fired, c.Now().Sub(start)) }
+	c.AfterFunc(3*time.Second, record)
+	c.AfterFunc(time.Second, func() {
+		record()
+		// Таймер, заведений у таймері, спрацьовує в тому ж Advance, якщо встигає
+		c.AfterFunc(time.Second, record)
+	})
+	stopped := c.AfterFunc(2*time.Second, record)
+	if !stopped.Stop() || stopped.Stop() {
+		t.Fatal("Stop must report an active timer only once")
+	}
+
+	c.Advance(5 * time.Second)
+	want := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second}
+	if len(fired) != len(want) {
+		t.Fatalf("fired at %v, want %v", fired, want)
+	}
+	for i := range want {
+		if fired[i] != want[i] {
+			t.Fatalf("fired at %v, want %v", fired, want)
+		}
+	}
+	if got := c.Now().Sub(start); got != 5*time.Second {
+		t.Fatalf("now = start+%v, want start+5s", got)
+	}
+}
+
+// Stop з іншої горутини під час Advance (як SendAfter, скасований агентом) - без гонок під -race.
+func TestFakeClockStopDuringAdvance(t *testing.T) {
+	c := NewFakeClock(time.Now())
+	timers := make([]mas.Timer, 100)
+	for i := range timers {
+		timers[i] = c.AfterFunc(time.Duration(i)*time.Millisecond, func() {})
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for _, tm := range timers {
+			tm.Stop()
+		}
+	}()
+	c.Advance(time.Second)
+	wg.Wait()
+
+	if c.Pending() != 0 {
+		t.Fatalf("pending %d", c.Pending())
+	}
+}
+
+// moody відповідає, хто його планує; після "SULK" - поведінкою mastest.sulk.
+type moody struct {
+	mas.BaseAgent
+}
+
+func (m *moody) Plan(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
+	if msg.Payload == "SULK" {
+		return []mas.Action{mas.Become("mastest.sulk")}, nil
+	}
+	return []mas.Action{mas.Send("report", "plan:"+mas.IdentityFrom(ctx))}, nil
+}
+
+func init() {
+	mas.RegisterReceive("mastest.sulk", func(ctx context.Context, self mas.Agent, msg mas.Envelope) ([]mas.Action, error) {
+		return []mas.Action{mas.Send("report", "sulk:"+mas.IdentityFrom(ctx))}, nil
+	})
+}
+
+func TestPlanUsesDeliveryPath(t *testing.T) {
+	ts := New(t)
+	ts.Spawn(&moody{BaseAgent: mas.BaseAgent{IDVal: "moody"}})
+
+	// Plan бачить ідентичність агента, як і справжня доставка
+	actions, err := ts.Plan("moody", mas.Envelope{Payload: "HI"})
+	if err != nil || ts.Apply("moody", actions...) != nil {
+		t.Fatal(err)
+	}
+	ts.RunUntilIdle()
+	ts.ExpectMessage("report", mas.PayloadEquals("plan:moody"))
+
+	// Після Become Plan іде через активну поведінку
+	ts.Send("main", "moody", "SULK")
+	ts.RunUntilIdle()
+	actions, err = ts.Plan("moody", mas.Envelope{Payload: "HI"})
+	if err != nil || ts.Apply("moody", actions...) != nil {
+		t.Fatal(err)
+	}
+	ts.RunUntilIdle()
+	ts.ExpectMessage("report", mas.PayloadEquals("sulk:moody"))
+}
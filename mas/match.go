@@ -0,0 +1,98 @@
+package mas
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Matcher - шаблон повідомлення (аналог MessageTemplate у JADE).
+// Використовується для фільтрації конвертів: у тестах, stash, поведінках.
+type Matcher func(Envelope) bool
+
+// MatchAny приймає будь-яке повідомлення.
+func MatchAny() Matcher {
+	return func(Envelope) bool { return true }
+}
+
+// PayloadEquals перевіряє точну рівність payload (наприклад, "TICK").
+func PayloadEquals(v any) Matcher {
+	return func(env Envelope) bool {
+		return reflect.DeepEqual(env.Payload, v)
+	}
+}
+
+// PayloadHasPrefix перевіряє рядкові команди з параметрами ("POLICY:DFS").
+func PayloadHasPrefix(prefix string) Matcher {
+	return func(env Envelope) bool {
+		str, ok := env.Payload.(string)
+		return ok && strings.HasPrefix(str, prefix)
+	}
+}
+
+// PayloadOf перевіряє тип payload. Приймає і значення T, і вказівник *T,
+// бо після GOB-декодування тип може "перетворитися" на вказівник.
+func PayloadOf[T any]() Matcher {
+	return func(env Envelope) bool {
+		_, ok := PayloadAs[T](env)
+		return ok
+	}
+}
+
+// PayloadAs дістає payload як T (значення або розіменований *T).
+func PayloadAs[T any](env Envelope) (T, bool) {
+	switch p := env.Payload.(type) {
+	case T:
+		return p, true
+	case *T:
+		if p != nil {
+			return *p, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// PayloadLike перевіряє payload маскою path.Match так само, як правила доступу (Rule.Payload):
+// рядок ("STOP", "POLICY:*") або ім'я типу ("MoveRequest", "maze.*").
+func PayloadLike(mask string) Matcher {
+	return func(env Envelope) bool { return payloadMatch(mask, env.Payload) }
+}
+
+// From перевіряє відправника.
+func From(id string) Matcher {
+	return func(env Envelope) bool { return env.From == id }
+}
+
+// To перевіряє отримувача.
+func To(id string) Matcher {
+	return func(env Envelope) bool { return env.To == id }
+}
+
+// AllOf - логічне "І" для шаблонів.
+func AllOf(ms ...Matcher) Matcher {
+	return func(env Envelope) bool {
+		for _, m := range ms {
+			if !m(env) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// AnyOf - логічне "АБО" для шаблонів.
+func AnyOf(ms ...Matcher) Matcher {
+	return func(env Envelope) bool {
+		for _, m := range ms {
+			if m(env) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not інвертує шаблон.
+func Not(m Matcher) Matcher {
+	return func(env Envelope) bool { return !m(env) }
+}
@@ -1,6 +1,9 @@
 package mas
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type Performative string
 
@@ -8,15 +11,39 @@ const (
 	Request Performative = "REQUEST"
 	Propose Performative = "PROPOSE"
 	Inform  Performative = "INFORM"
+
+	// NotUnderstood - відповідь на повідомлення, для якого в агента немає обробника.
+	NotUnderstood Performative = "NOT_UNDERSTOOD"
+	// Refuse - отримувач (або система, наприклад, через ліміт швидкості) відмовився приймати повідомлення.
+	Refuse Performative = "REFUSE"
 )
 
 type Envelope struct {
-	From    string
-	To      string
+	// From - відправник. Для агентів і автентифікованого коду його ставить System
+	// (аргумент fromID ігнорується); лише анонімний код ззовні вказує його сам.
+	From string
+	To   string
+	// Sender - ідентичність, яку поставила System (агент, у чиєму обробнику
+	// викликано Send, або System.Authenticate). Порожній - невідомий код ззовні.
+	// Правила доступу (Allow/Deny) дивляться сюди.
+	Sender string
+	// ReplyTo - куди відповідати, якщо не From (WithReplyTo: Router зберігає автора запиту).
+	ReplyTo string
 	Type    Performative
 	Payload any
 	// Metadata дозволяє middleware додавати контекст (наприклад, TraceID)
 	Metadata map[string]string
+	// Deadline - до якого моменту повідомлення має сенс (нульовий - без обмежень).
+	// Отримувач обробляє його з context.WithDeadline.
+	Deadline time.Time
+}
+
+// ReplyAddr - адреса відповіді: ReplyTo, а якщо його немає - From.
+func (e Envelope) ReplyAddr() string {
+	if e.ReplyTo != "" {
+		return e.ReplyTo
+	}
+	return e.From
 }
 
 // Handler - функція, яка обробляє повідомлення
@@ -0,0 +1,278 @@
+package mas
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+)
+
+// ErrMigrating - причина зупинки агента, який переїжджає (Migrate, Export).
+// BaseAgent.Run у цьому випадку не дочитує inbox: скринька їде разом з агентом.
+var ErrMigrating = errors.New("agent is migrating")
+
+// forward - нова адреса агента, що переїхав: інша система в цьому процесі
+// або довільна доставка (наприклад, мережевий транспорт до іншого процесу).
+type forward struct {
+	sys *System
+	fn  Dispatcher
+}
+
+func (f forward) send(ctx context.Context, fromID, toID string, payload any, expire <-chan time.Time) error {
+	if f.sys != nil {
+		return f.sys.send(ctx, fromID, toID, payload, expire)
+	}
+	return f.fn(ctx, newEnvelope(ctx, fromID, toID, payload))
+}
+
+func (s *System) forwardFor(id string) (forward, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	f, ok := s.forwards[id]
+	return f, ok
+}
+
+// Forward встановлює адресу переїзду вручну: повідомлення для id, якого
+// в системі немає, передаються в d (наприклад, після Export в інший процес).
+func (s *System) Forward(id string, d Dispatcher) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.forwards[id] = forward{fn: d}
+}
+
+// Migrate переносить працюючого агента разом зі станом і непрочитаними
+// повідомленнями в систему target (наприклад, з головної системи в підсистему).
+//
+// Агент дообробляє поточне повідомлення і зупиняється, його стан проходить
+// через GOB (типи мають бути зареєстровані, як і для Shutdown), а на старому місці
+// лишається адреса переїзду: Send, Inspect і Backpressure далі знаходять агента.
+//
+// Відкладені повідомлення (stash) і фільтр AcceptOnly переїжджають разом з агентом.
+// Migrate чекає на зупинку агента, тому з його власних дій кличте дію MigrateTo.
+// Агентів з дітьми і самих дітей не переносимо: зв'язок з батьком розірвався б.
+func (s *System) Migrate(id string, target *System) error {
+	if target == nil || target == s {
+		return fmt.Errorf("migrate failed: bad target system for '%s'", id)
+	}
+	if s.dispatch != nil || target.dispatch != nil {
+		return fmt.Errorf("migrate failed: not supported in synchronous mode")
+	}
+
+	c, agent, err := s.detach(id)
+	if err != nil {
+		return err
+	}
+
+	moved, err := recodeAgent(agent)
+	if err != nil {
+		s.reattach(id, agent, c.inbox)
+		return fmt.Errorf("migrate %s: %w", id, err)
+	}
+
+	target.mu.Lock()
+	if _, exists := target.agents[id]; exists {
+		target.mu.Unlock()
+		s.reattach(id, agent, c.inbox)
+		return fmt.Errorf("migrate failed: agent with ID '%s' already exists in target", id)
+	}
+	moved.SetSystem(target)
+	// Приватні поля через GOB не проходять: відкладені повідомлення і фільтр переносимо самі
+	if sel, ok := moved.(selective); ok {
+		accept, stash := stashOf(agent)
+		sel.restoreStash(accept, slices.Clone(stash))
+	}
+	target.agents[id] = moved
+	// Стара скринька переходить до нового агента: і відкладені повідомлення,
+	// і відправники, які зараз чекають на місце, нічого не втрачають
+	target.startWith(id, moved, c.inbox)
+	target.mu.Unlock()
+
+	s.mu.Lock()
+	delete(s.agents, id)
+	delete(s.registry, id)
+	s.forwards[id] = forward{sys: target}
+	s.mu.Unlock()
+
+	return nil
+}
+
+// migration - агент у дорозі між процесами (Export/Import).
+type migration struct {
+	Agent   Agent
+	Pending []Envelope
+}
+
+// Export зупиняє агента і повертає його стан разом із непрочитаними
+// повідомленнями у вигляді GOB, щоб відновити в іншому процесі через Import.
+// Якщо fwd задано, він стає адресою переїзду для подальших повідомлень.
+// Корисні навантаження в скриньці теж мають бути зареєстровані gob.Register.
+// Відкладені повідомлення (stash) йдуть першими, але фільтр AcceptOnly через GOB
+// не передати, тож агента, що чекає на вибіркове отримання, не експортуємо.
+func (s *System) Export(id string, fwd Dispatcher) ([]byte, error) {
+	if s.dispatch != nil {
+		return nil, fmt.Errorf("export failed: not supported in synchronous mode")
+	}
+
+	c, agent, err := s.detach(id)
+	if err != nil {
+		return nil, err
+	}
+
+	accept, stash := stashOf(agent)
+	if accept != nil {
+		s.reattach(id, agent, c.inbox)
+		return nil, fmt.Errorf("export failed: agent '%s' is waiting for a selective receive (AcceptOnly)", id)
+	}
+
+	queued := drainPending(c.inbox)
+	m := migration{Agent: agent, Pending: append(slices.Clone(stash), queued...)}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(m); err != nil {
+		// Повертаємо все як було (stash лишився в агента). Поки йшло кодування, відправники
+		// могли знову заповнити стару скриньку, тож, як і Restart, переносимо все в нову.
+		pending := append(queued, drainPending(c.inbox)...)
+		inbox := make(chan Envelope, max(cap(c.inbox), len(pending)))
+		for _, env := range pending {
+			inbox <- env
+		}
+		s.reattach(id, agent, inbox)
+		return nil, fmt.Errorf("export %s: %w", id, err)
+	}
+
+	s.mu.Lock()
+	delete(s.agents, id)
+	delete(s.registry, id)
+	if fwd != nil {
+		s.forwards[id] = forward{fn: fwd}
+	}
+	s.mu.Unlock()
+
+	// Те, що встигло прийти під час кодування, відправляємо за новою адресою
+	for _, env := range drainPending(c.inbox) {
+		if fwd == nil {
+			log.Printf("Export %s: message from %s dropped", id, env.From)
+			continue
+		}
+		if err := fwd(s.ctx, env); err != nil {
+			log.Printf("Export %s: forward failed: %v", id, err)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Import запускає агента, отриманого з Export, разом з його непрочитаними повідомленнями.
+func (s *System) Import(data []byte) (Agent, error) {
+	if s.dispatch != nil {
+		return nil, fmt.Errorf("import failed: not supported in synchronous mode")
+	}
+
+	var m migration
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&m); err != nil {
+		return nil, fmt.Errorf("import: %w", err)
+	}
+	if m.Agent == nil {
+		return nil, fmt.Errorf("import: no agent in data")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := m.Agent.ID()
+	if _, exists := s.agents[id]; exists {
+		return nil, fmt.Errorf("import failed: agent with ID '%s' already exists", id)
+	}
+
+	inbox := make(chan Envelope, max(100, len(m.Pending)))
+	for _, env := range m.Pending {
+		inbox <- env
+	}
+
+	m.Agent.SetSystem(s)
+	s.agents[id] = m.Agent
+	s.startWith(id, m.Agent, inbox)
+
+	return m.Agent, nil
+}
+
+// MigrateTo - дія: агент переїжджає в систему target після обробки поточного повідомлення.
+func MigrateTo(target *System) Action {
+	return func(ctx context.Context, a Agent, sys *System) error {
+		// Migrate чекає на зупинку агента, тому не можна чекати в його ж горутині
+		go func() {
+			if err := sys.Migrate(a.ID(), target); err != nil {
+				log.Printf("Agent %s: %v", a.ID(), err)
+			}
+		}()
+		return nil
+	}
+}
+
+// detach зупиняє горутину агента, не чіпаючи його скриньку і запис у системі.
+func (s *System) detach(id string) (*cell, Agent, error) {
+	s.mu.RLock()
+	c, exists := s.registry[id]
+	agent := s.agents[id]
+	children := s.childrenLocked(id)
+	s.mu.RUnlock()
+
+	if !exists || agent == nil {
+		return nil, nil, fmt.Errorf("migrate failed: agent '%s' not found", id)
+	}
+	if len(children) > 0 {
+		return nil, nil, fmt.Errorf("migrate failed: agent '%s' has children %v", id, children)
+	}
+	// Дитина теж лишається з батьком: в іншій системі її ParentID нікуди б не вказував
+	if p, ok := agent.(interface{ Parent() string }); ok && p.Parent() != "" {
+		return nil, nil, fmt.Errorf("migrate failed: agent '%s' is a child of '%s'", id, p.Parent())
+	}
+
+	c.cancel(ErrMigrating)
+	<-c.done
+	return c, agent, nil
+}
+
+// reattach запускає агента знову на старому місці (переїзд не вдався).
+func (s *System) reattach(id string, agent Agent, inbox chan Envelope) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.startWith(id, agent, inbox)
+}
+
+// drainPending забирає зі скриньки все, що там є, без очікування.
+// Службові запити Inspect не переносяться: їхні автори отримають помилку.
+func drainPending(inbox chan Envelope) []Envelope {
+	var pending []Envelope
+	for {
+		select {
+		case env := <-inbox:
+			if _, ok := env.Payload.(inspectRequest); ok {
+				continue
+			}
+			pending = append(pending, env)
+		default:
+			return pending
+		}
+	}
+}
+
+// recodeAgent проводить агента через GOB: нова система отримує незалежну копію стану.
+func recodeAgent(a Agent) (Agent, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(migration{Agent: a}); err != nil {
+		return nil, err
+	}
+	var m migration
+	if err := gob.NewDecoder(&buf).Decode(&m); err != nil {
+		return nil, err
+	}
+	return m.Agent, nil
+}
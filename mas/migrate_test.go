@@ -0,0 +1,191 @@
+package mas
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// badPayload не кодується: Export на ньому падає, спершу викликавши onEncode.
+type badPayload struct{ onEncode func() }
+
+func (p badPayload) GobEncode() ([]byte, error) {
+	p.onEncode()
+	return nil, errors.New("cannot encode")
+}
+
+func init() {
+	gob.Register(badPayload{})
+	RegisterType("mas.parkAgent", func() Agent { return &parkAgent{} })
+	RegisterType("mas.pickyAgent", func() Agent { return &pickyAgent{} })
+}
+
+// parkAgent зависає на "HANG", доки не скасують його контекст, і рахує решту повідомлень.
+type parkAgent struct {
+	BaseAgent
+	got atomic.Int32
+}
+
+func (a *parkAgent) Plan(ctx context.Context, msg Envelope) ([]Action, error) {
+	if msg.Payload == "HANG" {
+		<-ctx.Done()
+		return nil, nil
+	}
+	a.got.Add(1)
+	return nil, nil
+}
+
+func TestExportFailureKeepsFullInbox(t *testing.T) {
+	sys := NewSystem()
+	a := &parkAgent{BaseAgent: BaseAgent{IDVal: "parked"}}
+	sys.Spawn(a)
+	sys.Send(context.Background(), "main", "parked", "HANG")
+	for deadline := time.Now().Add(time.Second); sys.Health().Agents[0].Message == nil; {
+		if time.Now().After(deadline) {
+			t.Fatal("agent did not start processing HANG")
+		}
+	}
+
+	// Поки Export кодує, відправники знову заповнюють скриньку
+	refill := func() {
+		for i := range 100 {
+			if err := sys.TrySend(context.Background(), "main", "parked", i); err != nil {
+				t.Error(err)
+			}
+		}
+	}
+	for i := range 99 {
+		sys.Send(context.Background(), "main", "parked", i)
+	}
+	sys.Send(context.Background(), "main", "parked", badPayload{onEncode: refill})
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := sys.Export("parked", nil)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("export of an unregistered payload succeeded")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Export hung while restoring the inbox")
+	}
+
+	// Агент працює далі на старому місці з усіма повідомленнями
+	for deadline := time.Now().Add(2 * time.Second); a.got.Load() < 200; {
+		if time.Now().After(deadline) {
+			t.Fatalf("agent got %d of 200 messages after the failed export", a.got.Load())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if _, ok := sys.GetAgent("parked"); !ok {
+		t.Fatal("agent is gone after the failed export")
+	}
+}
+
+// pickyAgent на "WAIT" приймає лише "REPLY", а решту відкладає; Seen - порядок обробки.
+type pickyAgent struct {
+	BaseAgent
+	Seen []string
+}
+
+func (a *pickyAgent) Plan(ctx context.Context, msg Envelope) ([]Action, error) {
+	text, _ := msg.Payload.(string)
+	a.Seen = append(a.Seen, text)
+	switch text {
+	case "WAIT":
+		a.AcceptOnly(PayloadEquals("REPLY"))
+	case "REPLY":
+		a.AcceptAll()
+	}
+	return nil, nil
+}
+
+// seen чекає, доки агент id обробить want, і повертає порядок обробки.
+func seen(t *testing.T, sys *System, id string, want int) []string {
+	t.Helper()
+	var got []string
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+		sys.Inspect(id, func(a Agent) { got = slices.Clone(a.(*pickyAgent).Seen) })
+		if len(got) >= want {
+			return got
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s processed %v", id, got)
+		}
+	}
+}
+
+func TestMigrateKeepsStashAndFilter(t *testing.T) {
+	sys := NewSystem()
+	defer sys.Shutdown(context.Background())
+	target := sys.CreateSubsystem()
+	sys.Spawn(&pickyAgent{BaseAgent: BaseAgent{IDVal: "picky"}})
+
+	for _, p := range []string{"WAIT", "TICK-1", "TICK-2"} {
+		sys.Send(context.Background(), "main", "picky", p)
+	}
+	seen(t, sys, "picky", 1)
+	// Чекаємо, доки обидва TICK опиняться у stash
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+		var n int
+		sys.Inspect("picky", func(a Agent) { n = a.(*pickyAgent).Stashed() })
+		if n == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("stashed %d, want 2", n)
+		}
+	}
+
+	if err := sys.Migrate("picky", target); err != nil {
+		t.Fatal(err)
+	}
+	// Фільтр переїхав: TICK і далі відкладаються, доки не прийде REPLY
+	sys.Send(context.Background(), "main", "picky", "TICK-3")
+	sys.Send(context.Background(), "main", "picky", "REPLY")
+	got := seen(t, target, "picky", 5)
+	if want := []string{"WAIT", "REPLY", "TICK-1", "TICK-2", "TICK-3"}; !slices.Equal(got, want) {
+		t.Fatalf("processed %v, want %v", got, want)
+	}
+}
+
+func TestExportWithStash(t *testing.T) {
+	sys := NewSystem()
+	defer sys.Shutdown(context.Background())
+	sys.Spawn(&pickyAgent{BaseAgent: BaseAgent{IDVal: "picky"}})
+	sys.Send(context.Background(), "main", "picky", "WAIT")
+	sys.Send(context.Background(), "main", "picky", "TICK")
+	seen(t, sys, "picky", 1)
+
+	// Фільтр через GOB не передати - агент лишається на місці
+	if _, err := sys.Export("picky", nil); err == nil {
+		t.Fatal("exported an agent waiting for a selective receive")
+	}
+	sys.Send(context.Background(), "main", "picky", "REPLY")
+	if got := seen(t, sys, "picky", 3); !slices.Equal(got, []string{"WAIT", "REPLY", "TICK"}) {
+		t.Fatalf("after the refused export: %v", got)
+	}
+}
+
+func TestMigrateRejectsChildren(t *testing.T) {
+	sys := NewSystem()
+	defer sys.Shutdown(context.Background())
+	target := sys.CreateSubsystem()
+	sys.Spawn(&pickyAgent{BaseAgent: BaseAgent{IDVal: "parent"}})
+	sys.SpawnChild("parent", &pickyAgent{BaseAgent: BaseAgent{IDVal: "child"}})
+
+	for _, id := range []string{"parent", "child"} {
+		if err := sys.Migrate(id, target); err == nil {
+			t.Fatalf("migrated %s", id)
+		}
+	}
+	sys.Send(context.Background(), "main", "child", "PING")
+	seen(t, sys, "child", 1)
+}
@@ -0,0 +1,153 @@
+package mas
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"sync"
+)
+
+// PlannerFunc дозволяє використати звичайну функцію як Planner.
+type PlannerFunc func(ctx context.Context, state any, msg Envelope) ([]Action, error)
+
+func (f PlannerFunc) Plan(ctx context.Context, state any, msg Envelope) ([]Action, error) {
+	return f(ctx, state, msg)
+}
+
+var (
+	plannersMu sync.RWMutex
+	planners   = make(map[string]Planner)
+)
+
+// RegisterPlanner реєструє Planner під іменем (зазвичай у init()).
+// PlannerAgent зберігає лише ім'я, тому після Startup мозок знаходиться знову.
+func RegisterPlanner(name string, p Planner) {
+	plannersMu.Lock()
+	defer plannersMu.Unlock()
+	planners[name] = p
+}
+
+// LookupPlanner шукає зареєстрований Planner.
+func LookupPlanner(name string) (Planner, bool) {
+	plannersMu.RLock()
+	defer plannersMu.RUnlock()
+	p, ok := planners[name]
+	return p, ok
+}
+
+// StateCloner - стан, який сам вміє зробити свою глибоку копію (інакше - через GOB).
+type StateCloner interface {
+	CloneState() any
+}
+
+// PlannerAgent - агент, у якого мозок (Planner) відокремлений від стану.
+// Planner отримує копію State і лише повертає дії; змінюють стан дії SetState/UpdateState.
+// Тож один Planner можна тестувати окремо, ділити між агентами і міняти на льоту (UsePlanner).
+// State має бути зареєстрований gob.Register, щоб агент зберігався між запусками.
+type PlannerAgent struct {
+	BaseAgent
+	PlannerName string // Ім'я в реєстрі RegisterPlanner
+	State       any
+}
+
+// NewPlannerAgent створює агента з мозком plannerName і початковим станом.
+func NewPlannerAgent(id, plannerName string, state any) *PlannerAgent {
+	return &PlannerAgent{
+		BaseAgent:   BaseAgent{IDVal: id},
+		PlannerName: plannerName,
+		State:       state,
+	}
+}
+
+// Plan передає Planner'у копію стану: мозок не може змінити агента в обхід дій.
+func (p *PlannerAgent) Plan(ctx context.Context, msg Envelope) ([]Action, error) {
+	planner, ok := LookupPlanner(p.PlannerName)
+	if !ok {
+		return nil, fmt.Errorf("planner %q is not registered", p.PlannerName)
+	}
+	state, err := CloneState(p.State)
+	if err != nil {
+		return nil, err
+	}
+	return planner.Plan(ctx, state, msg)
+}
+
+// Clone - копія для System.Snapshot.
+func (p *PlannerAgent) Clone() Agent {
+	c := *p
+	c.BaseAgent = p.CloneBase()
+	if state, err := CloneState(p.State); err == nil {
+		c.State = state
+	}
+	return &c
+}
+
+// CloneState робить глибоку копію стану: через StateCloner або GOB.
+func CloneState(state any) (any, error) {
+	if state == nil {
+		return nil, nil
+	}
+	if c, ok := state.(StateCloner); ok {
+		return c.CloneState(), nil
+	}
+
+	// Обгортка потрібна, щоб GOB записав конкретний тип за інтерфейсом
+	type box struct{ S any }
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(box{S: state}); err != nil {
+		return nil, fmt.Errorf("clone state %T: %w", state, err)
+	}
+	var out box
+	if err := gob.NewDecoder(&buf).Decode(&out); err != nil {
+		return nil, fmt.Errorf("clone state %T: %w", state, err)
+	}
+	return out.S, nil
+}
+
+// SetState - дія: замінити стан PlannerAgent на новий.
+func SetState(state any) Action {
+	return func(ctx context.Context, a Agent, sys *System) error {
+		p, ok := a.(*PlannerAgent)
+		if !ok {
+			return fmt.Errorf("SetState: agent %s is not a PlannerAgent", a.ID())
+		}
+		p.State = state
+		p.MarkChanged()
+		return nil
+	}
+}
+
+// UpdateState - дія: обчислити новий стан з поточного (fn отримує сам стан, не копію).
+func UpdateState(fn func(state any) any) Action {
+	return func(ctx context.Context, a Agent, sys *System) error {
+		p, ok := a.(*PlannerAgent)
+		if !ok {
+			return fmt.Errorf("UpdateState: agent %s is not a PlannerAgent", a.ID())
+		}
+		p.State = fn(p.State)
+		p.MarkChanged()
+		return nil
+	}
+}
+
+// UsePlanner - дія: замінити мозок PlannerAgent (гаряча заміна, стан лишається).
+func UsePlanner(name string) Action {
+	return func(ctx context.Context, a Agent, sys *System) error {
+		p, ok := a.(*PlannerAgent)
+		if !ok {
+			return fmt.Errorf("UsePlanner: agent %s is not a PlannerAgent", a.ID())
+		}
+		if _, ok := LookupPlanner(name); !ok {
+			return fmt.Errorf("UsePlanner: planner %q is not registered", name)
+		}
+		p.PlannerName = name
+		p.MarkChanged()
+		return nil
+	}
+}
+
+func init() {
+	gob.Register(&PlannerAgent{})
+}
@@ -0,0 +1,94 @@
+package mas_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"testing"
+
+	"github.com/youryharchenko/go-mas/mas"
+	"github.com/youryharchenko/go-mas/mas/mastest"
+)
+
+// tallyState - стан лічильника; Log ловить мозок, що змінює копію стану.
+type tallyState struct {
+	N   int
+	Log []string
+}
+
+func init() {
+	gob.Register(tallyState{})
+	mas.RegisterPlanner("mas_test.up", tallyPlanner(1))
+	mas.RegisterPlanner("mas_test.down", tallyPlanner(-1))
+}
+
+// tallyPlanner на "STEP" додає step до N, на "SWAP:<name>" міняє мозок.
+func tallyPlanner(step int) mas.PlannerFunc {
+	return func(ctx context.Context, state any, msg mas.Envelope) ([]mas.Action, error) {
+		st := state.(tallyState)
+		if len(st.Log) > 0 {
+			st.Log[0] = "planned" // Мозку дозволено псувати свою копію
+		}
+		switch payload, _ := msg.Payload.(string); {
+		case payload == "STEP":
+			return []mas.Action{mas.UpdateState(func(s any) any {
+				cur := s.(tallyState)
+				cur.N += step
+				return cur
+			})}, nil
+		case len(payload) > 5 && payload[:5] == "SWAP:":
+			return []mas.Action{mas.UsePlanner(payload[5:])}, nil
+		}
+		return nil, nil
+	}
+}
+
+func TestPlannerIsPure(t *testing.T) {
+	// Мозок перевіряється без системи: стан на вході, дії на виході
+	actions, err := tallyPlanner(1).Plan(context.Background(), tallyState{N: 2}, mas.Envelope{Payload: "STEP"})
+	if err != nil || len(actions) != 1 {
+		t.Fatalf("got %d actions, %v", len(actions), err)
+	}
+	a := mas.NewPlannerAgent("solo", "mas_test.up", tallyState{N: 2})
+	if err := actions[0](context.Background(), a, nil); err != nil {
+		t.Fatal(err)
+	}
+	if st := a.State.(tallyState); st.N != 3 {
+		t.Fatalf("N = %d, want 3", st.N)
+	}
+}
+
+func TestPlannerAgentHotSwap(t *testing.T) {
+	ts := mastest.New(t)
+	a := mas.NewPlannerAgent("tally", "mas_test.up", tallyState{Log: []string{"start"}})
+	ts.Spawn(a)
+
+	for _, p := range []string{"STEP", "STEP", "SWAP:mas_test.down", "STEP", "SWAP:mas_test.ghost", "STEP"} {
+		ts.Send("main", "tally", p)
+	}
+	ts.RunUntilIdle()
+
+	st := a.State.(tallyState)
+	if st.N != 0 {
+		t.Fatalf("N = %d, want 0 (up, up, down, down)", st.N)
+	}
+	if len(st.Log) != 1 || st.Log[0] != "start" {
+		t.Fatalf("planner changed the agent state directly: %v", st.Log)
+	}
+	if a.PlannerName != "mas_test.down" {
+		t.Fatalf("planner %q after swapping to an unknown one, want mas_test.down", a.PlannerName)
+	}
+
+	// Стан і мозок переживають збереження світу (Shutdown/Startup)
+	var buf bytes.Buffer
+	if err := mas.EncodeWorld(&buf, map[string]mas.Agent{"tally": a}); err != nil {
+		t.Fatal(err)
+	}
+	world, err := mas.DecodeWorld(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := world["tally"].(*mas.PlannerAgent); got.PlannerName != "mas_test.down" || got.State.(tallyState).N != 0 {
+		t.Fatalf("decoded %+v", got)
+	}
+}
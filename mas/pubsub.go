@@ -0,0 +1,93 @@
+package mas
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Publication - payload, яке отримують підписники теми (Publish).
+type Publication struct {
+	Topic   string
+	Payload any
+}
+
+// Subscribe підписує агента id на тему topic.
+func (s *System) Subscribe(topic, id string) {
+	s.topicMu.Lock()
+	defer s.topicMu.Unlock()
+
+	if s.topics == nil {
+		s.topics = make(map[string][]string)
+	}
+	if !slices.Contains(s.topics[topic], id) {
+		s.topics[topic] = append(s.topics[topic], id)
+	}
+}
+
+// Unsubscribe скасовує підписку агента id на тему topic.
+func (s *System) Unsubscribe(topic, id string) {
+	s.topicMu.Lock()
+	defer s.topicMu.Unlock()
+
+	s.topics[topic] = slices.DeleteFunc(s.topics[topic], func(sub string) bool { return sub == id })
+	if len(s.topics[topic]) == 0 {
+		delete(s.topics, topic)
+	}
+}
+
+// Subscribers повертає ID підписників теми.
+func (s *System) Subscribers(topic string) []string {
+	s.topicMu.Lock()
+	defer s.topicMu.Unlock()
+	return slices.Clone(s.topics[topic])
+}
+
+// Publish розсилає Publication{topic, payload} усім підписникам теми від імені fromID.
+// Зупинені агенти мовчки видаляються з підписки; помилки доставки решті повертаються разом.
+func (s *System) Publish(ctx context.Context, fromID, topic string, payload any) error {
+	s.notifyTap(Traffic{Topic: topic, Envelope: newEnvelope(ctx, fromID, "", payload)})
+
+	var errs []error
+	for _, id := range s.Subscribers(topic) {
+		if _, exists := s.GetAgent(id); !exists {
+			s.Unsubscribe(topic, id)
+			continue
+		}
+		if err := s.Send(ctx, fromID, id, Publication{Topic: topic, Payload: payload}); err != nil {
+			errs = append(errs, fmt.Errorf("publish %s to %s: %w", topic, id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// --- Дії ---
+
+// Subscribe - дія: підписати агента на тему.
+func Subscribe(topic string) Action {
+	return func(ctx context.Context, a Agent, sys *System) error {
+		sys.Subscribe(topic, a.ID())
+		return nil
+	}
+}
+
+// Unsubscribe - дія: відписати агента від теми.
+func Unsubscribe(topic string) Action {
+	return func(ctx context.Context, a Agent, sys *System) error {
+		sys.Unsubscribe(topic, a.ID())
+		return nil
+	}
+}
+
+// Publish - дія: опублікувати payload у тему.
+func Publish(topic string, payload any) Action {
+	return func(ctx context.Context, a Agent, sys *System) error {
+		return sys.Publish(ctx, a.ID(), topic, payload)
+	}
+}
+
+func init() {
+	gob.Register(Publication{})
+}
@@ -0,0 +1,279 @@
+package mas
+
+import (
+	"encoding/gob"
+	"fmt"
+	"log"
+	"maps"
+	"time"
+)
+
+// LimitPolicy - що робити з повідомленням понад ліміт.
+type LimitPolicy string
+
+const (
+	LimitDelay      LimitPolicy = "DELAY"       // Доставити пізніше, коли з'явиться токен
+	LimitDrop       LimitPolicy = "DROP"        // Мовчки викинути
+	LimitDeadLetter LimitPolicy = "DEAD_LETTER" // Передати агенту DeadLettersID
+	LimitRefuse     LimitPolicy = "REFUSE"      // Відповісти відправнику REFUSE
+)
+
+// DeadLettersID - агент, який отримує недоставлені повідомлення (DeadLetterMsg).
+// Якщо його немає, такі повідомлення лише логуються.
+const DeadLettersID = "dead-letters"
+
+// DeadLetterMsg - payload для DeadLettersID: оригінальний конверт і причина.
+type DeadLetterMsg struct {
+	Envelope Envelope
+	Reason   string
+}
+
+// ReasonThrottled - причина в DeadLetterMsg для повідомлень понад ліміт.
+const ReasonThrottled = "rate limit exceeded"
+
+// Limit - налаштування token bucket: Rate повідомлень на секунду, сплеск до Burst.
+type Limit struct {
+	Rate   float64
+	Burst  int
+	Policy LimitPolicy
+
+	// MaxDelay - для LimitDelay: довше не відкладаємо, а викидаємо (0 - одна секунда).
+	MaxDelay time.Duration
+}
+
+// limitKey - для кого ліміт: From == "" - для всіх відправників отримувача.
+type limitKey struct {
+	From, To string
+}
+
+// bucket - token bucket одного ліміту.
+type bucket struct {
+	limit  Limit
+	tokens float64
+	last   time.Time
+}
+
+func (b *bucket) refill(now time.Time) {
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.limit.Rate
+	}
+	b.last = now
+	if burst := float64(max(b.limit.Burst, 1)); b.tokens > burst {
+		b.tokens = burst
+	}
+}
+
+// take бере токен; для LimitDelay може "позичити" майбутній і повертає, скільки чекати.
+func (b *bucket) take(now time.Time) (time.Duration, bool) {
+	b.refill(now)
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+	if b.limit.Policy != LimitDelay || b.limit.Rate <= 0 {
+		return 0, false
+	}
+
+	maxDelay := b.limit.MaxDelay
+	if maxDelay == 0 {
+		maxDelay = time.Second
+	}
+	wait := time.Duration((1 - b.tokens) / b.limit.Rate * float64(time.Second))
+	if wait > maxDelay {
+		return 0, false
+	}
+	b.tokens--
+	return wait, true
+}
+
+// Metrics - лічильники системи (події обмеження швидкості і доступу).
+type Metrics struct {
+	Delayed      uint64
+	Dropped      uint64
+	DeadLettered uint64
+	Refused      uint64
+	Denied       uint64 // Заборонені правилами доступу (Allow/Deny)
+
+	// Throttled - скільки повідомлень кожного отримувача не пройшли ліміт одразу
+	Throttled map[string]uint64
+}
+
+// WithRateLimit обмежує швидкість повідомлень до агента to (від усіх відправників разом).
+func WithRateLimit(to string, l Limit) Option {
+	return func(s *System) {
+		s.SetRateLimit(to, l)
+	}
+}
+
+// WithSenderRateLimit обмежує швидкість повідомлень від from до to.
+func WithSenderRateLimit(from, to string, l Limit) Option {
+	return func(s *System) {
+		s.SetSenderRateLimit(from, to, l)
+	}
+}
+
+// SetRateLimit задає (або з Rate == 0 - знімає) ліміт для отримувача to.
+func (s *System) SetRateLimit(to string, l Limit) {
+	s.setLimit(limitKey{To: to}, l)
+}
+
+// SetSenderRateLimit задає (або з Rate == 0 - знімає) ліміт для пари from -> to.
+func (s *System) SetSenderRateLimit(from, to string, l Limit) {
+	s.setLimit(limitKey{From: from, To: to}, l)
+}
+
+func (s *System) setLimit(key limitKey, l Limit) {
+	s.limitMu.Lock()
+	defer s.limitMu.Unlock()
+
+	if l.Rate <= 0 {
+		delete(s.limits, key)
+		return
+	}
+	if s.limits == nil {
+		s.limits = make(map[limitKey]*bucket)
+	}
+	s.limits[key] = &bucket{limit: l, tokens: float64(max(l.Burst, 1))}
+}
+
+// Metrics повертає знімок лічильників.
+func (s *System) Metrics() Metrics {
+	s.limitMu.Lock()
+	defer s.limitMu.Unlock()
+
+	m := s.metrics
+	m.Throttled = maps.Clone(s.metrics.Throttled)
+	return m
+}
+
+// throttle перевіряє ліміти для конверта. handled == true означає, що
+// звичайна доставка не потрібна: повідомлення відкладене, викинуте або відхилене.
+// Для відправника це не помилка: про відмову він дізнається з REFUSE.
+func (s *System) throttle(env Envelope) (handled bool) {
+	s.limitMu.Lock()
+	if len(s.limits) == 0 {
+		s.limitMu.Unlock()
+		return false
+	}
+
+	now := s.clock.Now()
+	var (
+		wait  time.Duration
+		taken []*bucket
+	)
+	// Спершу ліміт пари, потім отримувача: повідомлення має пройти обидва
+	for _, key := range []limitKey{{From: env.From, To: env.To}, {To: env.To}} {
+		b, ok := s.limits[key]
+		if !ok {
+			continue
+		}
+		w, ok := b.take(now)
+		if !ok {
+			// Повертаємо вже взяті токени - повідомлення не пройшло
+			for _, t := range taken {
+				t.tokens++
+			}
+			if s.metrics.Throttled == nil {
+				s.metrics.Throttled = make(map[string]uint64)
+			}
+			s.metrics.Throttled[env.To]++
+			policy := b.limit.Policy
+			if policy == LimitDelay {
+				policy = LimitDrop // Затримка була б завеликою
+			}
+			s.countLocked(policy)
+			s.limitMu.Unlock()
+			s.excess(env, policy)
+			return true
+		}
+		taken = append(taken, b)
+		wait = max(wait, w)
+	}
+
+	if wait == 0 {
+		s.limitMu.Unlock()
+		return false
+	}
+	s.countLocked(LimitDelay)
+	s.limitMu.Unlock()
+
+	s.afterFunc(wait, func() {
+		if err := s.redeliver(env); err != nil {
+			log.Printf("Delayed message to %s dropped: %v", env.To, err)
+		}
+	})
+	return true
+}
+
+func (s *System) countLocked(p LimitPolicy) {
+	switch p {
+	case LimitDelay:
+		s.metrics.Delayed++
+	case LimitDrop:
+		s.metrics.Dropped++
+	case LimitDeadLetter:
+		s.metrics.DeadLettered++
+	case LimitRefuse:
+		s.metrics.Refused++
+	}
+}
+
+// excess застосовує політику до повідомлення понад ліміт.
+func (s *System) excess(env Envelope, p LimitPolicy) {
+	switch p {
+	case LimitDeadLetter:
+		s.deadLetter(env, ReasonThrottled)
+	case LimitRefuse:
+		// Як і refuse() правил доступу: відповідаємо лише справжньому відправнику (Sender),
+		// не відповідаємо на відмови, і не чекаємо на місце в скриньці відправника:
+		// відправник може саме зараз виконувати цю дію у власній горутині.
+		// Відповідь іде звичайним шляхом (TrySend), тож знаходить і агентів підсистем.
+		to := env.Sender
+		if env.Type == Refuse || env.Type == NotUnderstood || to == "" {
+			return
+		}
+		ctx := WithMetadata(WithPerformative(s.ctx, Refuse), env.Metadata)
+		if err := s.TrySend(ctx, env.To, to, env.Payload); err != nil {
+			log.Printf("Refuse to %s not delivered: %v", to, err)
+		}
+	}
+}
+
+// deadLetter передає конверт агенту DeadLettersID (або логує, якщо його немає).
+func (s *System) deadLetter(env Envelope, reason string) {
+	if env.To == DeadLettersID {
+		// Ліміт на самому DeadLettersID: не зациклюємось, лише логуємо
+		log.Printf("Dead letter %s -> %s (%s): %+v", env.From, env.To, reason, env.Payload)
+		return
+	}
+	dl := DeadLetterMsg{Envelope: env, Reason: reason}
+	if err := s.TrySend(s.ctx, env.From, DeadLettersID, dl); err != nil {
+		log.Printf("Dead letter %s -> %s (%s): %+v (%v)", env.From, env.To, reason, env.Payload, err)
+	}
+}
+
+// redeliver доставляє готовий конверт без перевірки лімітів (відкладена доставка).
+func (s *System) redeliver(env Envelope) error {
+	if s.dispatch != nil {
+		return s.dispatch(s.ctx, env)
+	}
+
+	s.mu.RLock()
+	c, exists := s.registry[env.To]
+	s.mu.RUnlock()
+	if !exists {
+		return fmt.Errorf("agent '%s' not found", env.To)
+	}
+
+	select {
+	case c.inbox <- env:
+		s.notifyTap(Traffic{Envelope: env})
+		return nil
+	case <-s.ctx.Done():
+		return fmt.Errorf("system is shutting down")
+	}
+}
+
+func init() {
+	gob.Register(DeadLetterMsg{})
+}
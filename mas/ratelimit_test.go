@@ -0,0 +1,78 @@
+package mas
+
+import (
+	"context"
+	"testing"
+)
+
+// burstAgent на "GO" шле по два повідомлення кожному з to, а решту складає в got.
+type burstAgent struct {
+	inboxAgent
+	to []string
+}
+
+func (a *burstAgent) Plan(ctx context.Context, msg Envelope) ([]Action, error) {
+	if msg.Payload != "GO" {
+		return a.inboxAgent.Plan(ctx, msg)
+	}
+	var out []Action
+	for _, to := range a.to {
+		out = append(out, Send(to, "PING"), Send(to, "PING"))
+	}
+	return out, nil
+}
+
+func TestThrottleRepliesReachSubsystems(t *testing.T) {
+	sys := NewSystem(WithRateLimit("target", Limit{Rate: 0.001, Burst: 1, Policy: LimitRefuse}))
+	defer sys.Shutdown(context.Background())
+	sub := sys.CreateSubsystem()
+	sub.SetRateLimit("quiet", Limit{Rate: 0.001, Burst: 1, Policy: LimitDeadLetter})
+
+	dead := newInboxAgent(DeadLettersID)
+	target := newInboxAgent("target")
+	sys.Spawn(dead)
+	sys.Spawn(target)
+	quiet := newInboxAgent("quiet")
+	client := &burstAgent{inboxAgent: *newInboxAgent("client"), to: []string{"target", "quiet"}}
+	sub.Spawn(quiet)
+	sub.Spawn(client)
+
+	sub.Send(context.Background(), "main", "client", "GO")
+
+	// Відмова знаходить відправника в підсистемі
+	expectOne(t, target.got)
+	refuse := expectOne(t, client.got)
+	if refuse.Type != Refuse || refuse.From != "target" || refuse.Payload != "PING" {
+		t.Fatalf("reply %+v, want REFUSE PING from target", refuse)
+	}
+
+	// Недоставлене в підсистемі потрапляє до DeadLettersID батьківської системи
+	expectOne(t, quiet.got)
+	dl, ok := expectOne(t, dead.got).Payload.(DeadLetterMsg)
+	if !ok || dl.Reason != ReasonThrottled || dl.Envelope.To != "quiet" || dl.Envelope.From != "client" {
+		t.Fatalf("dead letter %+v", dl)
+	}
+
+	expectNone(t, target.got)
+	expectNone(t, quiet.got)
+	expectNone(t, client.got)
+}
+
+func TestThrottleRefuseIgnoresSpoofedFrom(t *testing.T) {
+	sys := NewSystem(WithRateLimit("target", Limit{Rate: 0.001, Burst: 1, Policy: LimitRefuse}))
+	defer sys.Shutdown(context.Background())
+	target := newInboxAgent("target")
+	victim := newInboxAgent("victim")
+	sys.Spawn(target)
+	sys.Spawn(victim)
+
+	// Анонімний код ззовні вписує чужий From: відмова нікому не йде
+	for range 3 {
+		sys.Send(context.Background(), "victim", "target", "PING")
+	}
+	expectOne(t, target.got)
+	expectNone(t, victim.got)
+	if m := sys.Metrics(); m.Refused != 2 {
+		t.Fatalf("refused %d, want 2", m.Refused)
+	}
+}
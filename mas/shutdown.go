@@ -0,0 +1,304 @@
+package mas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrShuttingDown - система завершує роботу: повідомлення ззовні більше не приймаються.
+var ErrShuttingDown = errors.New("system is shutting down")
+
+// DefaultShutdownTimeout - скільки Shutdown чекає агентів, якщо в ctx немає дедлайну.
+const DefaultShutdownTimeout = 30 * time.Second
+
+// WithShutdownTimeout задає, скільки Shutdown чекає агентів, якщо в ctx немає дедлайну
+// (типово DefaultShutdownTimeout; 0 - чекати, доки не затихнуть, хоч і вічно).
+func WithShutdownTimeout(d time.Duration) Option {
+	return func(s *System) {
+		s.shutdownTimeout = d
+	}
+}
+
+// Фази Shutdown (System.phase). Діють і на підсистеми.
+const (
+	phaseRunning       int32 = iota
+	phaseTimersStopped       // Таймери (SendAfter, таймери світу, відкладені ліміти) не спрацьовують
+	phaseClosed              // Send не з горутини агента відхиляється з ErrShuttingDown
+)
+
+// busy - повідомлення, яке агент зараз обробляє (для звіту Shutdown).
+type busy struct {
+	msg   Envelope
+	since time.Time
+}
+
+// StuckAgent - агент, що не зупинився до дедлайну Shutdown.
+type StuckAgent struct {
+	ID      string
+	Message *Envelope     // Що обробляв (nil - був не в обробці повідомлення, наприклад, у власному Run)
+	For     time.Duration // Скільки часу вже обробляв
+}
+
+func (a StuckAgent) String() string {
+	if a.Message == nil {
+		return a.ID + " (not processing a message)"
+	}
+	return fmt.Sprintf("%s (processing %v from %s for %v)", a.ID, a.Message.Payload, a.Message.From, a.For.Round(time.Millisecond))
+}
+
+// ShutdownError - Shutdown не дочекався агентів.
+// errors.Is(err, context.DeadlineExceeded) працює через Unwrap.
+type ShutdownError struct {
+	Stuck []StuckAgent
+	Err   error // Чому перестали чекати (ctx.Err())
+}
+
+func (e *ShutdownError) Error() string {
+	names := make([]string, len(e.Stuck))
+	for i, a := range e.Stuck {
+		names[i] = a.String()
+	}
+	return fmt.Sprintf("shutdown: %d agent(s) did not stop: %s", len(e.Stuck), strings.Join(names, ", "))
+}
+
+func (e *ShutdownError) Unwrap() error { return e.Err }
+
+// Shutdown зупиняє систему і зберігає світ (WithPersistence). Фази:
+//  1. таймери (SendAfter, таймери світу, відкладені ліміти) більше не спрацьовують;
+//  2. Send ззовні (консолі, шлюз, main) відхиляється з ErrShuttingDown,
+//     агенти ж і далі пишуть одне одному як завжди;
+//  3. Shutdown чекає, доки скриньки агентів системи та підсистем спорожніють
+//     і ніхто нічого не обробляє (відкладені Stash повідомлення не чекаються);
+//  4. контексти агентів і підсистем скасовуються, агенти дочитують те,
+//     що встигло прийти, і завершуються;
+//  5. світ записується у файл.
+//
+// Якщо ctx закінчиться раніше, ніж зупиняться всі агенти, Shutdown повертає
+// *ShutdownError зі списком агентів і повідомлень, на яких вони зависли.
+// Світ тоді НЕ зберігається: агенти, що не зупинились, ще змінюють свій стан,
+// і записати його без гонок неможливо - у файлі лишається попередній світ.
+// Без дедлайну в ctx Shutdown чекає не довше за WithShutdownTimeout
+// (типово DefaultShutdownTimeout): агенти, що безкінечно пишуть одне одному,
+// інакше ніколи не дали б йому завершитися.
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+//	defer cancel()
+//	if err := sys.Shutdown(ctx); err != nil {
+//		log.Println(err)
+//	}
+func (s *System) Shutdown(ctx context.Context) error {
+	log.Println("System begin Shutdown")
+	if _, ok := ctx.Deadline(); !ok && s.shutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.shutdownTimeout)
+		defer cancel()
+	}
+
+	// 1-2. Нових повідомлень від таймерів і ззовні більше не буде
+	s.phase.Store(phaseTimersStopped)
+	s.phase.Store(phaseClosed)
+
+	// 3. Агенти дообробляють те, що вже в скриньках, і все, що напишуть одне одному
+	s.waitIdle(ctx)
+
+	// 4. Зупинка всіх процесів (і підсистем): агенти дочитують inbox (drainInbox)
+	s.cancelAll()
+	if stuck := s.waitAgents(ctx); len(stuck) > 0 {
+		err := &ShutdownError{Stuck: stuck, Err: ctx.Err()}
+		log.Println(err)
+		if len(s.filename) > 0 {
+			log.Printf("World is not saved to %s: agents are still running", s.filename)
+		}
+		return err
+	}
+
+	if len(s.filename) == 0 {
+		return nil
+	}
+
+	// 5. Запис у файл
+	return s.persist()
+}
+
+// idlePoll - як часто Shutdown перевіряє, чи затихли агенти.
+const idlePoll = 5 * time.Millisecond
+
+// waitIdle чекає, доки всі скриньки порожні і ніхто нічого не обробляє, або до кінця ctx
+// (Shutdown ставить йому дедлайн, див. WithShutdownTimeout).
+// Тиша має протриматися дві перевірки поспіль: агент, що щойно взяв повідомлення
+// зі скриньки, ще не встиг позначити себе зайнятим.
+func (s *System) waitIdle(ctx context.Context) {
+	ticker := time.NewTicker(idlePoll)
+	defer ticker.Stop()
+
+	handled, quiet := s.activity()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		next, nowQuiet := s.activity()
+		if quiet && nowQuiet && next == handled {
+			return
+		}
+		handled, quiet = next, nowQuiet
+	}
+}
+
+// activity - скільки повідомлень оброблено агентами системи та підсистем
+// і чи всі вони зараз без роботи.
+func (s *System) activity() (handled uint64, quiet bool) {
+	quiet = true
+	for _, rc := range s.running() {
+		handled += rc.c.handled.Load()
+		if len(rc.c.inbox) > 0 || rc.c.busy.Load() != nil {
+			quiet = false
+		}
+	}
+	return handled, quiet
+}
+
+// cancelAll скасовує контексти системи та всіх підсистем.
+func (s *System) cancelAll() {
+	s.cancel()
+
+	s.mu.RLock()
+	subs := slices.Clone(s.subsystems)
+	s.mu.RUnlock()
+	for _, sub := range subs {
+		sub.cancelAll()
+	}
+}
+
+// persist записує світ у файл WithPersistence.
+func (s *System) persist() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	file, err := os.Create(s.filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return EncodeWorld(file, s.agents)
+}
+
+// closing - чи дійшов Shutdown цієї системи або її батьків до фази phase.
+func (s *System) closing(phase int32) bool {
+	for sys := s; sys != nil; sys = sys.parent {
+		if sys.phase.Load() >= phase {
+			return true
+		}
+	}
+	return false
+}
+
+// afterFunc - clock.AfterFunc, що не спрацьовує після початку Shutdown.
+func (s *System) afterFunc(d time.Duration, f func()) Timer {
+	return s.clock.AfterFunc(d, func() {
+		if s.closing(phaseTimersStopped) {
+			return
+		}
+		f()
+	})
+}
+
+// track запам'ятовує повідомлення, яке обробляє агент id; done - обробку завершено.
+func (s *System) track(id string, msg Envelope) (done func()) {
+	s.mu.RLock()
+	c := s.registry[id]
+	s.mu.RUnlock()
+
+	if c == nil {
+		return func() {}
+	}
+	c.busy.Store(&busy{msg: msg, since: s.clock.Now()})
+	return func() {
+		c.busy.Store(nil)
+		c.last.Store(s.clock.Now().UnixNano())
+		c.handled.Add(1)
+	}
+}
+
+// runningCell - агент, чий Run ще не завершився.
+type runningCell struct {
+	id string
+	c  *cell
+}
+
+// running - агенти системи та підсистем, чий Run ще не завершився.
+// Спершу свої: вони зупиняються першими і можуть писати в підсистеми.
+func (s *System) running() []runningCell {
+	s.mu.RLock()
+	var out []runningCell
+	for id, c := range s.registry {
+		select {
+		case <-c.done:
+		default:
+			out = append(out, runningCell{id: id, c: c})
+		}
+	}
+	subs := slices.Clone(s.subsystems)
+	s.mu.RUnlock()
+
+	slices.SortFunc(out, func(a, b runningCell) int { return strings.Compare(a.id, b.id) })
+	for _, sub := range subs {
+		out = append(out, sub.running()...)
+	}
+	return out
+}
+
+// waitAgents чекає, доки зупиняться всі агенти (і ті, що народилися під час дочитування).
+// Повертає тих, хто не зупинився до кінця ctx.
+func (s *System) waitAgents(ctx context.Context) []StuckAgent {
+	for {
+		pending := s.running()
+		if len(pending) == 0 {
+			return nil
+		}
+		select {
+		case <-pending[0].c.done:
+		case <-ctx.Done():
+			return s.stuckAfter(stopGrace)
+		}
+	}
+}
+
+// stopGrace - скільки після дедлайну чекати агентів без роботи: їм треба мить,
+// щоб помітити скасування, і у звіт Shutdown вони потрапляти не повинні.
+const stopGrace = 20 * time.Millisecond
+
+// stuckAfter дає агентам ще grace на зупинку і описує тих, хто так і не зупинився.
+func (s *System) stuckAfter(grace time.Duration) []StuckAgent {
+	deadline := time.After(grace)
+	for _, rc := range s.running() {
+		select {
+		case <-rc.c.done:
+		case <-deadline:
+			return s.stuckReport(s.running())
+		}
+	}
+	return s.stuckReport(s.running())
+}
+
+// stuckReport описує агентів, що не зупинились: що і як довго вони обробляють.
+func (s *System) stuckReport(cells []runningCell) []StuckAgent {
+	now := s.clock.Now()
+	out := make([]StuckAgent, len(cells))
+	for i, rc := range cells {
+		out[i] = StuckAgent{ID: rc.id}
+		if b := rc.c.busy.Load(); b != nil {
+			out[i].Message = &b.msg
+			out[i].For = now.Sub(b.since)
+		}
+	}
+	return out
+}
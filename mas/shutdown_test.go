@@ -0,0 +1,168 @@
+package mas
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// relayAgent на "GO" чекає і шле N повідомлень у to (більше, ніж вміщає скринька).
+type relayAgent struct {
+	BaseAgent
+	to    string
+	n     int
+	delay time.Duration
+}
+
+func (a *relayAgent) Plan(ctx context.Context, msg Envelope) ([]Action, error) {
+	if msg.Payload != "GO" {
+		return nil, nil
+	}
+	return []Action{func(ctx context.Context, _ Agent, sys *System) error {
+		time.Sleep(a.delay)
+		for i := range a.n {
+			if err := sys.Send(ctx, a.IDVal, a.to, i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}}, nil
+}
+
+// countAgent рахує повідомлення, обробляючи кожне не миттєво.
+type countAgent struct {
+	BaseAgent
+	got atomic.Int32
+}
+
+func (a *countAgent) Plan(ctx context.Context, msg Envelope) ([]Action, error) {
+	time.Sleep(100 * time.Microsecond)
+	a.got.Add(1)
+	return nil, nil
+}
+
+// blockAgent зависає в Plan, доки не закриють release.
+type blockAgent struct {
+	BaseAgent
+	release chan struct{}
+}
+
+func (a *blockAgent) Plan(ctx context.Context, msg Envelope) ([]Action, error) {
+	<-a.release
+	return nil, nil
+}
+
+func TestShutdownDrainsAgentTraffic(t *testing.T) {
+	sys := NewSystem()
+	sink := &countAgent{BaseAgent: BaseAgent{IDVal: "sink"}}
+	sys.Spawn(sink)
+	sys.Spawn(&relayAgent{BaseAgent: BaseAgent{IDVal: "relay"}, to: "sink", n: 500, delay: 20 * time.Millisecond})
+
+	if err := sys.Send(context.Background(), "main", "relay", "GO"); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := sys.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// Агенти писали одне одному і під час Shutdown, і скринька sink переповнювалась
+	if got := sink.got.Load(); got != 500 {
+		t.Fatalf("sink got %d of 500", got)
+	}
+
+	if err := sys.Send(context.Background(), "main", "sink", "late"); !errors.Is(err, ErrShuttingDown) {
+		t.Fatalf("send after Shutdown: %v, want ErrShuttingDown", err)
+	}
+}
+
+func TestShutdownStopsTimersAndSubsystems(t *testing.T) {
+	sys := NewSystem()
+	sub := sys.CreateSubsystem()
+	sink := &countAgent{BaseAgent: BaseAgent{IDVal: "sink"}}
+	sub.Spawn(sink)
+	sys.Spawn(&actionAgent{BaseAgent: BaseAgent{IDVal: "timer"}, act: SendAfter(30*time.Millisecond, "sink", "TICK")})
+
+	sys.Send(context.Background(), "main", "timer", "GO")
+	if err := sys.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if sub.Context().Err() == nil {
+		t.Fatal("subsystem is still running")
+	}
+	if len(sub.running()) != 0 {
+		t.Fatalf("subsystem agents still running: %v", sub.running())
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if got := sink.got.Load(); got != 0 {
+		t.Fatalf("timer fired after Shutdown (%d messages)", got)
+	}
+}
+
+func TestShutdownStuckAgentIsNotPersisted(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "world.gob")
+	sys := NewSystem(WithPersistence(file))
+	stuck := &blockAgent{BaseAgent: BaseAgent{IDVal: "stuck"}, release: make(chan struct{})}
+	defer close(stuck.release)
+	sys.Spawn(stuck)
+	sys.Spawn(&countAgent{BaseAgent: BaseAgent{IDVal: "idle"}})
+	sys.Send(context.Background(), "main", "stuck", "HANG")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err := sys.Shutdown(ctx)
+
+	var serr *ShutdownError
+	if !errors.As(err, &serr) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want *ShutdownError with DeadlineExceeded", err)
+	}
+	if len(serr.Stuck) != 1 || serr.Stuck[0].ID != "stuck" {
+		t.Fatalf("stuck = %v, want only 'stuck'", serr.Stuck)
+	}
+	if m := serr.Stuck[0].Message; m == nil || m.Payload != "HANG" {
+		t.Fatalf("stuck message = %+v", m)
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Fatalf("world saved while an agent is still running (stat: %v)", err)
+	}
+}
+
+// rallyAgent на кожне повідомлення відповідає peer: пара таких агентів не затихає ніколи.
+type rallyAgent struct {
+	BaseAgent
+	peer string
+}
+
+func (a *rallyAgent) Plan(ctx context.Context, msg Envelope) ([]Action, error) {
+	return []Action{Send(a.peer, msg.Payload)}, nil
+}
+
+func TestShutdownWithoutDeadlineIsBounded(t *testing.T) {
+	sys := NewSystem(WithShutdownTimeout(100 * time.Millisecond))
+	sys.Spawn(&rallyAgent{BaseAgent: BaseAgent{IDVal: "ping"}, peer: "pong"})
+	sys.Spawn(&rallyAgent{BaseAgent: BaseAgent{IDVal: "pong"}, peer: "ping"})
+	sys.Send(context.Background(), "main", "ping", "BALL")
+
+	done := make(chan struct{})
+	start := time.Now()
+	go func() {
+		sys.Shutdown(context.Background())
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Shutdown without a deadline did not return")
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Fatalf("Shutdown returned after %v, before the timeout", elapsed)
+	}
+	if len(sys.running()) != 0 {
+		t.Fatalf("agents still running: %v", sys.running())
+	}
+}
@@ -0,0 +1,102 @@
+package mas
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+)
+
+// Cloner - агент, який сам вміє зробити свою глибоку копію.
+// Потрібен, коли в стані є поля, які GOB не серіалізує (sync.Map, інтерфейси без реєстрації).
+type Cloner interface {
+	Clone() Agent
+}
+
+// inspectRequest - службовий конверт: виконати fn у горутині агента між повідомленнями.
+type inspectRequest struct {
+	fn   func(Agent)
+	done chan struct{}
+}
+
+// Inspect виконує fn у власній горутині агента, між обробкою повідомлень.
+// Поки fn працює, агент нічого не змінює, тож читати його стан безпечно.
+// fn не повинна зберігати посилання на агента - для цього є Snapshot.
+func (s *System) Inspect(id string, fn func(Agent)) error {
+	s.mu.RLock()
+	c, exists := s.registry[id]
+	agent := s.agents[id]
+	s.mu.RUnlock()
+
+	if !exists {
+		if fwd, moved := s.forwardFor(id); moved && fwd.sys != nil {
+			return fwd.sys.Inspect(id, fn)
+		}
+		if sub := s.subsystemOf(id); sub != nil {
+			return sub.Inspect(id, fn)
+		}
+		if s.parent != nil {
+			return s.parent.Inspect(id, fn)
+		}
+		return fmt.Errorf("inspect failed: agent '%s' not found", id)
+	}
+
+	// Синхронний режим (mastest): ми і так у "горутині агента"
+	if s.dispatch != nil {
+		fn(agent)
+		return nil
+	}
+
+	req := inspectRequest{fn: fn, done: make(chan struct{})}
+	select {
+	case c.inbox <- Envelope{To: id, Payload: req}:
+	case <-c.done:
+		return fmt.Errorf("inspect failed: agent '%s' stopped", id)
+	case <-s.ctx.Done():
+		return fmt.Errorf("system is shutting down")
+	}
+
+	select {
+	case <-req.done:
+		return nil
+	case <-c.done:
+		return fmt.Errorf("inspect failed: agent '%s' stopped", id)
+	case <-s.ctx.Done():
+		return fmt.Errorf("system is shutting down")
+	}
+}
+
+// Snapshot повертає глибоку копію агента, зроблену між повідомленнями.
+// Копію можна читати з будь-якої горутини (UI, інструменти).
+func (s *System) Snapshot(id string) (Agent, error) {
+	var (
+		copied Agent
+		err    error
+	)
+	if ierr := s.Inspect(id, func(a Agent) {
+		copied, err = CloneAgent(a)
+	}); ierr != nil {
+		return nil, ierr
+	}
+	return copied, err
+}
+
+// CloneAgent робить глибоку копію: через Cloner, якщо агент його реалізує,
+// інакше через GOB (тип має бути зареєстрований gob.Register).
+func CloneAgent(a Agent) (Agent, error) {
+	if c, ok := a.(Cloner); ok {
+		return c.Clone(), nil
+	}
+
+	// Обгортка потрібна, щоб GOB записав конкретний тип за інтерфейсом
+	type box struct{ A Agent }
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(box{A: a}); err != nil {
+		return nil, fmt.Errorf("clone %s: %w", a.ID(), err)
+	}
+	var out box
+	if err := gob.NewDecoder(&buf).Decode(&out); err != nil {
+		return nil, fmt.Errorf("clone %s: %w", a.ID(), err)
+	}
+	return out.A, nil
+}
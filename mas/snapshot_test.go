@@ -0,0 +1,48 @@
+package mas
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestSnapshotIsConsistentCopy(t *testing.T) {
+	sys := NewSystem()
+	defer sys.Shutdown(context.Background())
+	sub := sys.CreateSubsystem()
+	picky := &pickyAgent{BaseAgent: BaseAgent{IDVal: "picky"}}
+	sub.Spawn(picky)
+
+	go func() {
+		for i := range 200 {
+			sys.Send(context.Background(), "main", "picky", fmt.Sprint(i))
+		}
+	}()
+
+	// Читаємо стан, поки агент його змінює (-race не повинен нічого знайти)
+	var last []string
+	for len(last) < 200 {
+		a, err := sys.Snapshot("picky") // Агент живе в підсистемі
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen := a.(*pickyAgent).Seen
+		if len(seen) < len(last) || !slices.Equal(seen[:len(last)], last) {
+			t.Fatalf("snapshot went back: %d after %d messages", len(seen), len(last))
+		}
+		last = slices.Clone(seen)
+		if len(seen) > 0 {
+			seen[0] = "changed" // Копія не ділить пам'ять з агентом
+		}
+	}
+	sub.Inspect("picky", func(a Agent) {
+		if a.(*pickyAgent).Seen[0] != "0" {
+			t.Error("changing a snapshot changed the agent")
+		}
+	})
+
+	if _, err := sys.Snapshot("ghost"); err == nil {
+		t.Fatal("snapshot of a missing agent")
+	}
+}
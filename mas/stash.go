@@ -0,0 +1,113 @@
+package mas
+
+import (
+	"context"
+	"log"
+)
+
+// MaxStash - скільки повідомлень агент може відкласти. Найстаріші понад ліміт губляться.
+const MaxStash = 1000
+
+// AcceptOnly обмежує, які повідомлення агент приймає зараз (selective receive).
+// Решта відкладається (stash) і повертається пізніше в порядку надходження,
+// коли фільтр їх пропустить (AcceptAll або новий AcceptOnly).
+func (b *BaseAgent) AcceptOnly(ms ...Matcher) {
+	b.accept = AnyOf(ms...)
+	b.filterChanged = true
+}
+
+// AcceptAll знімає фільтр: всі відкладені повідомлення будуть оброблені (unstashAll).
+func (b *BaseAgent) AcceptAll() {
+	b.accept = nil
+	b.filterChanged = true
+}
+
+// Stashed повертає кількість відкладених повідомлень.
+func (b *BaseAgent) Stashed() int {
+	return len(b.stash)
+}
+
+// selective - агент з вибірковим отриманням (BaseAgent): Migrate переносить його stash.
+type selective interface {
+	stashState() (Matcher, []Envelope)
+	restoreStash(accept Matcher, stash []Envelope)
+}
+
+func (b *BaseAgent) stashState() (Matcher, []Envelope) { return b.accept, b.stash }
+
+func (b *BaseAgent) restoreStash(accept Matcher, stash []Envelope) {
+	b.accept, b.stash = accept, stash
+}
+
+// stashOf повертає фільтр і відкладені повідомлення зупиненого агента.
+func stashOf(a Agent) (Matcher, []Envelope) {
+	if sel, ok := a.(selective); ok {
+		return sel.stashState()
+	}
+	return nil, nil
+}
+
+func (b *BaseAgent) accepts(msg Envelope) bool {
+	return b.accept == nil || b.accept(msg)
+}
+
+func (b *BaseAgent) stashMessage(msg Envelope) {
+	if len(b.stash) >= MaxStash {
+		log.Printf("Agent %s stash overflow, dropping %+v", b.IDVal, b.stash[0].Payload)
+		b.stash = b.stash[1:]
+	}
+	b.stash = append(b.stash, msg)
+}
+
+// replayStash повертає відкладені повідомлення, якщо під час обробки змінився фільтр.
+// Обробка одного з них може знову змінити фільтр, тому проходимо, доки він не стабілізується.
+func (b *BaseAgent) replayStash(ctx context.Context) {
+	for b.filterChanged && len(b.stash) > 0 {
+		b.filterChanged = false
+
+		pending := b.stash
+		b.stash = nil
+		for _, msg := range pending {
+			if b.accepts(msg) {
+				b.handleMessage(ctx, msg)
+			} else {
+				b.stash = append(b.stash, msg)
+			}
+		}
+	}
+}
+
+// --- Дії ---
+
+// AcceptOnly - дія: з наступного повідомлення приймати лише те, що пройде шаблони.
+func AcceptOnly(ms ...Matcher) Action {
+	return func(ctx context.Context, a Agent, sys *System) error {
+		if s, ok := a.(interface{ AcceptOnly(...Matcher) }); ok {
+			s.AcceptOnly(ms...)
+		}
+		return nil
+	}
+}
+
+// AcceptAll - дія: приймати все і повернути відкладені повідомлення.
+func AcceptAll() Action {
+	return func(ctx context.Context, a Agent, sys *System) error {
+		if s, ok := a.(interface{ AcceptAll() }); ok {
+			s.AcceptAll()
+		}
+		return nil
+	}
+}
+
+// Stash - дія: відкласти поточне повідомлення (як stash() в Akka).
+// Воно повернеться, коли агент змінить фільтр (AcceptAll - аналог unstashAll).
+func Stash() Action {
+	return func(ctx context.Context, a Agent, sys *System) error {
+		if s, ok := a.(interface{ stashCurrentMessage() }); ok {
+			s.stashCurrentMessage()
+		}
+		return nil
+	}
+}
+
+func (b *BaseAgent) stashCurrentMessage() { b.stashCurrent = true }
@@ -3,21 +3,74 @@ package mas
 import (
 	"context"
 	"encoding/gob"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"maps"
 	"os"
+	"slices"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// cell - рантайм-інфраструктура одного агента, яку GOB не зберігає.
+type cell struct {
+	inbox   chan Envelope
+	cancel  context.CancelCauseFunc // Зупиняє саме цього агента (Stop, Migrate)
+	done    chan struct{}           // Закривається, коли Run агента завершився
+	busy    atomic.Pointer[busy]    // Що агент зараз обробляє (звіт Shutdown, Health)
+	last    atomic.Int64            // Коли агент востаннє закінчив обробку (UnixNano; спершу - запуск)
+	handled atomic.Uint64           // Скільки повідомлень оброблено (Shutdown чекає тиші)
+
+	restarts int // Скільки разів агента перезапускав Restart
+}
+
+// ErrMailboxFull - у поштовій скриньці отримувача немає місця (TrySend, SendWithTimeout).
+var ErrMailboxFull = errors.New("mailbox is full")
+
 type System struct {
 	mu       sync.RWMutex
-	agents   map[string]Agent         // Тут живуть типи
-	registry map[string]chan Envelope // Тут живуть канали (runtime)
+	agents   map[string]Agent // Тут живуть типи
+	registry map[string]*cell // Тут живуть канали та керування горутинами (runtime)
+
+	parent     *System
+	subsystems []*System // Дочірні системи (CreateSubsystem): Send знаходить їхніх агентів
 
-	parent *System
+	forwards map[string]forward // Адреси агентів, що переїхали (Migrate, Export)
 
 	filename string // Куди зберігати dump
 
+	watchMu  sync.Mutex
+	watchers []*watcher   // Підписки на зміни стану (Watch)
+	taps     []*tap       // Слухачі трафіку (Tap)
+	hooks    []*hookEntry // Хуки обробки повідомлень (Hook)
+
+	topicMu sync.Mutex
+	topics  map[string][]string // Підписники тем (Subscribe/Publish)
+
+	limitMu sync.Mutex
+	limits  map[limitKey]*bucket // Ліміти швидкості (SetRateLimit)
+	metrics Metrics
+
+	aclMu sync.RWMutex
+	rules []Rule // Правила доступу (Allow/Deny)
+
+	trusted map[string]*credentialKey // Довірені ідентичності (WithTrustedIdentity); після створення не змінюються
+	sealed  bool                      // Опції застосовано - довіру вже не додати
+
+	clock    Clock      // Джерело часу (таймери SendAfter)
+	dispatch Dispatcher // Якщо задано - синхронна доставка без горутин (mastest)
+
+	handleTimeout time.Duration // Тайм-аут обробки одного повідомлення (WithHandleTimeout)
+	stallTimeout  time.Duration // Скільки скринька може не розбиратися (WithStallTimeout)
+
+	shutdownTimeout time.Duration // Межа Shutdown без дедлайну в ctx (WithShutdownTimeout)
+
+	phase atomic.Int32 // Фаза Shutdown (phaseRunning...)
+
 	ctx    context.Context
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
@@ -41,6 +94,26 @@ func WithContext(ctx context.Context) Option {
 	}
 }
 
+// Dispatcher перехоплює доставку конвертів.
+// Використовується тестовим harness-ом (mastest) для синхронного режиму.
+type Dispatcher func(ctx context.Context, env Envelope) error
+
+// WithClock підміняє джерело часу (наприклад, фейковий годинник у тестах).
+func WithClock(c Clock) Option {
+	return func(s *System) {
+		s.clock = c
+	}
+}
+
+// WithDispatcher вмикає синхронний режим: агенти не отримують власних горутин
+// та inbox-ів, а кожен Send передає готовий конверт у d.
+// Хто і коли викличе обробку (BaseAgent.Deliver) - вирішує d.
+func WithDispatcher(d Dispatcher) Option {
+	return func(s *System) {
+		s.dispatch = d
+	}
+}
+
 // NewSystem створює новий екземпляр системи.
 // Приймає список опцій для конфігурації.
 func NewSystem(opts ...Option) *System {
@@ -49,16 +122,21 @@ func NewSystem(opts ...Option) *System {
 
 	s := &System{
 		agents:   make(map[string]Agent),
-		registry: make(map[string]chan Envelope),
+		registry: make(map[string]*cell),
+		forwards: make(map[string]forward),
 		//filename: "mas_state.gob", // Дефолтне ім'я файлу
-		ctx:    defaultCtx,
-		cancel: defaultCancel,
+		clock:           realClock{},
+		stallTimeout:    DefaultStallTimeout,
+		shutdownTimeout: DefaultShutdownTimeout,
+		ctx:             defaultCtx,
+		cancel:          defaultCancel,
 	}
 
 	// 2. Застосування опцій користувача
 	for _, opt := range opts {
 		opt(s)
 	}
+	s.sealed = true
 
 	return s
 }
@@ -68,15 +146,26 @@ func (s *System) CreateSubsystem(opts ...Option) *System {
 	defaultCtx, defaultCancel := context.WithCancel(context.Background())
 	ss := &System{
 		agents:   make(map[string]Agent),
-		registry: make(map[string]chan Envelope),
+		registry: make(map[string]*cell),
+		forwards: make(map[string]forward),
 		parent:   s, // Запам'ятовуємо, хто створив
-		ctx:      defaultCtx,
-		cancel:   defaultCancel,
+		clock:    s.clock,
+		// Тайм-аути здоров'я (Health) успадковуються
+		handleTimeout:   s.handleTimeout,
+		stallTimeout:    s.stallTimeout,
+		shutdownTimeout: s.shutdownTimeout,
+		ctx:             defaultCtx,
+		cancel:          defaultCancel,
 	}
 	// 2. Застосування опцій користувача
 	for _, opt := range opts {
 		opt(ss)
 	}
+	ss.sealed = true
+
+	s.mu.Lock()
+	s.subsystems = append(s.subsystems, ss)
+	s.mu.Unlock()
 
 	return ss
 }
@@ -85,6 +174,11 @@ func (s *System) Context() context.Context {
 	return s.ctx
 }
 
+// Clock повертає джерело часу системи.
+func (s *System) Clock() Clock {
+	return s.clock
+}
+
 // Startup - завантаження світу
 func (s *System) Startup() error {
 
@@ -104,62 +198,63 @@ func (s *System) Startup() error {
 	defer file.Close()
 
 	// 1. Магія GOB: відновлюємо карту інтерфейсів
-	// Важливо: типи агентів мають бути зареєстровані через gob.Register() у init()
-	decoder := gob.NewDecoder(file)
-	if err := decoder.Decode(&s.agents); err != nil {
+	// Важливо: типи агентів мають бути зареєстровані (mas.RegisterType або gob.Register) у init()
+	agents, err := DecodeWorld(file)
+	if err != nil {
 		return err
 	}
+	s.agents = agents
 	log.Println("Resurrection")
 	// 2. Оживлення (Resurrection)
 	for id, agent := range s.agents {
 		log.Println(agent.ID())
-		// Створюємо інфраструктуру, яку GOB не зберіг
-		inbox := make(chan Envelope, 100)
-		s.registry[id] = inbox
-
-		// Прив'язуємо агента до системи
-		agent.Bind(s, inbox, agent)
-
-		// Запускаємо
-		s.wg.Add(1)
-		go func(a Agent) {
-			defer s.wg.Done()
-			a.Run(s.ctx)
-		}(agent)
+		// Створюємо інфраструктуру, яку GOB не зберіг, і запускаємо
+		s.start(id, agent)
 	}
 
 	return nil
 }
 
-// Shutdown - збереження світу
-func (s *System) Shutdown() error {
-
-	log.Println("System begin Shutdown")
-	// 1. Зупинка всіх процесів
-	s.cancel()
-	s.wg.Wait()
-
-	if len(s.filename) == 0 {
-		return nil
+// DecodeWorld читає світ, збережений Shutdown (для інструментів на кшталт masctl).
+// Типи агентів мають бути зареєстровані (RegisterType або gob.Register), інакше - помилка GOB з іменем типу.
+func DecodeWorld(r io.Reader) (map[string]Agent, error) {
+	var agents map[string]Agent
+	if err := gob.NewDecoder(r).Decode(&agents); err != nil {
+		return nil, err
 	}
-
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	// 2. Запис у файл
-	file, err := os.Create(s.filename)
-	if err != nil {
-		return err
+	if agents == nil {
+		agents = make(map[string]Agent)
 	}
-	defer file.Close()
+	return agents, nil
+}
 
-	encoder := gob.NewEncoder(file)
+// EncodeWorld пише світ у форматі Shutdown.
+func EncodeWorld(w io.Writer, agents map[string]Agent) error {
 	// Ми просто пишемо всю мапу агентів.
 	// GOB сам збереже конкретні структури, сховані за інтерфейсом Agent.
-	return encoder.Encode(s.agents)
+	return gob.NewEncoder(w).Encode(agents)
 }
 
+// GetAgent шукає агента так само, як Send: у системі, за адресою переїзду,
+// у підсистемах і в батьківській системі.
 func (s *System) GetAgent(id string) (Agent, bool) {
+	if agent, exists := s.localAgent(id); exists {
+		return agent, true
+	}
+	if fwd, moved := s.forwardFor(id); moved && fwd.sys != nil {
+		return fwd.sys.GetAgent(id)
+	}
+	if sub := s.subsystemOf(id); sub != nil {
+		return sub.localAgent(id)
+	}
+	if s.parent != nil {
+		return s.parent.GetAgent(id)
+	}
+	return nil, false
+}
+
+// localAgent - агент саме цієї системи (без підсистем і батька).
+func (s *System) localAgent(id string) (Agent, bool) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -182,36 +277,70 @@ func (s *System) Spawn(agent Agent) error {
 
 	agent.SetSystem(s)
 
+	// s.agents потрібен для GOB-серіалізації (Shutdown) та GetAgent
+	s.agents[id] = agent
+
+	// 2-5. Транспорт, реєстрація, прив'язка та запуск
+	s.start(id, agent)
+
+	return nil
+}
+
+// start створює інфраструктуру агента і запускає його цикл обробки.
+// Викликається під s.mu.Lock().
+func (s *System) start(id string, agent Agent) {
+	s.startWith(id, agent, make(chan Envelope, 100))
+}
+
+// startWith - start з готовим inbox (Migrate передає агенту його стару скриньку).
+func (s *System) startWith(id string, agent Agent, inbox chan Envelope) {
+	// Агент знову живе тут - стара адреса переїзду більше не потрібна
+	delete(s.forwards, id)
+
 	// 2. Ініціалізація інфраструктури (Транспорт)
 	// Створюємо буферизований канал. Розмір буфера (100) можна винести в конфіг,
 	// але для MVP це нормальне значення, щоб згладжувати пікові навантаження.
-	inbox := make(chan Envelope, 100)
+	// Кожен агент отримує власний контекст, щоб його можна було зупинити окремо (Stop);
+	// контекст підписаний ID агента - так System знає відправника його повідомлень.
+	ctx, cancel := context.WithCancelCause(withAgent(s.ctx, id))
+	if s.dispatch != nil {
+		// Синхронний режим: без inbox і горутини, доставкою керує Dispatcher
+		c := &cell{cancel: cancel, done: make(chan struct{})}
+		close(c.done)
+		s.registry[id] = c
+		agent.Bind(s, nil, agent)
+		return
+	}
+
+	c := &cell{
+		inbox:  inbox,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	c.last.Store(s.clock.Now().UnixNano())
 
 	// 3. Реєстрація
 	// s.registry потрібен для маршрутизації (Send)
-	s.registry[id] = inbox
-	// s.agents потрібен для GOB-серіалізації (Shutdown) та GetAgent
-	s.agents[id] = agent
+	s.registry[id] = c
 
 	// 4. Прив'язка (Binding)
 	// Впроваджуємо залежності (Dependency Injection) в структуру агента.
 	// Це наповнює приватні поля (sys, inbox), які GOB ігнорує.
-	agent.Bind(s, inbox, agent)
+	agent.Bind(s, c.inbox, agent)
 
 	// 5. Запуск (Execution)
 	s.wg.Add(1)
 	go func() {
 		defer s.wg.Done() // Сигналізуємо про завершення при виході
+		defer close(c.done)
 
-		// Запускаємо Run із системним контекстом.
-		// Якщо s.Shutdown() скасує контекст, агент отримає сигнал ctx.Done()
-		if err := agent.Run(s.ctx); err != nil {
+		// Запускаємо Run із контекстом агента (дочірнім від системного).
+		// Якщо s.Shutdown() або s.Stop() скасує контекст, агент отримає сигнал ctx.Done()
+		if err := agent.Run(ctx); err != nil {
 			// Тут можна підключити системний логер помилок
 			// fmt.Printf("Agent %s crashed: %v\n", id, err)
 		}
 	}()
-
-	return nil
 }
 
 // Send відправляє повідомлення від одного агента іншому.
@@ -224,45 +353,202 @@ func (s *System) Spawn(agent Agent) error {
 //	toID    - ID отримувача.
 //	payload - Корисне навантаження (суть задачі).
 func (s *System) Send(ctx context.Context, fromID, toID string, payload any) error {
+	return s.send(ctx, fromID, toID, payload, nil)
+}
+
+// TrySend - неблокуюча відправка: якщо в поштовій скриньці немає місця,
+// одразу повертає помилку з ErrMailboxFull (перевіряйте через errors.Is).
+// Підходить для таймерів і тиків, які не страшно пропустити.
+func (s *System) TrySend(ctx context.Context, fromID, toID string, payload any) error {
+	return s.send(ctx, fromID, toID, payload, expired)
+}
+
+// SendWithTimeout чекає на місце в скриньці не довше за timeout.
+// На відміну від context.WithTimeout, тайм-аут стосується лише самої відправки
+// і не стає дедлайном повідомлення (Envelope.Deadline).
+func (s *System) SendWithTimeout(ctx context.Context, fromID, toID string, payload any, timeout time.Duration) error {
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	return s.send(ctx, fromID, toID, payload, t.C)
+}
+
+// Backpressure повертає заповненість поштової скриньки агента (0.0 - порожня, 1.0 - повна).
+// Продюсери (наприклад, ManagerBot) можуть пригальмовувати, коли значення велике.
+func (s *System) Backpressure(id string) (float64, bool) {
+	s.mu.RLock()
+	c, exists := s.registry[id]
+	s.mu.RUnlock()
+
+	if !exists {
+		if fwd, moved := s.forwardFor(id); moved && fwd.sys != nil {
+			return fwd.sys.Backpressure(id)
+		}
+		if s.parent != nil {
+			return s.parent.Backpressure(id)
+		}
+		return 0, false
+	}
+	if cap(c.inbox) == 0 {
+		return 0, true // Синхронний режим: скриньки немає
+	}
+	return float64(len(c.inbox)) / float64(cap(c.inbox)), true
+}
+
+// expired - завжди закритий канал: "чекати не можна" для TrySend.
+var expired = func() <-chan time.Time {
+	ch := make(chan time.Time)
+	close(ch)
+	return ch
+}()
+
+// send - спільна реалізація Send/TrySend/SendWithTimeout.
+// expire == nil означає "чекати, скільки дозволить ctx".
+func (s *System) send(ctx context.Context, fromID, toID string, payload any, expire <-chan time.Time) error {
+	// Під час Shutdown пишуть лише агенти: консолі, шлюз і main уже відрізані
+	if s.closing(phaseClosed) && !fromAgent(ctx) {
+		return fmt.Errorf("send to '%s': %w", toID, ErrShuttingDown)
+	}
+
+	// 0. Синхронний режим (mastest): конверт іде прямо в Dispatcher
+	if s.dispatch != nil {
+		env := newEnvelope(ctx, fromID, toID, payload)
+		if err := s.authorize(env); err != nil {
+			return err
+		}
+		if s.throttle(env) {
+			return nil
+		}
+		if err := s.dispatch(ctx, env); err != nil {
+			return err
+		}
+		s.notifyTap(Traffic{Envelope: env})
+		return nil
+	}
+
 	// 1. Пошук адресата
 	// Використовуємо RLock, бо це операція читання, яка відбувається дуже часто.
 	s.mu.RLock()
-	ch, exists := s.registry[toID]
+	c, exists := s.registry[toID]
 	s.mu.RUnlock()
 
 	if !exists {
+		if fwd, moved := s.forwardFor(toID); moved {
+			return fwd.send(ctx, fromID, toID, payload, expire)
+		}
+		// Агент дочірньої системи (наприклад, лабіринт у вкладці лабораторії)
+		if sub := s.subsystemOf(toID); sub != nil {
+			return sub.send(ctx, fromID, toID, payload, expire)
+		}
 		if s.parent != nil {
-			return s.parent.Send(ctx, fromID, toID, payload)
+			return s.parent.send(ctx, fromID, toID, payload, expire)
 		} else {
 			return fmt.Errorf("send failed: agent '%s' not found", toID)
 		}
 	}
 
 	// 2. Формування конверта
-	env := Envelope{
-		From:    fromID,
-		To:      toID,
-		Payload: payload,
-		// Metadata можна додати тут, якщо потрібно (наприклад, timestamp)
+	// Метадані та дедлайн беремо з контексту відправника, щоб вони
+	// "текли" ланцюжком повідомлень (trace, тайм-аути).
+	env := newEnvelope(ctx, fromID, toID, payload)
+
+	// 2.1 Правила доступу: відправника визначає System, а не аргумент fromID
+	if err := s.authorize(env); err != nil {
+		return err
+	}
+
+	// 2.2 Ліміти швидкості: повідомлення може бути відкладене, викинуте або відхилене
+	if s.throttle(env) {
+		return nil
 	}
 
 	// 3. Доставка з урахуванням Backpressure (зворотного тиску)
+	// Спершу пробуємо без очікування: якщо місце є, доставляємо навіть тоді,
+	// коли контекст уже скасовано (наприклад, під час drainInbox).
+	select {
+	case c.inbox <- env:
+		s.notifyTap(Traffic{Envelope: env})
+		return nil
+	default:
+	}
+
 	select {
-	case ch <- env:
+	case c.inbox <- env:
 		// Успішно поклали в канал
 		//log.Println("Send Success:", env)
+		s.notifyTap(Traffic{Envelope: env})
 		return nil
 
+	case <-expire:
+		// TrySend або SendWithTimeout: місця так і не з'явилось
+		return fmt.Errorf("send to '%s' failed: %w", toID, ErrMailboxFull)
+
 	case <-ctx.Done():
-		// Відправник (caller) скасував операцію або вийшов час (timeout)
+		// Відправник (caller) скасував операцію або вийшов час (timeout, дедлайн конверта)
 		return fmt.Errorf("send canceled by caller: %w", ctx.Err())
 
 	case <-s.ctx.Done():
 		// Сама система вимикається, канали можуть бути вже закриті або неактивні
-		return fmt.Errorf("system is shutting down")
+		return fmt.Errorf("send to '%s': %w", toID, ErrShuttingDown)
 	}
 }
 
+// newEnvelope формує конверт, забираючи відправника, тип, метадані та дедлайн з контексту.
+func newEnvelope(ctx context.Context, fromID, toID string, payload any) Envelope {
+	// From ставить System: агентам і автентифікованому коду - їхню ідентичність;
+	// fromID лишається лише анонімному коду ззовні (тоді Sender порожній)
+	sender := IdentityFrom(ctx)
+	if sender != "" {
+		fromID = sender
+	}
+	env := Envelope{
+		From:     fromID,
+		To:       toID,
+		Sender:   sender,
+		ReplyTo:  replyToFrom(ctx),
+		Type:     performativeFrom(ctx),
+		Payload:  payload,
+		Metadata: MetadataFrom(ctx),
+	}
+	env.Deadline = envelopeDeadline(ctx)
+	return env
+}
+
+// subsystemOf шукає дочірню систему (на будь-якій глибині), де живе агент id.
+// Пошук іде лише вниз, тож не зациклюється з пересиланням до батька.
+func (s *System) subsystemOf(id string) *System {
+	s.mu.RLock()
+	subs := slices.Clone(s.subsystems)
+	s.mu.RUnlock()
+
+	for _, sub := range subs {
+		sub.mu.RLock()
+		_, exists := sub.registry[id]
+		sub.mu.RUnlock()
+		if exists {
+			return sub
+		}
+		if found := sub.subsystemOf(id); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// AgentIDs повертає ID усіх агентів системи та її підсистем (відсортовані).
+// Потрібен консолям: доповнення ID, розсилка "*".
+func (s *System) AgentIDs() []string {
+	s.mu.RLock()
+	ids := slices.Collect(maps.Keys(s.agents))
+	subs := slices.Clone(s.subsystems)
+	s.mu.RUnlock()
+
+	for _, sub := range subs {
+		ids = append(ids, sub.AgentIDs()...)
+	}
+	sort.Strings(ids)
+	return slices.Compact(ids)
+}
+
 // Kill примусово видаляє агента з системи (пам'яті та реєстру).
 // Корисно для тимчасових агентів (GUI, Debug), які не треба зберігати.
 func (s *System) Kill(id string) {
@@ -273,3 +559,64 @@ func (s *System) Kill(id string) {
 	delete(s.registry, id)
 	// Якщо треба, тут можна закрити канал inbox, але обережно
 }
+
+// SpawnChild запускає агента як дочірнього для parentID.
+// Зв'язок батько-дитина зберігається в самому агенті (BaseAgent.ParentID),
+// тому переживає Shutdown/Startup разом зі світом.
+func (s *System) SpawnChild(parentID string, child Agent) error {
+	p, ok := child.(interface{ SetParent(string) })
+	if !ok {
+		return fmt.Errorf("spawn failed: agent '%s' can't have a parent", child.ID())
+	}
+	if _, exists := s.localAgent(parentID); !exists {
+		return fmt.Errorf("spawn failed: parent '%s' not found", parentID)
+	}
+	p.SetParent(parentID)
+	return s.Spawn(child)
+}
+
+// Children повертає ID дочірніх агентів (відсортовані для стабільного виводу).
+func (s *System) Children(parentID string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.childrenLocked(parentID)
+}
+
+func (s *System) childrenLocked(parentID string) []string {
+	var ids []string
+	for id, agent := range s.agents {
+		if p, ok := agent.(interface{ Parent() string }); ok && p.Parent() == parentID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Stop зупиняє агента і всіх його нащадків та видаляє їх із системи.
+// Агент дочитує свій inbox (drainInbox) і завершується у власній горутині,
+// тому Stop можна безпечно викликати з дії (Action) самого агента.
+func (s *System) Stop(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.agents[id]; !exists {
+		return fmt.Errorf("stop failed: agent '%s' not found", id)
+	}
+	s.stopLocked(id)
+	return nil
+}
+
+func (s *System) stopLocked(id string) {
+	// Спочатку діти, щоб вони не лишились "сиротами"
+	for _, childID := range s.childrenLocked(id) {
+		s.stopLocked(childID)
+	}
+
+	if c, ok := s.registry[id]; ok {
+		c.cancel(nil)
+	}
+	delete(s.agents, id)
+	delete(s.registry, id)
+}
@@ -0,0 +1,138 @@
+package mas
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// fullAgent породжує агента, що завис на першому повідомленні, і заповнює його скриньку.
+// Закрийте release, щоб відпустити його.
+func fullAgent(t *testing.T, sys *System, id string) *blockAgent {
+	t.Helper()
+	a := &blockAgent{BaseAgent: BaseAgent{IDVal: id}, release: make(chan struct{})}
+	sys.Spawn(a)
+	sys.Send(context.Background(), "main", id, "HANG")
+	busy := func() bool {
+		for _, h := range sys.Health().Agents {
+			if h.ID == id && h.Message != nil {
+				return true
+			}
+		}
+		return false
+	}
+	for deadline := time.Now().Add(time.Second); !busy(); {
+		if time.Now().After(deadline) {
+			t.Fatalf("%s did not start processing HANG", id)
+		}
+	}
+	for {
+		if err := sys.TrySend(context.Background(), "main", id, "FILL"); errors.Is(err, ErrMailboxFull) {
+			return a
+		}
+	}
+}
+
+func TestSpawnAndStopChildren(t *testing.T) {
+	sys := NewSystem()
+	defer sys.Shutdown(context.Background())
+
+	errs := make(chan error, 4)
+	parent := &actionAgent{BaseAgent: BaseAgent{IDVal: "maze"}, act: func(ctx context.Context, a Agent, sys *System) error {
+		for _, id := range []string{"walker-1", "walker-2"} {
+			if err := SpawnChild(newInboxAgent(id))(ctx, a, sys); err != nil {
+				return err
+			}
+		}
+		grandchild := newInboxAgent("walker-1.helper")
+		grandchild.SetParent("walker-1")
+		if err := sys.Spawn(grandchild); err != nil {
+			return err
+		}
+		// Чужого агента (і онука) батько зупинити не може
+		errs <- StopChild("stranger")(ctx, a, sys)
+		errs <- StopChild("walker-1.helper")(ctx, a, sys)
+		errs <- StopChild("walker-2")(ctx, a, sys)
+		return nil
+	}}
+	sys.Spawn(parent)
+	sys.Spawn(newInboxAgent("stranger"))
+	sys.Send(context.Background(), "main", "maze", "SPAWN")
+
+	for _, id := range []string{"stranger", "walker-1.helper"} {
+		if err := <-errs; err == nil {
+			t.Fatalf("StopChild(%s) succeeded", id)
+		}
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("StopChild(walker-2): %v", err)
+	}
+	if got := sys.Children("maze"); !slices.Equal(got, []string{"walker-1"}) {
+		t.Fatalf("children %v, want [walker-1]", got)
+	}
+	if err := sys.SpawnChild("ghost", newInboxAgent("orphan")); err == nil {
+		t.Fatal("spawned a child of a missing parent")
+	}
+
+	// Батько зупиняється разом з усіма нащадками
+	if err := sys.Stop("maze"); err != nil {
+		t.Fatal(err)
+	}
+	if got := sys.AgentIDs(); !slices.Equal(got, []string{"stranger"}) {
+		t.Fatalf("agents after Stop: %v", got)
+	}
+}
+
+func TestSendVariantsOnFullMailbox(t *testing.T) {
+	sys := NewSystem()
+	defer sys.Shutdown(context.Background())
+	defer close(fullAgent(t, sys, "stuck").release)
+
+	if fill, ok := sys.Backpressure("stuck"); !ok || fill != 1 {
+		t.Fatalf("backpressure = %v, %v; want 1, true", fill, ok)
+	}
+	if _, ok := sys.Backpressure("ghost"); ok {
+		t.Fatal("backpressure of a missing agent")
+	}
+
+	if err := sys.TrySend(context.Background(), "main", "stuck", "X"); !errors.Is(err, ErrMailboxFull) {
+		t.Fatalf("TrySend: %v, want ErrMailboxFull", err)
+	}
+	start := time.Now()
+	if err := sys.SendWithTimeout(context.Background(), "main", "stuck", "X", 30*time.Millisecond); !errors.Is(err, ErrMailboxFull) {
+		t.Fatalf("SendWithTimeout: %v, want ErrMailboxFull", err)
+	}
+	if waited := time.Since(start); waited < 30*time.Millisecond {
+		t.Fatalf("SendWithTimeout gave up after %v", waited)
+	}
+
+	// Дія TrySend не вважає переповнену скриньку помилкою
+	errs := make(chan error, 1)
+	sys.Spawn(&actionAgent{BaseAgent: BaseAgent{IDVal: "ticker"}, act: func(ctx context.Context, a Agent, sys *System) error {
+		errs <- TrySend("stuck", "TICK")(ctx, a, sys)
+		return nil
+	}})
+	sys.Send(context.Background(), "main", "ticker", "GO")
+	if err := <-errs; err != nil {
+		t.Fatalf("TrySend action: %v", err)
+	}
+}
+
+func TestSendWithTimeoutIsNotMessageDeadline(t *testing.T) {
+	sys := NewSystem()
+	defer sys.Shutdown(context.Background())
+	sink := newInboxAgent("sink")
+	sys.Spawn(sink)
+
+	if err := sys.SendWithTimeout(context.Background(), "main", "sink", "X", time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if env := expectOne(t, sink.got); !env.Deadline.IsZero() {
+		t.Fatalf("send timeout became the message deadline: %v", env.Deadline)
+	}
+	if fill, ok := sys.Backpressure("sink"); !ok || fill != 0 {
+		t.Fatalf("backpressure = %v, %v; want 0, true", fill, ok)
+	}
+}
@@ -0,0 +1,181 @@
+package mas
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	typesMu   sync.RWMutex
+	types     = map[string]func() Agent{}
+	typeNames = map[reflect.Type][]string{} // Зворотний індекс для Restart
+)
+
+// plannerAgentType - PlannerAgent налаштовується даними (PlannerName), тож сам Go-тип
+// не каже, з якої реєстрації створено агента.
+var plannerAgentType = reflect.TypeOf(&PlannerAgent{})
+
+// TypeOption - налаштування RegisterType.
+type TypeOption func(*typeOptions)
+
+type typeOptions struct {
+	gobName string
+}
+
+// WithGobName реєструє тип у GOB під явним іменем - наприклад, "*main.WorkerBot",
+// з яким його зберігала стара програма, щоб world.gob читався і далі.
+func WithGobName(name string) TypeOption {
+	return func(o *typeOptions) {
+		o.gobName = name
+	}
+}
+
+// RegisterType реєструє фабрику агентів під ім'ям типу і сам тип у GOB
+// (для Startup, Export/Import). Фабрика повертає агента з типовими значеннями;
+// ID і поля задаються потім (SpawnByName, файли світу LoadWorld).
+//
+//	mas.RegisterType("WorkerBot", func() mas.Agent { return &WorkerBot{} })
+//
+// Реєструвати треба в init(): GOB панікує, якщо тип уже зареєстровано під іншим іменем.
+func RegisterType(name string, factory func() Agent, opts ...TypeOption) {
+	var o typeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.gobName != "" {
+		gob.RegisterName(o.gobName, factory())
+	} else {
+		gob.Register(factory())
+	}
+
+	typesMu.Lock()
+	defer typesMu.Unlock()
+	types[name] = factory
+	t := reflect.TypeOf(factory())
+	if !slices.Contains(typeNames[t], name) {
+		typeNames[t] = append(typeNames[t], name)
+	}
+}
+
+// typeNameOf - ім'я зареєстрованого типу агента a: записане при створенні
+// (SpawnByName, LoadWorld), а для агентів, створених напряму, - за Go-типом,
+// якщо той зареєстровано рівно під одним ім'ям.
+func typeNameOf(a Agent) (string, bool) {
+	if r, ok := a.(interface{ RegisteredType() string }); ok && r.RegisteredType() != "" {
+		return r.RegisteredType(), true
+	}
+
+	t := reflect.TypeOf(a)
+	if t == plannerAgentType {
+		return "", false
+	}
+	typesMu.RLock()
+	defer typesMu.RUnlock()
+	if names := typeNames[t]; len(names) == 1 {
+		return names[0], true
+	}
+	return "", false
+}
+
+// NewAgentOf створює агента зареєстрованого типу з типовими значеннями.
+func NewAgentOf(typeName string) (Agent, error) {
+	typesMu.RLock()
+	factory, ok := types[typeName]
+	typesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown agent type %q (registered: %v)", typeName, TypeNames())
+	}
+	return factory(), nil
+}
+
+// TypeNames повертає зареєстровані імена типів (відсортовані).
+func TypeNames() []string {
+	typesMu.RLock()
+	defer typesMu.RUnlock()
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SpawnByName створює агента зареєстрованого типу з ID id, заповнює
+// експортовані поля з params (ключ - ім'я поля, значення - як у JSON) і породжує його.
+//
+//	sys.SpawnByName("WorkerBot", "worker-7", map[string]any{"Count": 5})
+func (s *System) SpawnByName(typeName, id string, params map[string]any) error {
+	agent, err := buildAgent(typeName, id, params)
+	if err != nil {
+		return err
+	}
+	return s.Spawn(agent)
+}
+
+// fieldError - помилка в конкретному полі params (файл світу додає до неї рядок).
+type fieldError struct {
+	field string
+	msg   string
+}
+
+func (e *fieldError) Error() string {
+	return e.msg
+}
+
+// buildAgent створює агента за типом, задає ID і заповнює поля з params (через JSON).
+func buildAgent(typeName, id string, params map[string]any) (Agent, error) {
+	if id == "" {
+		return nil, errors.New("agent id is required")
+	}
+	agent, err := NewAgentOf(typeName)
+	if err != nil {
+		return nil, err
+	}
+	setter, ok := agent.(interface{ SetID(string) })
+	if !ok {
+		return nil, fmt.Errorf("type %s (%T) can't take an id", typeName, agent)
+	}
+	setter.SetID(id)
+	if r, ok := agent.(interface{ SetRegisteredType(string) }); ok {
+		r.SetRegisteredType(typeName)
+	}
+
+	if len(params) == 0 {
+		return agent, nil
+	}
+	for _, reserved := range []string{"IDVal", "ParentID", "TypeVal"} {
+		if _, ok := params[reserved]; ok {
+			return nil, &fieldError{field: reserved, msg: fmt.Sprintf("field %s is set by id/parent", reserved)}
+		}
+	}
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(agent); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			field, _, _ := strings.Cut(typeErr.Field, ".")
+			return nil, &fieldError{field: field,
+				msg: fmt.Sprintf("%s.%s: can't use %s as %s", id, typeErr.Field, typeErr.Value, typeErr.Type)}
+		}
+		// json: unknown field "Foo"
+		if name, found := strings.CutPrefix(err.Error(), "json: unknown field "); found {
+			name = strings.Trim(name, `"`)
+			return nil, &fieldError{field: name, msg: fmt.Sprintf("%s has no field %s (type %s)", id, name, typeName)}
+		}
+		return nil, fmt.Errorf("%s: %w", id, err)
+	}
+	return agent, nil
+}
@@ -0,0 +1,90 @@
+package mas_test
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/youryharchenko/go-mas/mas"
+	"github.com/youryharchenko/go-mas/mas/mastest"
+)
+
+// legacyAgent колись зберігався програмою як "*main.legacyAgent".
+type legacyAgent struct {
+	mas.BaseAgent
+	Level int
+}
+
+func init() {
+	mas.RegisterType("mas_test.legacy", func() mas.Agent { return &legacyAgent{Level: 1} }, mas.WithGobName("*main.legacyAgent"))
+}
+
+func TestSpawnByName(t *testing.T) {
+	ts := mastest.New(t)
+	if err := ts.Sys.SpawnByName("mas_test.cell", "cell-7", map[string]any{"Count": 5, "Label": "seven"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ts.Sys.SpawnByName("mas_test.legacy", "old", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	cell := ts.Agent("cell-7").(*cellAgent)
+	if cell.ID() != "cell-7" || cell.Count != 5 || cell.Label != "seven" {
+		t.Fatalf("cell %+v", cell)
+	}
+	if cell.RegisteredType() != "mas_test.cell" {
+		t.Fatalf("registered type %q", cell.RegisteredType())
+	}
+	// Фабрика дає типові значення і щоразу нового агента
+	if old := ts.Agent("old").(*legacyAgent); old.Level != 1 {
+		t.Fatalf("legacy %+v", old)
+	}
+	a, _ := mas.NewAgentOf("mas_test.legacy")
+	b, _ := mas.NewAgentOf("mas_test.legacy")
+	if a == b {
+		t.Fatal("factory returned the same agent twice")
+	}
+	if names := mas.TypeNames(); !slices.Contains(names, "mas_test.cell") || !slices.IsSorted(names) {
+		t.Fatalf("type names %v", names)
+	}
+
+	for _, tt := range []struct {
+		typ    string
+		id     string
+		params map[string]any
+		want   string
+	}{
+		{"mas_test.nope", "x", nil, `unknown agent type "mas_test.nope"`},
+		{"mas_test.cell", "", nil, "id is required"},
+		{"mas_test.cell", "x", map[string]any{"Color": "red"}, "x has no field Color (type mas_test.cell)"},
+		{"mas_test.cell", "x", map[string]any{"Count": "many"}, "x.Count: can't use string as int"},
+		{"mas_test.cell", "x", map[string]any{"ParentID": "boss"}, "field ParentID is set by id/parent"},
+	} {
+		err := ts.Sys.SpawnByName(tt.typ, tt.id, tt.params)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Fatalf("SpawnByName(%s, %q, %v): %v, want ...%s...", tt.typ, tt.id, tt.params, err, tt.want)
+		}
+	}
+	if _, ok := ts.Sys.GetAgent("x"); ok {
+		t.Fatal("agent spawned despite an error")
+	}
+}
+
+func TestRegisterTypeKeepsGobName(t *testing.T) {
+	var buf bytes.Buffer
+	if err := mas.EncodeWorld(&buf, map[string]mas.Agent{"old": &legacyAgent{BaseAgent: mas.BaseAgent{IDVal: "old"}, Level: 3}}); err != nil {
+		t.Fatal(err)
+	}
+	// Файл, записаний старою програмою, знову читається
+	if !bytes.Contains(buf.Bytes(), []byte("*main.legacyAgent")) {
+		t.Fatal("type is not stored under its gob name")
+	}
+	world, err := mas.DecodeWorld(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if old, ok := world["old"].(*legacyAgent); !ok || old.Level != 3 {
+		t.Fatalf("decoded %+v", world["old"])
+	}
+}
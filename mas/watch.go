@@ -0,0 +1,111 @@
+package mas
+
+// WatchAll - спеціальний ID для Watch: сповіщення про зміни всіх агентів системи.
+const WatchAll = "*"
+
+// StateChange - сповіщення "стан агента змінився".
+// Сам стан не передається: спостерігач бере його через Snapshot, коли йому зручно.
+type StateChange struct {
+	AgentID string
+	Version uint64   // Порядковий номер зміни (з моменту запуску агента)
+	Cause   Envelope // Повідомлення, обробка якого змінила стан
+}
+
+// watcher - одна підписка Watch.
+type watcher struct {
+	id string
+	ch chan StateChange
+}
+
+// Watch підписується на зміни стану агента id (або WatchAll).
+// Сповіщення приходять після кожного обробленого повідомлення, яке змінило стан
+// (MutateState або BaseAgent.MarkChanged). Повільний спостерігач пропускає
+// проміжні сповіщення, але не гальмує агента.
+// Повернена функція скасовує підписку і закриває канал.
+func (s *System) Watch(id string) (<-chan StateChange, func()) {
+	w := &watcher{id: id, ch: make(chan StateChange, 16)}
+
+	s.watchMu.Lock()
+	s.watchers = append(s.watchers, w)
+	s.watchMu.Unlock()
+
+	cancel := func() {
+		s.watchMu.Lock()
+		defer s.watchMu.Unlock()
+		for i, other := range s.watchers {
+			if other == w {
+				s.watchers = append(s.watchers[:i], s.watchers[i+1:]...)
+				close(w.ch)
+				return
+			}
+		}
+	}
+	return w.ch, cancel
+}
+
+// notifyChange розсилає сповіщення підписникам, не блокуючись.
+func (s *System) notifyChange(change StateChange) {
+	s.watchMu.Lock()
+	defer s.watchMu.Unlock()
+
+	for _, w := range s.watchers {
+		if w.id != WatchAll && w.id != change.AgentID {
+			continue
+		}
+		select {
+		case w.ch <- change:
+		default:
+			// Підписник не встигає - пропускаємо, він все одно отримає наступне
+		}
+	}
+}
+
+// Traffic - подія для Tap: доставлене повідомлення або публікація в тему.
+type Traffic struct {
+	Envelope Envelope
+	Topic    string // Для Publish: тема (Envelope.To тоді порожній)
+}
+
+// tap - одна підписка Tap.
+type tap struct {
+	ch chan Traffic
+}
+
+// Tap підписується на весь трафік системи та її підсистем: кожне доставлене
+// в скриньку повідомлення і кожну публікацію (Publish). Як і Watch, повільний
+// слухач пропускає події, але не гальмує агентів.
+// Повернена функція скасовує підписку і закриває канал.
+func (s *System) Tap() (<-chan Traffic, func()) {
+	t := &tap{ch: make(chan Traffic, 256)}
+
+	s.watchMu.Lock()
+	s.taps = append(s.taps, t)
+	s.watchMu.Unlock()
+
+	cancel := func() {
+		s.watchMu.Lock()
+		defer s.watchMu.Unlock()
+		for i, other := range s.taps {
+			if other == t {
+				s.taps = append(s.taps[:i], s.taps[i+1:]...)
+				close(t.ch)
+				return
+			}
+		}
+	}
+	return t.ch, cancel
+}
+
+// notifyTap передає подію слухачам цієї системи і всіх батьківських.
+func (s *System) notifyTap(tr Traffic) {
+	for sys := s; sys != nil; sys = sys.parent {
+		sys.watchMu.Lock()
+		for _, t := range sys.taps {
+			select {
+			case t.ch <- tr:
+			default:
+			}
+		}
+		sys.watchMu.Unlock()
+	}
+}
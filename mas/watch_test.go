@@ -0,0 +1,70 @@
+package mas_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/youryharchenko/go-mas/mas"
+	"github.com/youryharchenko/go-mas/mas/mastest"
+)
+
+// tallyAgent змінює стан лише на "INC".
+type tallyAgent struct {
+	mas.BaseAgent
+	Count int
+}
+
+func (a *tallyAgent) Plan(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
+	if msg.Payload != "INC" {
+		return nil, nil
+	}
+	return []mas.Action{mas.MutateState(func(a any) { a.(*tallyAgent).Count++ })}, nil
+}
+
+// drain забирає все, що вже прийшло в канал.
+func drain(ch <-chan mas.StateChange) []mas.StateChange {
+	var out []mas.StateChange
+	for {
+		select {
+		case c := <-ch:
+			out = append(out, c)
+		default:
+			return out
+		}
+	}
+}
+
+func TestWatchReportsOnlyChanges(t *testing.T) {
+	ts := mastest.New(t)
+	ts.Spawn(&tallyAgent{BaseAgent: mas.BaseAgent{IDVal: "a"}}, &tallyAgent{BaseAgent: mas.BaseAgent{IDVal: "b"}})
+	one, stopOne := ts.Sys.Watch("a")
+	all, stopAll := ts.Sys.Watch(mas.WatchAll)
+	defer stopAll()
+
+	ts.Send("main", "a", "INC")
+	ts.Send("main", "a", "READ")
+	ts.Send("main", "b", "INC")
+	ts.Send("main", "a", "INC")
+	ts.RunUntilIdle()
+
+	got := drain(one)
+	if len(got) != 2 {
+		t.Fatalf("watch(a) got %d changes, want 2: %+v", len(got), got)
+	}
+	for i, c := range got {
+		if c.AgentID != "a" || c.Version != uint64(i+1) || c.Cause.Payload != "INC" {
+			t.Fatalf("change %d: %+v", i, c)
+		}
+	}
+	if got := drain(all); len(got) != 3 {
+		t.Fatalf("watch(*) got %d changes, want 3: %+v", len(got), got)
+	}
+
+	// Після скасування канал закрито і нових сповіщень немає
+	stopOne()
+	ts.Send("main", "a", "INC")
+	ts.RunUntilIdle()
+	if _, open := <-one; open {
+		t.Fatal("channel is open after cancel")
+	}
+}
@@ -0,0 +1,295 @@
+package mas
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// WorldDef - декларативний опис світу (файл YAML або TOML, див. LoadWorld):
+//
+//	agents:
+//	  - type: WorkerBot        # ім'я з RegisterType
+//	    id: worker-1
+//	    fields: {Count: 0}     # початкові значення експортованих полів
+//	  - type: ManagerBot
+//	    id: boss-1
+//	    subscribe: [news]      # теми Publish
+//	    fields:
+//	      TargetAgentID: worker-1
+//	timers:
+//	  - to: boss-1
+//	    every: 2s              # або after: 5s - один раз
+//	    payload: TICK
+//	subsystems:
+//	  - name: maze
+//	    agents: [...]
+type WorldDef struct {
+	Name       string     `toml:"name"` // Ім'я підсистеми (лише для повідомлень)
+	Agents     []AgentDef `toml:"agents"`
+	Timers     []TimerDef `toml:"timers"`
+	Subsystems []WorldDef `toml:"subsystems"`
+
+	Line int    `toml:"-"` // Рядок у файлі
+	File string `toml:"-"` // Звідки прочитано (для повідомлень про помилки)
+}
+
+// AgentDef - один агент у файлі світу.
+type AgentDef struct {
+	Type      string         `toml:"type"`
+	ID        string         `toml:"id"`
+	Parent    string         `toml:"parent"` // Породити як дитину цього агента (SpawnChild)
+	Fields    map[string]any `toml:"fields"`
+	Subscribe []string       `toml:"subscribe"`
+
+	Line       int            `toml:"-"`
+	FieldLines map[string]int `toml:"-"` // Рядки окремих полів (якщо відомі)
+}
+
+// TimerDef - повідомлення, яке світ надсилає за годинником системи.
+// Відправник завжди WorldTimerID: файл світу не може говорити від імені агентів,
+// тож правила доступу для таймерів пишуться на "world".
+type TimerDef struct {
+	To      string `toml:"to"`
+	Every   string `toml:"every"` // Період ("2s"); тік пропускається, якщо скринька повна
+	After   string `toml:"after"` // Або одна відправка через цей час
+	Payload any    `toml:"payload"`
+
+	Line int `toml:"-"`
+}
+
+// WorldTimerID - відправник повідомлень таймерів файлу світу.
+const WorldTimerID = "world"
+
+// WorldError - помилка у файлі світу з номером рядка.
+type WorldError struct {
+	File string
+	Line int
+	Msg  string
+}
+
+func (e *WorldError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+	}
+	return fmt.Sprintf("%s: %s", e.File, e.Msg)
+}
+
+// LoadWorld читає файл світу (.yaml, .yml або .toml) і породжує агентів,
+// яких у системі ще немає (наприклад, після Startup), підписки і таймери.
+// Файл спершу перевіряється цілком: з помилками не породжується нічого.
+func (s *System) LoadWorld(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	def, err := ParseWorld(path, data)
+	if err != nil {
+		return err
+	}
+	return s.ApplyWorld(def)
+}
+
+// ApplyWorld застосовує вже прочитаний опис світу (див. LoadWorld).
+func (s *System) ApplyWorld(def *WorldDef) error {
+	plan, err := s.planWorld(def, def.File, true)
+	if err != nil {
+		return err
+	}
+	return s.runWorld(plan)
+}
+
+// worldPlan - перевірений опис: готові агенти, таймери і плани підсистем.
+type worldPlan struct {
+	def    *WorldDef
+	agents []plannedAgent
+	timers []plannedTimer
+	subs   []*worldPlan
+}
+
+type plannedAgent struct {
+	agent  Agent
+	parent string
+}
+
+type plannedTimer struct {
+	def   TimerDef
+	every time.Duration
+	after time.Duration
+}
+
+// planWorld перевіряє опис і будує агентів. existing - чи зважати на агентів системи
+// (для нових підсистем - ні, вони ще порожні).
+func (s *System) planWorld(def *WorldDef, file string, existing bool) (*worldPlan, error) {
+	plan := &worldPlan{def: def}
+	var errs []error
+	fail := func(line int, format string, args ...any) {
+		errs = append(errs, &WorldError{File: file, Line: line, Msg: fmt.Sprintf(format, args...)})
+	}
+	running := func(id string) bool {
+		if !existing {
+			return false
+		}
+		_, ok := s.localAgent(id)
+		return ok
+	}
+
+	declared := make(map[string]int) // ID -> рядок
+	for _, ad := range def.Agents {
+		if ad.ID == "" {
+			fail(ad.Line, "agent has no id")
+			continue
+		}
+		if line, dup := declared[ad.ID]; dup {
+			fail(ad.Line, "duplicate agent id %q (first at line %d)", ad.ID, line)
+			continue
+		}
+		declared[ad.ID] = ad.Line
+	}
+
+	for _, ad := range def.Agents {
+		if ad.Parent != "" {
+			if _, ok := declared[ad.Parent]; !ok && !running(ad.Parent) {
+				fail(ad.Line, "parent %q of %s is not defined", ad.Parent, ad.ID)
+			}
+		}
+		for _, topic := range ad.Subscribe {
+			if topic == "" {
+				fail(ad.Line, "empty topic in subscribe of %s", ad.ID)
+			}
+		}
+		if ad.ID == "" || running(ad.ID) {
+			continue // Вже є (відновлений Startup) - не чіпаємо
+		}
+
+		agent, err := buildAgent(ad.Type, ad.ID, ad.Fields)
+		if err != nil {
+			line := ad.Line
+			var fe *fieldError
+			if errors.As(err, &fe) {
+				if l, ok := ad.FieldLines[fe.field]; ok {
+					line = l
+				}
+			}
+			fail(line, "%v", err)
+			continue
+		}
+		plan.agents = append(plan.agents, plannedAgent{agent: agent, parent: ad.Parent})
+	}
+
+	for _, td := range def.Timers {
+		pt := plannedTimer{def: td}
+		if td.To == "" {
+			fail(td.Line, "timer has no target (to)")
+		} else if _, ok := declared[td.To]; !ok && !running(td.To) {
+			fail(td.Line, "timer target %q is not defined", td.To)
+		}
+		if td.Payload == nil {
+			fail(td.Line, "timer has no payload")
+		}
+		var err error
+		switch {
+		case td.Every != "" && td.After != "":
+			fail(td.Line, "timer needs either every or after, not both")
+		case td.Every != "":
+			if pt.every, err = time.ParseDuration(td.Every); err != nil || pt.every <= 0 {
+				fail(td.Line, "bad period %q", td.Every)
+			}
+		case td.After != "":
+			if pt.after, err = time.ParseDuration(td.After); err != nil || pt.after < 0 {
+				fail(td.Line, "bad delay %q", td.After)
+			}
+		default:
+			fail(td.Line, "timer needs every or after")
+		}
+		plan.timers = append(plan.timers, pt)
+	}
+
+	for i := range def.Subsystems {
+		sub, err := s.planWorld(&def.Subsystems[i], file, false)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		plan.subs = append(plan.subs, sub)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return plan, nil
+}
+
+// runWorld породжує агентів (батьків раніше за дітей), підписки, таймери і підсистеми.
+func (s *System) runWorld(plan *worldPlan) error {
+	pending := plan.agents
+	for len(pending) > 0 {
+		var next []plannedAgent
+		for _, pa := range pending {
+			if pa.parent != "" {
+				if _, ok := s.localAgent(pa.parent); !ok {
+					next = append(next, pa) // Батько ще не породжений
+					continue
+				}
+				if err := s.SpawnChild(pa.parent, pa.agent); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := s.Spawn(pa.agent); err != nil {
+				return err
+			}
+		}
+		if len(next) == len(pending) {
+			return &WorldError{File: plan.def.File, Line: plan.def.Line,
+				Msg: fmt.Sprintf("agent %s: parent cycle", next[0].agent.ID())}
+		}
+		pending = next
+	}
+
+	for _, ad := range plan.def.Agents {
+		for _, topic := range ad.Subscribe {
+			s.Subscribe(topic, ad.ID)
+		}
+	}
+
+	for _, pt := range plan.timers {
+		s.startTimer(pt)
+	}
+
+	for _, sub := range plan.subs {
+		if err := s.CreateSubsystem().runWorld(sub); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startTimer запускає таймер файлу світу; він живе, поки живе система.
+func (s *System) startTimer(pt plannedTimer) {
+	// Таймер - частина світу, а не агент: підписуємо повідомлення ідентичністю світу
+	from := WorldTimerID
+	ctx := withIdentity(s.ctx, from)
+
+	if pt.every == 0 {
+		s.afterFunc(pt.after, func() {
+			if err := s.Send(ctx, from, pt.def.To, pt.def.Payload); err != nil && s.ctx.Err() == nil {
+				log.Printf("World timer -> %s: %v", pt.def.To, err)
+			}
+		})
+		return
+	}
+
+	var tick func()
+	tick = func() {
+		if s.ctx.Err() != nil {
+			return
+		}
+		// Як і тікери в програмах: якщо агент не встигає, пропускаємо тік
+		s.TrySend(ctx, from, pt.def.To, pt.def.Payload)
+		s.afterFunc(pt.every, tick)
+	}
+	s.afterFunc(pt.every, tick)
+}
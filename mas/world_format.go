@@ -0,0 +1,296 @@
+package mas
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// ParseWorld розбирає опис світу; формат визначається розширенням name (.yaml, .yml, .toml).
+func ParseWorld(name string, data []byte) (*WorldDef, error) {
+	var (
+		def *WorldDef
+		err error
+	)
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml":
+		def, err = parseWorldYAML(data)
+	case ".toml":
+		def, err = parseWorldTOML(data)
+	default:
+		return nil, &WorldError{File: name, Msg: "unknown world format (want .yaml, .yml or .toml)"}
+	}
+	if err != nil {
+		var we *WorldError
+		if errors.As(err, &we) {
+			we.File = name
+			return nil, we
+		}
+		return nil, &WorldError{File: name, Msg: err.Error()}
+	}
+	def.File = name
+	return def, nil
+}
+
+// --- YAML ---
+
+// errUnknownKey - ключ, якого немає в схемі (рядок додає yamlMapping).
+var errUnknownKey = errors.New("unknown key")
+
+// yamlLine витягує номер рядка з помилки парсера yaml.v3 ("yaml: line 3: ...").
+var yamlLine = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+func parseWorldYAML(data []byte) (*WorldDef, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		if m := yamlLine.FindStringSubmatch(err.Error()); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			return nil, &WorldError{Line: line, Msg: m[2]}
+		}
+		return nil, err
+	}
+
+	def := &WorldDef{}
+	if len(root.Content) == 0 {
+		return def, nil // Порожній файл - порожній світ
+	}
+	if err := def.fromYAML(root.Content[0]); err != nil {
+		return nil, err
+	}
+	return def, nil
+}
+
+// yamlMapping обходить пари ключ-значення вузла, додаючи рядок до помилок.
+func yamlMapping(n *yaml.Node, fn func(key *yaml.Node, value *yaml.Node) error) error {
+	if n.Kind != yaml.MappingNode {
+		return &WorldError{Line: n.Line, Msg: "expected a mapping"}
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		err := fn(key, value)
+		if err == nil {
+			continue
+		}
+		var we *WorldError
+		switch {
+		case errors.Is(err, errUnknownKey):
+			return &WorldError{Line: key.Line, Msg: fmt.Sprintf("unknown key %q", key.Value)}
+		case errors.As(err, &we):
+			return err
+		default:
+			return &WorldError{Line: value.Line, Msg: fmt.Sprintf("%s: %v", key.Value, err)}
+		}
+	}
+	return nil
+}
+
+// yamlList обходить елементи послідовності.
+func yamlList(n *yaml.Node, fn func(item *yaml.Node) error) error {
+	if n.Kind != yaml.SequenceNode {
+		return &WorldError{Line: n.Line, Msg: "expected a list"}
+	}
+	for _, item := range n.Content {
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *WorldDef) fromYAML(n *yaml.Node) error {
+	d.Line = n.Line
+	return yamlMapping(n, func(key, v *yaml.Node) error {
+		switch key.Value {
+		case "name":
+			return v.Decode(&d.Name)
+		case "agents":
+			return yamlList(v, func(item *yaml.Node) error {
+				var a AgentDef
+				err := a.fromYAML(item)
+				d.Agents = append(d.Agents, a)
+				return err
+			})
+		case "timers":
+			return yamlList(v, func(item *yaml.Node) error {
+				var t TimerDef
+				err := t.fromYAML(item)
+				d.Timers = append(d.Timers, t)
+				return err
+			})
+		case "subsystems":
+			return yamlList(v, func(item *yaml.Node) error {
+				var sub WorldDef
+				err := sub.fromYAML(item)
+				d.Subsystems = append(d.Subsystems, sub)
+				return err
+			})
+		}
+		return errUnknownKey
+	})
+}
+
+func (a *AgentDef) fromYAML(n *yaml.Node) error {
+	a.Line = n.Line
+	return yamlMapping(n, func(key, v *yaml.Node) error {
+		switch key.Value {
+		case "type":
+			return v.Decode(&a.Type)
+		case "id":
+			return v.Decode(&a.ID)
+		case "parent":
+			return v.Decode(&a.Parent)
+		case "subscribe":
+			return v.Decode(&a.Subscribe)
+		case "fields":
+			a.Fields = make(map[string]any)
+			a.FieldLines = make(map[string]int)
+			return yamlMapping(v, func(field, value *yaml.Node) error {
+				var val any
+				if err := value.Decode(&val); err != nil {
+					return err
+				}
+				a.Fields[field.Value] = val
+				a.FieldLines[field.Value] = field.Line
+				return nil
+			})
+		}
+		return errUnknownKey
+	})
+}
+
+func (t *TimerDef) fromYAML(n *yaml.Node) error {
+	t.Line = n.Line
+	return yamlMapping(n, func(key, v *yaml.Node) error {
+		switch key.Value {
+		case "to":
+			return v.Decode(&t.To)
+		case "every":
+			return v.Decode(&t.Every)
+		case "after":
+			return v.Decode(&t.After)
+		case "payload":
+			return v.Decode(&t.Payload)
+		}
+		return errUnknownKey
+	})
+}
+
+// --- TOML ---
+//
+// BurntSushi/toml не віддає позицій ключів, тому рядки знаходимо самі
+// за заголовками таблиць: [[agents]], [[subsystems.agents]], [agents.fields] ...
+
+func parseWorldTOML(data []byte) (*WorldDef, error) {
+	def := &WorldDef{}
+	md, err := toml.Decode(string(data), def)
+	if err != nil {
+		var pe toml.ParseError
+		if errors.As(err, &pe) {
+			return nil, &WorldError{Line: pe.Position.Line, Msg: pe.Message}
+		}
+		return nil, err
+	}
+
+	lines := strings.Split(string(data), "\n")
+	tomlLines(def, lines)
+
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		key := undecoded[0]
+		return nil, &WorldError{Line: tomlKeyLine(lines, key), Msg: fmt.Sprintf("unknown key %q", key.String())}
+	}
+	return def, nil
+}
+
+// tomlHeader - "[[subsystems.agents]]" або "[agents.fields]".
+var tomlHeader = regexp.MustCompile(`^\s*(\[\[?)\s*([A-Za-z0-9_.\-]+)\s*\]\]?`)
+
+// tomlAssign - "Count = 5" (ключ без лапок).
+var tomlAssign = regexp.MustCompile(`^\s*([A-Za-z0-9_\-]+)\s*=`)
+
+// tomlLines розставляє рядки елементам масивів таблиць і полям агентів.
+func tomlLines(def *WorldDef, lines []string) {
+	def.Line = 1
+	seen := make(map[any]int) // Скільки елементів уже зустріли в кожному масиві
+
+	// current - описи, яким належать наступні рядки "ключ = значення"
+	var (
+		agent    *AgentDef
+		inFields bool
+	)
+	for i, text := range lines {
+		line := i + 1
+		if m := tomlHeader.FindStringSubmatch(text); m != nil {
+			inFields = false
+			path := strings.Split(m[2], ".")
+			if m[1] == "[" {
+				// Звичайна таблиця: цікавить лише [..agents.fields]
+				if agent != nil && path[len(path)-1] == "fields" {
+					inFields = true
+				}
+				continue
+			}
+
+			// [[...]] - новий елемент масиву; батьки - останні елементи підсистем
+			world := def
+			for _, seg := range path[:len(path)-1] {
+				if seg != "subsystems" || seen[&world.Subsystems] == 0 {
+					world = nil
+					break
+				}
+				world = &world.Subsystems[seen[&world.Subsystems]-1]
+			}
+			agent = nil
+			if world == nil {
+				continue
+			}
+			switch last := path[len(path)-1]; last {
+			case "agents":
+				if idx := seen[&world.Agents]; idx < len(world.Agents) {
+					agent = &world.Agents[idx]
+					agent.Line = line
+					seen[&world.Agents]++
+				}
+			case "timers":
+				if idx := seen[&world.Timers]; idx < len(world.Timers) {
+					world.Timers[idx].Line = line
+					seen[&world.Timers]++
+				}
+			case "subsystems":
+				if idx := seen[&world.Subsystems]; idx < len(world.Subsystems) {
+					world.Subsystems[idx].Line = line
+					seen[&world.Subsystems]++
+				}
+			}
+			continue
+		}
+
+		if agent != nil && inFields {
+			if m := tomlAssign.FindStringSubmatch(text); m != nil {
+				if agent.FieldLines == nil {
+					agent.FieldLines = make(map[string]int)
+				}
+				agent.FieldLines[m[1]] = line
+			}
+		}
+	}
+}
+
+// tomlKeyLine - перший рядок, де зустрічається останній сегмент ключа (або 0).
+func tomlKeyLine(lines []string, key toml.Key) int {
+	name := key[len(key)-1]
+	for i, text := range lines {
+		if m := tomlAssign.FindStringSubmatch(text); m != nil && m[1] == name {
+			return i + 1
+		}
+		if m := tomlHeader.FindStringSubmatch(text); m != nil && strings.HasSuffix(m[2], name) {
+			return i + 1
+		}
+	}
+	return 0
+}
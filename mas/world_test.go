@@ -0,0 +1,177 @@
+package mas_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/youryharchenko/go-mas/mas"
+	"github.com/youryharchenko/go-mas/mas/mastest"
+)
+
+// cellAgent - агент для файлів світу: поля задає fields.
+type cellAgent struct {
+	mas.BaseAgent
+	Count int
+	Label string
+}
+
+func (a *cellAgent) Plan(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
+	return nil, nil
+}
+
+func init() {
+	mas.RegisterType("mas_test.cell", func() mas.Agent { return &cellAgent{} })
+}
+
+const worldYAML = `name: demo
+agents:
+  - type: mas_test.cell
+    id: cell-1
+    subscribe: [news]
+    fields:
+      Count: 3
+      Label: first
+  - type: mas_test.cell
+    id: cell-2
+    parent: cell-1
+timers:
+  - to: cell-1
+    every: 2s
+    payload: TICK
+  - to: cell-2
+    after: 1s
+    payload: WAKE
+`
+
+const worldTOML = `name = "demo"
+
+[[agents]]
+type = "mas_test.cell"
+id = "cell-1"
+subscribe = ["news"]
+
+[agents.fields]
+Count = 3
+Label = "first"
+
+[[agents]]
+type = "mas_test.cell"
+id = "cell-2"
+parent = "cell-1"
+
+[[timers]]
+to = "cell-1"
+every = "2s"
+payload = "TICK"
+
+[[timers]]
+to = "cell-2"
+after = "1s"
+payload = "WAKE"
+`
+
+func TestLoadWorld(t *testing.T) {
+	for name, data := range map[string]string{"world.yaml": worldYAML, "world.toml": worldTOML} {
+		t.Run(name, func(t *testing.T) {
+			ts := mastest.New(t)
+			def, err := mas.ParseWorld(name, []byte(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := ts.Sys.ApplyWorld(def); err != nil {
+				t.Fatal(err)
+			}
+
+			cell := ts.Agent("cell-1").(*cellAgent)
+			if cell.Count != 3 || cell.Label != "first" {
+				t.Fatalf("fields not applied: %+v", cell)
+			}
+			if got := ts.Agent("cell-2").(*cellAgent).Parent(); got != "cell-1" {
+				t.Fatalf("cell-2 parent %q, want cell-1", got)
+			}
+			if subs := ts.Sys.Subscribers("news"); !slices.Equal(subs, []string{"cell-1"}) {
+				t.Fatalf("news subscribers %v", subs)
+			}
+
+			// Таймери говорять від імені світу
+			ts.Advance(time.Second)
+			ts.ExpectMessage("cell-2", mas.AllOf(mas.PayloadEquals("WAKE"), mas.From(mas.WorldTimerID)))
+			ts.Advance(time.Second)
+			env := ts.ExpectMessage("cell-1", mas.AllOf(mas.PayloadEquals("TICK"), mas.From(mas.WorldTimerID)))
+			if env.Sender != mas.WorldTimerID {
+				t.Fatalf("timer sender %q, want %q", env.Sender, mas.WorldTimerID)
+			}
+		})
+	}
+}
+
+func TestWorldTimerObeysACL(t *testing.T) {
+	ts := mastest.New(t)
+	ts.Sys.Deny(mas.WorldTimerID, "cell-1", "*")
+	def, err := mas.ParseWorld("world.yaml", []byte(worldYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ts.Sys.ApplyWorld(def); err != nil {
+		t.Fatal(err)
+	}
+
+	ts.Advance(2 * time.Second)
+	ts.ExpectMessage("cell-2", mas.PayloadEquals("WAKE"))
+	ts.ExpectNoMessage("cell-1", mas.PayloadEquals("TICK"))
+}
+
+func TestLoadWorldErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		data string
+		line int
+		msg  string
+	}{
+		{"yaml syntax", "w.yaml", "name: demo\nagents:\n\t- id: a\n", 3, "found character that cannot start any token"},
+		{"yaml unknown key", "w.yaml", "agents:\n  - id: a\n    kind: x\n", 3, `unknown key "kind"`},
+		{"yaml unknown type", "w.yaml", "agents:\n  - id: a\n    type: mas_test.nope\n", 2, "mas_test.nope"},
+		{"yaml duplicate id", "w.yaml", "agents:\n  - {type: mas_test.cell, id: a}\n  - {type: mas_test.cell, id: a}\n", 3, `duplicate agent id "a" (first at line 2)`},
+		{"yaml bad field", "w.yaml", "agents:\n  - type: mas_test.cell\n    id: a\n    fields:\n      Label: x\n      Count: many\n", 6, "a.Count"},
+		{"yaml unknown field", "w.yaml", "agents:\n  - type: mas_test.cell\n    id: a\n    fields:\n      Color: red\n", 5, "a has no field Color"},
+		{"yaml reserved field", "w.yaml", "agents:\n  - type: mas_test.cell\n    id: a\n    fields:\n      IDVal: b\n", 5, "field IDVal is set by id/parent"},
+		{"yaml timer from", "w.yaml", "agents:\n  - {type: mas_test.cell, id: a}\ntimers:\n  - to: a\n    from: admin\n    every: 1s\n    payload: X\n", 5, `unknown key "from"`},
+		{"yaml timer target", "w.yaml", "timers:\n  - to: ghost\n    every: 1s\n    payload: X\n", 2, `timer target "ghost" is not defined`},
+		{"yaml timer period", "w.yaml", "agents:\n  - {type: mas_test.cell, id: a}\ntimers:\n  - to: a\n    every: soon\n    payload: X\n", 4, `bad period "soon"`},
+
+		{"toml syntax", "w.toml", "[[agents]]\nid = \"a\"\ntype = \n", 3, ""},
+		{"toml unknown key", "w.toml", "[[agents]]\nid = \"a\"\nkind = \"x\"\n", 3, `unknown key "agents.kind"`},
+		{"toml unknown type", "w.toml", "[[agents]]\nid = \"a\"\ntype = \"mas_test.nope\"\n", 1, "mas_test.nope"},
+		{"toml duplicate id", "w.toml", "[[agents]]\nid = \"a\"\ntype = \"mas_test.cell\"\n\n[[agents]]\nid = \"a\"\ntype = \"mas_test.cell\"\n", 5, `duplicate agent id "a" (first at line 1)`},
+		{"toml bad field", "w.toml", "[[agents]]\nid = \"a\"\ntype = \"mas_test.cell\"\n\n[agents.fields]\nLabel = \"x\"\nCount = \"many\"\n", 7, "a.Count"},
+		{"toml timer from", "w.toml", "[[agents]]\nid = \"a\"\ntype = \"mas_test.cell\"\n\n[[timers]]\nto = \"a\"\nfrom = \"admin\"\nevery = \"1s\"\npayload = \"X\"\n", 7, `unknown key "timers.from"`},
+		{"toml subsystem", "w.toml", "[[subsystems]]\nname = \"inner\"\n\n[[subsystems.agents]]\nid = \"a\"\ntype = \"mas_test.nope\"\n", 4, "mas_test.nope"},
+
+		{"unknown format", "w.json", "{}", 0, "unknown world format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := mastest.New(t)
+			def, err := mas.ParseWorld(tt.file, []byte(tt.data))
+			if err == nil {
+				err = ts.Sys.ApplyWorld(def)
+			}
+			var we *mas.WorldError
+			if !errors.As(err, &we) {
+				t.Fatalf("got %v, want a WorldError", err)
+			}
+			if we.File != tt.file || we.Line != tt.line || !strings.Contains(we.Msg, tt.msg) {
+				t.Fatalf("got %q, want %s:%d: ...%s...", we.Error(), tt.file, tt.line, tt.msg)
+			}
+			// З помилками у файлі не породжується нічого
+			if _, ok := ts.Sys.GetAgent("a"); ok {
+				t.Fatal("agent spawned from a broken world")
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/youryharchenko/go-mas/mas"
+)
+
+// diff друкує різницю між двома світами, по рядку на зміну:
+// "+ id (тип)" - агент з'явився, "- id (тип)" - зник, "~ id.Path: a -> b" - змінилось поле.
+func diff(before, after map[string]mas.Agent) error {
+	ids := make(map[string]bool)
+	for id := range before {
+		ids[id] = true
+	}
+	for id := range after {
+		ids[id] = true
+	}
+	sorted := make([]string, 0, len(ids))
+	for id := range ids {
+		sorted = append(sorted, id)
+	}
+	sort.Strings(sorted)
+
+	for _, id := range sorted {
+		a, inBefore := before[id]
+		b, inAfter := after[id]
+		switch {
+		case !inBefore:
+			fmt.Printf("+ %s (%T)\n", id, b)
+		case !inAfter:
+			fmt.Printf("- %s (%T)\n", id, a)
+		default:
+			if ta, tb := fmt.Sprintf("%T", a), fmt.Sprintf("%T", b); ta != tb {
+				fmt.Printf("~ %s: type %s -> %s\n", id, ta, tb)
+				continue
+			}
+			fa, err := flatten(a)
+			if err != nil {
+				return fmt.Errorf("%s: %w", id, err)
+			}
+			fb, err := flatten(b)
+			if err != nil {
+				return fmt.Errorf("%s: %w", id, err)
+			}
+			for _, line := range diffFields(fa, fb) {
+				fmt.Printf("~ %s.%s\n", id, line)
+			}
+		}
+	}
+	return nil
+}
+
+// flatten перетворює агента на пари "шлях -> JSON-значення".
+func flatten(agent mas.Agent) (map[string]string, error) {
+	data, err := json.Marshal(agent)
+	if err != nil {
+		return nil, err
+	}
+	var tree any
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+	out := make(map[string]string)
+	flattenInto(out, "", tree)
+	return out, nil
+}
+
+func flattenInto(out map[string]string, prefix string, v any) {
+	if obj, ok := v.(map[string]any); ok && len(obj) > 0 {
+		for k, child := range obj {
+			path := k
+			if prefix != "" {
+				path = prefix + "." + k
+			}
+			flattenInto(out, path, child)
+		}
+		return
+	}
+	data, _ := json.Marshal(v)
+	out[prefix] = string(data)
+}
+
+func diffFields(a, b map[string]string) []string {
+	paths := make(map[string]bool)
+	for p := range a {
+		paths[p] = true
+	}
+	for p := range b {
+		paths[p] = true
+	}
+
+	var lines []string
+	for p := range paths {
+		va, okA := a[p]
+		vb, okB := b[p]
+		switch {
+		case !okA:
+			lines = append(lines, fmt.Sprintf("%s: (none) -> %s", p, vb))
+		case !okB:
+			lines = append(lines, fmt.Sprintf("%s: %s -> (none)", p, va))
+		case va != vb:
+			lines = append(lines, fmt.Sprintf("%s: %s -> %s", p, va, vb))
+		}
+	}
+	sort.Strings(lines)
+	return lines
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/youryharchenko/go-mas/mas"
+)
+
+// setField змінює поле агента за шляхом "Count", "State.Count" або "Positions.walker-2".
+// Значення розбирається як JSON; для рядкових полів можна писати без лапок.
+func setField(agent mas.Agent, path, raw string) error {
+	parts := strings.Split(path, ".")
+	if parts[0] == "IDVal" {
+		return errors.New("ID is the key of the world map and can't be changed")
+	}
+	return setPath(reflect.ValueOf(agent), parts, raw)
+}
+
+func setPath(v reflect.Value, path []string, raw string) error {
+	if len(path) == 0 {
+		return assign(v, raw)
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return fmt.Errorf("%s: nil pointer", path[0])
+		}
+		return setPath(v.Elem(), path, raw)
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return fmt.Errorf("%s: nil value", path[0])
+		}
+		inner := v.Elem()
+		if inner.Kind() == reflect.Pointer {
+			return setPath(inner, path, raw)
+		}
+		// Значення в інтерфейсі незмінне - міняємо копію і кладемо її назад
+		copied := reflect.New(inner.Type()).Elem()
+		copied.Set(inner)
+		if err := setPath(copied, path, raw); err != nil {
+			return err
+		}
+		v.Set(copied)
+		return nil
+
+	case reflect.Struct:
+		f := v.FieldByName(path[0])
+		if !f.IsValid() {
+			return fmt.Errorf("no field %q in %s", path[0], v.Type())
+		}
+		if !f.CanSet() {
+			return fmt.Errorf("field %q of %s is not exported", path[0], v.Type())
+		}
+		return setPath(f, path[1:], raw)
+
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("%s: only maps with string keys are supported", v.Type())
+		}
+		key := reflect.ValueOf(path[0]).Convert(v.Type().Key())
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if old := v.MapIndex(key); old.IsValid() {
+			elem.Set(old)
+		}
+		if err := setPath(elem, path[1:], raw); err != nil {
+			return err
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		v.SetMapIndex(key, elem)
+		return nil
+
+	default:
+		return fmt.Errorf("can't go into %s with %q", v.Type(), path[0])
+	}
+}
+
+func assign(v reflect.Value, raw string) error {
+	if v.Kind() == reflect.Interface {
+		return fmt.Errorf("%s has no concrete type to parse into; set its fields instead", v.Type())
+	}
+	if !v.CanAddr() {
+		return fmt.Errorf("%s is not settable", v.Type())
+	}
+
+	target := reflect.New(v.Type())
+	if err := json.Unmarshal([]byte(raw), target.Interface()); err != nil {
+		// Рядки зручно писати без лапок: Strategy=random
+		if v.Kind() == reflect.String {
+			v.SetString(raw)
+			return nil
+		}
+		return fmt.Errorf("parse %q as %s: %w", raw, v.Type(), err)
+	}
+	v.Set(target.Elem())
+	return nil
+}
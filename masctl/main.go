@@ -0,0 +1,242 @@
+// masctl - офлайн-інспектор і редактор збережених світів (world.gob).
+//
+// Типи агентів підключаються як бібліотеки тих самих програм (-app),
+// тому masctl читає і пише файл так само, як System.Startup/Shutdown:
+//
+//	masctl -app boss -f world.gob list
+//	masctl -app boss dump workers-1
+//	masctl -app boss set workers-1 Count=10
+//	masctl -app counter set worker-2 State.Count=3
+//	masctl -app boss delete boss-1
+//	masctl -app boss diff old.gob
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	bossmodels "github.com/youryharchenko/go-mas/boss/models"
+	countermodels "github.com/youryharchenko/go-mas/counter/models"
+	guilogmodels "github.com/youryharchenko/go-mas/gui-log/models"
+	"github.com/youryharchenko/go-mas/mas"
+
+	// Бібліотечні типи реєструються в init() своїх пакетів
+	_ "github.com/youryharchenko/go-mas/bdi"
+	_ "github.com/youryharchenko/go-mas/behaviours"
+	_ "github.com/youryharchenko/go-mas/routing"
+)
+
+// apps - набори типів програм. Разом їх не зареєструвати:
+// у кожної програми свій "*main.WorkerBot".
+var apps = map[string]func(){
+	"boss":    bossmodels.Register,
+	"counter": countermodels.Register,
+	"gui-log": guilogmodels.Register,
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: masctl [-app NAME] [-f FILE] COMMAND [ARGS]\n\n")
+	fmt.Fprintf(out, "Commands:\n")
+	fmt.Fprintf(out, "  list                       агенти та їхні типи\n")
+	fmt.Fprintf(out, "  dump [ID...]               стан агентів у JSON\n")
+	fmt.Fprintf(out, "  set ID PATH=VALUE...       змінити поле (VALUE - JSON або рядок)\n")
+	fmt.Fprintf(out, "  delete ID...               видалити агентів\n")
+	fmt.Fprintf(out, "  diff OTHER                 порівняти з іншим файлом\n\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	appName := flag.String("app", "", "чиї типи підключити: "+strings.Join(appNames(), ", "))
+	file := flag.String("f", "world.gob", "файл світу")
+	out := flag.String("o", "", "куди записати результат set/delete (за замовчуванням - у той самий файл)")
+	flag.Usage = usage
+	flag.Parse()
+
+	if err := run(*appName, *file, *out, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, "masctl:", err)
+		os.Exit(1)
+	}
+}
+
+func run(appName, file, out string, args []string) error {
+	if len(args) == 0 {
+		usage()
+		return errors.New("no command")
+	}
+	if appName != "" {
+		register, ok := apps[appName]
+		if !ok {
+			return fmt.Errorf("unknown -app %q (known: %s)", appName, strings.Join(appNames(), ", "))
+		}
+		register()
+	}
+	if out == "" {
+		out = file
+	}
+
+	world, err := load(file)
+	if err != nil {
+		return err
+	}
+
+	cmd, args := args[0], args[1:]
+	switch cmd {
+	case "list":
+		return list(world)
+
+	case "dump":
+		return dump(world, args)
+
+	case "set":
+		if len(args) < 2 {
+			return errors.New("usage: set ID PATH=VALUE...")
+		}
+		agent, ok := world[args[0]]
+		if !ok {
+			return fmt.Errorf("agent %q not found in %s", args[0], file)
+		}
+		for _, assignment := range args[1:] {
+			path, value, found := strings.Cut(assignment, "=")
+			if !found {
+				return fmt.Errorf("bad assignment %q: want PATH=VALUE", assignment)
+			}
+			if err := setField(agent, path, value); err != nil {
+				return fmt.Errorf("set %s.%s: %w", args[0], path, err)
+			}
+		}
+		return save(out, world)
+
+	case "delete":
+		if len(args) == 0 {
+			return errors.New("usage: delete ID...")
+		}
+		for _, id := range args {
+			if _, ok := world[id]; !ok {
+				return fmt.Errorf("agent %q not found in %s", id, file)
+			}
+			delete(world, id)
+		}
+		// Діти видалених агентів після Startup житимуть без батька - попереджаємо
+		for _, id := range sortedIDs(world) {
+			if p, ok := world[id].(interface{ Parent() string }); ok && slices.Contains(args, p.Parent()) {
+				fmt.Fprintf(os.Stderr, "masctl: warning: %s is a child of deleted %s\n", id, p.Parent())
+			}
+		}
+		return save(out, world)
+
+	case "diff":
+		if len(args) != 1 {
+			return errors.New("usage: diff OTHER")
+		}
+		other, err := load(args[0])
+		if err != nil {
+			return err
+		}
+		return diff(world, other)
+
+	default:
+		usage()
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+}
+
+// notRegistered витягує ім'я типу з помилки GOB про незареєстрований тип.
+var notRegistered = regexp.MustCompile(`name not registered for interface: "([^"]+)"`)
+
+func load(file string) (map[string]mas.Agent, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	world, err := mas.DecodeWorld(f)
+	if err != nil {
+		if m := notRegistered.FindStringSubmatch(err.Error()); m != nil {
+			return nil, fmt.Errorf("%s: type %s is not registered; choose the program that wrote the file with -app (%s)",
+				file, m[1], strings.Join(appNames(), ", "))
+		}
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return world, nil
+}
+
+// save пише у тимчасовий файл і підміняє ним старий, щоб не зіпсувати світ на півдорозі.
+func save(file string, world map[string]mas.Agent) error {
+	tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := mas.EncodeWorld(tmp, world); err != nil {
+		tmp.Close()
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+func list(world map[string]mas.Agent) error {
+	for _, id := range sortedIDs(world) {
+		line := fmt.Sprintf("%-24s %T", id, world[id])
+		if p, ok := world[id].(interface{ Parent() string }); ok && p.Parent() != "" {
+			line += "  (child of " + p.Parent() + ")"
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+// dumpEntry - агент у JSON разом з його Go-типом.
+type dumpEntry struct {
+	Type  string    `json:"type"`
+	State mas.Agent `json:"state"`
+}
+
+func dump(world map[string]mas.Agent, ids []string) error {
+	if len(ids) == 0 {
+		ids = sortedIDs(world)
+	}
+	entries := make(map[string]dumpEntry, len(ids))
+	for _, id := range ids {
+		agent, ok := world[id]
+		if !ok {
+			return fmt.Errorf("agent %q not found", id)
+		}
+		entries[id] = dumpEntry{Type: fmt.Sprintf("%T", agent), State: agent}
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+func sortedIDs(world map[string]mas.Agent) []string {
+	ids := make([]string, 0, len(world))
+	for id := range world {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func appNames() []string {
+	names := make([]string, 0, len(apps))
+	for name := range apps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
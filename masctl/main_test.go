@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	countermodels "github.com/youryharchenko/go-mas/counter/models"
+	"github.com/youryharchenko/go-mas/mas"
+)
+
+// ghostAgent пишеться у файл під іменем, яке потім підміняємо на незареєстроване.
+type ghostAgent struct {
+	mas.BaseAgent
+	Count int
+	Tags  map[string]int
+	note  string
+}
+
+func init() {
+	gob.RegisterName("masctl.ghost1", &ghostAgent{})
+}
+
+// writeWorld зберігає світ у тимчасовий файл.
+func writeWorld(t *testing.T, world map[string]mas.Agent) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "world.gob")
+	if err := save(file, world); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestSetField(t *testing.T) {
+	countermodels.Register()
+	planner := mas.NewPlannerAgent("worker-1", "counter", countermodels.CounterState{Count: 1})
+	ghost := &ghostAgent{BaseAgent: mas.BaseAgent{IDVal: "ghost"}}
+
+	for _, tt := range []struct {
+		agent mas.Agent
+		path  string
+		raw   string
+	}{
+		{planner, "State.Count", "7"}, // Значення всередині інтерфейсу
+		{planner, "PlannerName", "other"},
+		{ghost, "Tags.red", "2"},
+		{ghost, "Count", "3"},
+	} {
+		if err := setField(tt.agent, tt.path, tt.raw); err != nil {
+			t.Fatalf("set %s=%s: %v", tt.path, tt.raw, err)
+		}
+	}
+	if st := planner.State.(countermodels.CounterState); st.Count != 7 || planner.PlannerName != "other" {
+		t.Fatalf("planner %+v", planner)
+	}
+	if ghost.Count != 3 || ghost.Tags["red"] != 2 {
+		t.Fatalf("ghost %+v", ghost)
+	}
+
+	for path, want := range map[string]string{
+		"IDVal":     "can't be changed",
+		"note":      "not exported",
+		"Missing":   "no field",
+		"Count.Sub": "can't go into int",
+	} {
+		if err := setField(ghost, path, "1"); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("set %s: %v, want ...%s...", path, err, want)
+		}
+	}
+	if err := setField(ghost, "Count", "many"); err == nil {
+		t.Fatal("set Count=many succeeded")
+	}
+}
+
+func TestRunSetAndDelete(t *testing.T) {
+	parent := mas.NewPlannerAgent("worker-1", "counter", countermodels.CounterState{})
+	child := mas.NewPlannerAgent("worker-2", "counter", countermodels.CounterState{})
+	child.SetParent("worker-1")
+	file := writeWorld(t, map[string]mas.Agent{"worker-1": parent, "worker-2": child})
+	out := filepath.Join(t.TempDir(), "edited.gob")
+
+	if err := run("counter", file, out, []string{"set", "worker-2", "State.Count=5"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := run("counter", out, "", []string{"delete", "worker-1"}); err != nil {
+		t.Fatal(err)
+	}
+	edited, err := load(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := sortedIDs(edited); !slices.Equal(ids, []string{"worker-2"}) {
+		t.Fatalf("agents %v", ids)
+	}
+	if st := edited["worker-2"].(*mas.PlannerAgent).State.(countermodels.CounterState); st.Count != 5 {
+		t.Fatalf("count %d, want 5", st.Count)
+	}
+
+	// Вихідний файл не змінено (-o), а різниця видна по полях
+	before, err := load(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fb, _ := flatten(before["worker-2"])
+	fa, _ := flatten(edited["worker-2"])
+	if lines := diffFields(fb, fa); !slices.Equal(lines, []string{"State.Count: 0 -> 5"}) {
+		t.Fatalf("diff %v", lines)
+	}
+
+	for _, args := range [][]string{
+		{"set", "ghost", "Count=1"},
+		{"set", "worker-1", "Count"},
+		{"delete", "ghost"},
+		{"explode"},
+	} {
+		if err := run("counter", file, out, args); err == nil {
+			t.Fatalf("%v succeeded", args)
+		}
+	}
+	if err := run("nope", file, "", []string{"list"}); err == nil || !strings.Contains(err.Error(), "unknown -app") {
+		t.Fatalf("unknown app: %v", err)
+	}
+}
+
+func TestLoadUnregisteredType(t *testing.T) {
+	file := writeWorld(t, map[string]mas.Agent{"ghost": &ghostAgent{BaseAgent: mas.BaseAgent{IDVal: "ghost"}}})
+
+	// Файл від програми, чиїх типів masctl не знає: те саме ім'я, але інша цифра
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = bytes.ReplaceAll(data, []byte("masctl.ghost1"), []byte("masctl.ghost2"))
+	if err := os.WriteFile(file, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = load(file)
+	if err == nil || !strings.Contains(err.Error(), "type masctl.ghost2 is not registered") || !strings.Contains(err.Error(), "-app") {
+		t.Fatalf("got %v, want a hint about -app", err)
+	}
+}
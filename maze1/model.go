@@ -42,7 +42,7 @@ type MazeAgent struct {
 func (m *MazeAgent) Plan(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
 
 	// Обробка запиту на рух
-	if req, ok := msg.Payload.(MoveRequest); ok {
+	if req, ok := mas.PayloadAs[MoveRequest](msg); ok {
 		if m.Finished {
 			return nil, nil // Гра закінчена
 		}
@@ -134,14 +134,12 @@ func (w *WalkerBot) Plan(ctx context.Context, msg mas.Envelope) ([]mas.Action, e
 
 		return []mas.Action{
 			// Шлемо запит Лабіринту
-			func(a mas.Agent, sys *mas.System) error {
-				return sys.Send(ctx, w.IDVal, w.MazeID, MoveRequest{Dir: randomDir})
-			},
+			mas.Send(w.MazeID, MoveRequest{Dir: randomDir}),
 		}, nil
 	}
 
 	// 2. Реакція на відповідь від стіни/проходу
-	if res, ok := msg.Payload.(MoveResult); ok {
+	if res, ok := mas.PayloadAs[MoveResult](msg); ok {
 		if res.IsFinished {
 			return []mas.Action{mas.Send("console", "Walker: I WON! STOPPING.")}, nil
 		}
@@ -156,8 +154,8 @@ func (w *WalkerBot) Plan(ctx context.Context, msg mas.Envelope) ([]mas.Action, e
 }
 
 func init() {
-	gob.Register(&MoveRequest{})
-	gob.Register(&MoveResult{})
-	gob.Register(&MazeAgent{})
-	gob.Register(&WalkerBot{})
+	gob.Register(MoveRequest{})
+	gob.Register(MoveResult{})
+	mas.RegisterType("MazeAgent", func() mas.Agent { return &MazeAgent{} })
+	mas.RegisterType("WalkerBot", func() mas.Agent { return &WalkerBot{} })
 }
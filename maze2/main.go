@@ -3,19 +3,22 @@ package main
 import (
 	"context"
 	"log"
-	"strings"
 	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/data/binding"
+	"github.com/youryharchenko/go-mas/command"
 	"github.com/youryharchenko/go-mas/mas"
+	"github.com/youryharchenko/go-mas/ui"
 )
 
 func main() {
 	// Запускаємо MAS
-	sys := mas.NewSystem()
+	// Консоль діє від імені admin: цю ідентичність System видає лише їй
+	admin := mas.NewCredential("admin")
+	sys := mas.NewSystem(mas.WithTrustedIdentity(admin))
 
 	// 1. Створюємо Fyne App
 	myApp := app.New()
@@ -32,15 +35,10 @@ func main() {
 	outputEntry.Bind(logData)
 	outputEntry.TextStyle = fyne.TextStyle{Monospace: true}
 
-	inputEntry := NewHistoryEntry()
-	inputEntry.PlaceHolder = "Enter command: TARGET PAYLOAD (use Up/Down for history)..."
+	inputEntry := ui.NewHistoryEntry()
+	inputEntry.PlaceHolder = "Enter command: TARGET COMMAND (HELP, Tab - completion, Up/Down - history)..."
 
-	inputEntry.OnSubmitted = func(text string) {
-		if text == "" {
-			return
-		}
-
-	}
+	inputEntry.UseCommands(sys, admin, consoleCommands(), logData)
 
 	// Для краси загорнемо в скрол
 	scroll := container.NewVScroll(outputEntry)
@@ -103,11 +101,14 @@ func main() {
 	// Глобальний таймер світу
 	go func() {
 		ticker := time.NewTicker(500 * time.Millisecond) // 2 ходи на секунду
+		defer ticker.Stop()
 		for {
 			select {
 			case <-ticker.C:
-				// Пінаємо волкера, щоб він думав
-				sys.Send(context.Background(), "admin", "walker-1", "TICK")
+				// Пінаємо волкера, щоб він думав (пропускаємо тік, якщо він не встигає)
+				sys.TrySend(sys.Context(), "admin", "walker-1", "TICK")
+			case <-sys.Context().Done():
+				return
 			}
 		}
 	}()
@@ -119,22 +120,17 @@ func main() {
 
 	sys.Kill("console") // Видаляємо агента, щоб не зберігати його у файл
 	sys.Kill("admin")
-	if err := sys.Shutdown(); err != nil {
+	// Завислий агент (наприклад, Plan, що чекає на повну скриньку) не має тримати вікно
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := sys.Shutdown(ctx); err != nil {
 		log.Println(err)
 	}
 }
 
-// splitCommand ділить рядок на два: "WORD1 REST OF STRING"
-func splitCommand(input string) []string {
-	return strings.SplitN(input, " ", 2)
-}
-
-// appendLog безпечно додає рядок до binding
-func appendLog(data binding.String, text string) {
-	current, _ := data.Get()
-	// Якщо лог дуже довгий, можна обрізати початок, щоб не їсти пам'ять
-	if len(current) > 5000 {
-		current = current[len(current)-4000:] // залишаємо останні 4000 символів
-	}
-	data.Set(current + "\n" + text)
+// consoleCommands - команди, які розуміють агенти цього лабіринту.
+func consoleCommands() *command.Language {
+	return command.NewLanguage(
+		command.Word("TICK", "крок волкера"),
+	)
 }
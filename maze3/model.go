@@ -56,9 +56,7 @@ func (m *MazeAgent) Plan(ctx context.Context, msg mas.Envelope) ([]mas.Action, e
 				maze.Finished = false
 			}),
 			// Скидаємо мізки Волкеру!
-			func(a mas.Agent, sys *mas.System) error {
-				return sys.Send(ctx, m.IDVal, m.WalkerID, "RESET")
-			},
+			mas.Send(m.WalkerID, "RESET"),
 			mas.Send("console", "Maze: Generated new random level! Resetting walker..."),
 			// Показуємо нову карту
 			mas.Send("console", renderMap(newMap, 1, 1)), // func renderMap - це ваш код малювання
@@ -66,7 +64,7 @@ func (m *MazeAgent) Plan(ctx context.Context, msg mas.Envelope) ([]mas.Action, e
 	}
 
 	// Обробка запиту на рух
-	if req, ok := msg.Payload.(MoveRequest); ok {
+	if req, ok := mas.PayloadAs[MoveRequest](msg); ok {
 		if m.Finished {
 			return nil, nil // Гра закінчена
 		}
@@ -285,9 +283,7 @@ func (w *PlannerWalker) Plan(ctx context.Context, msg mas.Envelope) ([]mas.Actio
 			w.LastMove = move
 			w.IsBacktracking = false
 			return []mas.Action{
-				func(a mas.Agent, sys *mas.System) error {
-					return sys.Send(ctx, w.IDVal, w.MazeID, MoveRequest{Dir: move})
-				},
+				mas.Send(w.MazeID, MoveRequest{Dir: move}),
 			}, nil
 		}
 
@@ -298,14 +294,12 @@ func (w *PlannerWalker) Plan(ctx context.Context, msg mas.Envelope) ([]mas.Actio
 
 		return []mas.Action{
 			mas.Send("console", "Planner: Dead end. Backtracking..."),
-			func(a mas.Agent, sys *mas.System) error {
-				return sys.Send(ctx, w.IDVal, w.MazeID, MoveRequest{Dir: w.LastMove})
-			},
+			mas.Send(w.MazeID, MoveRequest{Dir: w.LastMove}),
 		}, nil
 	}
 
 	// 2. ОБРОБКА РЕЗУЛЬТАТУ ХОДУ
-	if res, ok := msg.Payload.(MoveResult); ok {
+	if res, ok := mas.PayloadAs[MoveResult](msg); ok {
 		if res.IsFinished {
 			return []mas.Action{
 				mas.MutateState(func(a any) {
@@ -370,8 +364,8 @@ func (w *PlannerWalker) Plan(ctx context.Context, msg mas.Envelope) ([]mas.Actio
 	return nil, nil
 }
 func init() {
-	gob.Register(&MoveRequest{})
-	gob.Register(&MoveResult{})
-	gob.Register(&MazeAgent{})
-	gob.Register(&PlannerWalker{})
+	gob.Register(MoveRequest{})
+	gob.Register(MoveResult{})
+	mas.RegisterType("MazeAgent", func() mas.Agent { return &MazeAgent{} })
+	mas.RegisterType("PlannerWalker", func() mas.Agent { return &PlannerWalker{} })
 }
@@ -0,0 +1,32 @@
+package planning
+
+import "sync"
+
+// VisitedMemory - потокобезпечна Memory для будь-якого стану.
+// Стани розрізняються за String(), тож State не мусить бути comparable.
+type VisitedMemory[S State] struct {
+	visited sync.Map // String() -> S
+}
+
+// NewMemory створює порожню пам'ять (типова, коли агент не дав власної).
+func NewMemory[S State]() *VisitedMemory[S] {
+	return &VisitedMemory[S]{}
+}
+
+func (m *VisitedMemory[S]) Remember(s S) {
+	m.visited.Store(s.String(), s)
+}
+
+func (m *VisitedMemory[S]) HasVisited(s S) bool {
+	_, exists := m.visited.Load(s.String())
+	return exists
+}
+
+func (m *VisitedMemory[S]) Clear() {
+	m.visited.Clear()
+}
+
+// Range обходить пам'ять: key - String() стану, value - сам стан.
+func (m *VisitedMemory[S]) Range(f func(key, value any) bool) {
+	m.visited.Range(f)
+}
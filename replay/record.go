@@ -0,0 +1,253 @@
+// Package replay - запис повідомлень системи і покрокове відтворення ("машина часу").
+//
+// Recorder через mas.System.Hook пише у файл кожне оброблене повідомлення
+// з часом і хешами стану агента до і після обробки, а перед першим повідомленням
+// агента - ще й копію його стану. Replayer будує з цих копій свіжу синхронну
+// систему і доставляє записані повідомлення по одному: зупиняється на точках
+// зупину, а розбіжність хешів показує недетермінізм (випадковість, час, гонки).
+//
+//	rec, _ := replay.Start(sys, "walker.rec", replay.OnlyAgents("walker-*", "maze-*"))
+//	... система працює ...
+//	rec.Stop()
+//
+//	rp, _ := replay.Load("walker.rec")
+//	rp.Break(replay.OnAgent("walker-1"))
+//	for step, ok := rp.Continue(); ok; step, ok = rp.Continue() {
+//		if step.Diverged() {
+//			t.Errorf("step %d: state differs from the recording", step.Record.Seq)
+//		}
+//	}
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/youryharchenko/go-mas/mas"
+)
+
+// Record - одне оброблене повідомлення.
+type Record struct {
+	Seq      int // Порядковий номер у записі (з 1)
+	Time     time.Time
+	Agent    string // Хто обробляв
+	Envelope mas.Envelope
+
+	Pre, Post string // StateHash агента до і після обробки ("" - стан не хешується)
+
+	// Initial - копія агента перед першим його повідомленням у записі
+	// (з неї Replayer відновлює агента); nil для наступних.
+	Initial mas.Agent
+
+	// Missing - чому запис не можна відтворити (payload або стан не серіалізуються).
+	Missing string
+}
+
+// StateHash - короткий хеш стану агента: SHA-256 від JSON експортованих полів
+// (JSON сортує ключі мап, тож хеш стабільний). Порожній, якщо стан не серіалізується в JSON.
+func StateHash(a mas.Agent) string {
+	data, err := json.Marshal(a)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// Option - налаштування Recorder.
+type Option func(*Recorder)
+
+// OnlyAgents записує лише повідомлення агентів, чиї ID підходять під маски path.Match.
+func OnlyAgents(patterns ...string) Option {
+	return func(r *Recorder) {
+		r.only = append(r.only, patterns...)
+	}
+}
+
+// Recorder пише оброблені повідомлення у потік (формат - WriteRecord).
+type Recorder struct {
+	sys    *mas.System
+	only   []string
+	unhook func()
+
+	mu     sync.Mutex
+	w      *bufio.Writer
+	closer io.Closer // Файл, якщо його відкрив Start
+	seq    int
+	seen   map[string]bool // Агенти, для яких уже записано Initial
+	err    error
+	done   bool // Stop уже викликано
+}
+
+// NewRecorder починає запис повідомлень sys (і її підсистем) у w.
+func NewRecorder(sys *mas.System, w io.Writer, opts ...Option) *Recorder {
+	r := &Recorder{
+		sys:  sys,
+		w:    bufio.NewWriter(w),
+		seen: make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	r.unhook = sys.Hook(r.hook)
+	return r
+}
+
+// Start починає запис у файл path (створює або перезаписує його).
+func Start(sys *mas.System, path string, opts ...Option) (*Recorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	r := NewRecorder(sys, f, opts...)
+	r.closer = f
+	return r, nil
+}
+
+// Len - скільки записів уже зроблено.
+func (r *Recorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.seq
+}
+
+// Stop зупиняє запис, дописує буфер і закриває файл (якщо його відкрив Start).
+// Повертає першу помилку запису.
+func (r *Recorder) Stop() error {
+	r.unhook()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.done = true
+	err := r.w.Flush()
+	if r.closer != nil {
+		err = errors.Join(err, r.closer.Close())
+		r.closer = nil
+	}
+	return errors.Join(r.err, err)
+}
+
+func (r *Recorder) records(id string) bool {
+	if len(r.only) == 0 {
+		return true
+	}
+	for _, p := range r.only {
+		if ok, _ := path.Match(p, id); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// hook - mas.DeliveryHook: виконується в горутині агента.
+func (r *Recorder) hook(agent mas.Agent, env mas.Envelope, next func()) {
+	id := agent.ID()
+	if !r.records(id) {
+		next()
+		return
+	}
+
+	rec := Record{Time: r.sys.Clock().Now(), Agent: id, Envelope: env, Pre: StateHash(agent)}
+
+	r.mu.Lock()
+	first := !r.seen[id]
+	r.seen[id] = true
+	r.mu.Unlock()
+	if first {
+		initial, err := mas.CloneAgent(agent)
+		if err != nil {
+			rec.Missing = fmt.Sprintf("no snapshot of %s: %v", id, err)
+		}
+		rec.Initial = initial
+	}
+
+	next()
+	rec.Post = StateHash(agent)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.done {
+		return // Повідомлення обробилось, поки запис зупинявся
+	}
+	r.seq++
+	rec.Seq = r.seq
+	if err := WriteRecord(r.w, rec); err != nil {
+		// Найчастіше - незареєстрований у GOB payload: пишемо запис без нього
+		rec.Envelope.Payload = fmt.Sprintf("%+v", env.Payload)
+		rec.Missing = fmt.Sprintf("not recorded: %v", err)
+		err = WriteRecord(r.w, rec)
+		if err != nil && rec.Initial != nil {
+			// Не кодується й сам агент: знімок зробимо перед наступним його повідомленням
+			rec.Initial = nil
+			delete(r.seen, id)
+			err = WriteRecord(r.w, rec)
+		}
+		if err != nil && r.err == nil {
+			r.err = err
+			log.Printf("Recorder: %v", err)
+		}
+	}
+}
+
+// WriteRecord пише один запис: довжина (uvarint) і самостійний GOB-блок.
+// Кожен блок має власний кодер, тож зіпсований запис не ламає решту файлу.
+func WriteRecord(w io.Writer, rec Record) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(rec); err != nil {
+		return fmt.Errorf("record %d (%s): %w", rec.Seq, rec.Agent, err)
+	}
+	var size [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(size[:], uint64(buf.Len()))
+	if _, err := w.Write(size[:n]); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// Read читає всі записи потоку (типи payload і агентів мають бути зареєстровані в GOB).
+func Read(r io.Reader) ([]Record, error) {
+	br := bufio.NewReader(r)
+	var records []Record
+	for {
+		size, err := binary.ReadUvarint(br)
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return records, err
+		}
+		data := make([]byte, size)
+		if _, err := io.ReadFull(br, data); err != nil {
+			return records, fmt.Errorf("record %d: %w", len(records)+1, err)
+		}
+		var rec Record
+		if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&rec); err != nil {
+			return records, fmt.Errorf("record %d: %w", len(records)+1, err)
+		}
+		records = append(records, rec)
+	}
+}
+
+// ReadFile читає записи з файлу.
+func ReadFile(path string) ([]Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
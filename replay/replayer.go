@@ -0,0 +1,266 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/youryharchenko/go-mas/mas"
+)
+
+// Step - результат відтворення одного запису.
+type Step struct {
+	Record    Record
+	Pre, Post string         // StateHash агента при відтворенні
+	Sent      []mas.Envelope // Що агент відправив (у відтворенні нікуди не доставляється)
+	Skipped   string         // Чому запис не відтворено (тоді хеші порожні)
+}
+
+// Diverged - стан агента при відтворенні відрізняється від записаного:
+// обробка недетермінована (випадковість, час, спільний стан) або запис неповний.
+func (s Step) Diverged() bool {
+	if s.Skipped != "" || s.Record.Post == "" {
+		return false
+	}
+	return s.Pre != s.Record.Pre || s.Post != s.Record.Post
+}
+
+// Breakpoint - умова зупинки Continue перед записом.
+type Breakpoint func(rec Record) bool
+
+// AtSeq зупиняється перед записом з номером seq.
+func AtSeq(seq int) Breakpoint {
+	return func(rec Record) bool { return rec.Seq == seq }
+}
+
+// OnAgent зупиняється перед повідомленнями агентів, чиї ID підходять під маску path.Match.
+func OnAgent(pattern string) Breakpoint {
+	return func(rec Record) bool {
+		ok, _ := path.Match(pattern, rec.Agent)
+		return ok
+	}
+}
+
+// OnMessage зупиняється перед повідомленнями, що задовольняють m (mas.PayloadOf, mas.PayloadEquals...).
+func OnMessage(m mas.Matcher) Breakpoint {
+	return func(rec Record) bool { return m(rec.Envelope) }
+}
+
+// Replayer відтворює запис у свіжій синхронній системі (mas.WithDispatcher):
+// агенти не мають горутин, повідомлення доставляються лише з запису,
+// а все, що агенти відправляють, збирається в Step.Sent.
+type Replayer struct {
+	Records []Record
+
+	// StopOnDivergence - Continue зупиняється після кроку, що розійшовся із записом.
+	StopOnDivergence bool
+
+	sys    *mas.System
+	cancel context.CancelFunc
+	clock  *replayClock
+	pos    int
+	steps  []Step
+	sent   []mas.Envelope
+	breaks []Breakpoint
+}
+
+// NewReplayer готує відтворення записів (позиція - перед першим).
+func NewReplayer(records []Record) *Replayer {
+	r := &Replayer{Records: records, StopOnDivergence: true}
+	r.Reset()
+	return r
+}
+
+// Load читає файл запису і готує відтворення.
+func Load(path string) (*Replayer, error) {
+	records, err := ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplayer(records), nil
+}
+
+// Reset повертає відтворення на початок: нова система з початкових копій агентів.
+func (r *Replayer) Reset() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r.clock = &replayClock{}
+	r.cancel = cancel
+	r.sys = mas.NewSystem(mas.WithContext(ctx), mas.WithClock(r.clock), mas.WithDispatcher(r.collect))
+	r.pos = 0
+	r.steps = nil
+
+	// Усі агенти запису існують з самого початку: вони звертаються одне до одного
+	// (наприклад, волкер бере домен у лабіринту) ще до власних перших повідомлень
+	for _, rec := range r.Records {
+		if rec.Initial == nil {
+			continue
+		}
+		if _, exists := r.sys.GetAgent(rec.Agent); exists {
+			continue
+		}
+		// Копія: після наступного Reset агент має почати з того самого стану
+		if agent, err := mas.CloneAgent(rec.Initial); err == nil {
+			r.sys.Spawn(agent)
+		}
+	}
+}
+
+// collect - mas.Dispatcher відтворення: відправлене не доставляється, лише запам'ятовується.
+func (r *Replayer) collect(ctx context.Context, env mas.Envelope) error {
+	r.sent = append(r.sent, env)
+	return nil
+}
+
+// System - система відтворення (для Snapshot, Inspect, GetAgent).
+func (r *Replayer) System() *mas.System {
+	return r.sys
+}
+
+// Pos - скільки записів уже відтворено.
+func (r *Replayer) Pos() int {
+	return r.pos
+}
+
+// Done - чи відтворено весь запис.
+func (r *Replayer) Done() bool {
+	return r.pos >= len(r.Records)
+}
+
+// Steps - відтворені кроки (від останнього Reset).
+func (r *Replayer) Steps() []Step {
+	return r.steps
+}
+
+// Divergences - кроки, на яких стан розійшовся із записом.
+func (r *Replayer) Divergences() []Step {
+	var out []Step
+	for _, s := range r.steps {
+		if s.Diverged() {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Break додає точку зупину для Continue.
+func (r *Replayer) Break(bp Breakpoint) {
+	r.breaks = append(r.breaks, bp)
+}
+
+// ClearBreakpoints знімає всі точки зупину.
+func (r *Replayer) ClearBreakpoints() {
+	r.breaks = nil
+}
+
+// State повертає копію агента в поточній точці відтворення.
+func (r *Replayer) State(id string) (mas.Agent, error) {
+	return r.sys.Snapshot(id)
+}
+
+// Step відтворює наступний запис. false - запис закінчився.
+func (r *Replayer) Step() (Step, bool) {
+	if r.Done() {
+		return Step{}, false
+	}
+	rec := r.Records[r.pos]
+	r.pos++
+	r.clock.now = rec.Time
+
+	step := Step{Record: rec}
+	agent, exists := r.sys.GetAgent(rec.Agent)
+	switch {
+	case rec.Missing != "":
+		step.Skipped = rec.Missing
+	case !exists:
+		step.Skipped = fmt.Sprintf("agent %s has no snapshot in the recording", rec.Agent)
+	default:
+		env := rec.Envelope
+		// Дедлайн уже минув - у записі його ще не було
+		env.Deadline = time.Time{}
+
+		r.sent = nil
+		step.Pre = StateHash(agent)
+		deliver(agent, r.sys, env)
+		step.Post = StateHash(agent)
+		step.Sent = r.sent
+	}
+	r.steps = append(r.steps, step)
+	return step, true
+}
+
+// Continue відтворює записи, доки наступний не потрапить на точку зупину,
+// крок не розійдеться із записом (StopOnDivergence) або запис не скінчиться.
+// Повертає останній відтворений крок; false - нічого не відтворено (кінець запису).
+func (r *Replayer) Continue() (Step, bool) {
+	step, ok := r.Step()
+	if !ok {
+		return step, false
+	}
+	for !r.Done() {
+		if r.StopOnDivergence && step.Diverged() {
+			break
+		}
+		if r.breakpointAt(r.Records[r.pos]) {
+			break
+		}
+		step, _ = r.Step()
+	}
+	return step, true
+}
+
+func (r *Replayer) breakpointAt(rec Record) bool {
+	for _, bp := range r.breaks {
+		if bp(rec) {
+			return true
+		}
+	}
+	return false
+}
+
+// Seek переходить у позицію pos (скільки записів відтворено): назад - через Reset.
+func (r *Replayer) Seek(pos int) {
+	pos = max(0, min(pos, len(r.Records)))
+	if pos < r.pos {
+		r.Reset()
+	}
+	for r.pos < pos {
+		r.Step()
+	}
+}
+
+// deliver обробляє конверт так само, як BaseAgent у своїй горутині (див. mastest).
+func deliver(agent mas.Agent, sys *mas.System, env mas.Envelope) {
+	ctx := context.Background()
+	if d, ok := agent.(interface {
+		Deliver(ctx context.Context, msg mas.Envelope)
+	}); ok {
+		d.Deliver(ctx, env)
+		return
+	}
+
+	actions, err := agent.Plan(ctx, env)
+	if err != nil {
+		return
+	}
+	for _, action := range actions {
+		action(ctx, agent, sys)
+	}
+}
+
+// replayClock - час відтворення: Now - час поточного запису.
+// Таймери не спрацьовують: їхні повідомлення вже є в записі.
+type replayClock struct {
+	now time.Time
+}
+
+func (c *replayClock) Now() time.Time { return c.now }
+
+func (c *replayClock) AfterFunc(d time.Duration, f func()) mas.Timer { return stoppedTimer{} }
+
+type stoppedTimer struct{}
+
+func (stoppedTimer) Stop() bool { return false }
@@ -0,0 +1,288 @@
+// Package routing - агенти-маршрутизатори, що стоять перед пулом однакових агентів (routees).
+//
+// Router пересилає повідомлення одному або всім агентам пулу за стратегією,
+// а сам пул породжує з шаблону як своїх дітей, тож його можна масштабувати на льоту:
+//
+//	workers := routing.NewRouter("workers", routing.RoundRobin, 3, &WorkerBot{})
+//	sys.Spawn(workers)
+//	sys.Send(ctx, "main", "workers", routing.Resize{Size: 5})
+package routing
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/youryharchenko/go-mas/mas"
+)
+
+// Strategy - як Router обирає отримувача.
+type Strategy string
+
+const (
+	RoundRobin      Strategy = "round-robin"      // По черзі
+	Random          Strategy = "random"           // Випадковий
+	Broadcast       Strategy = "broadcast"        // Усім
+	ConsistentHash  Strategy = "consistent-hash"  // Той самий ключ - той самий агент (HashKey)
+	SmallestMailbox Strategy = "smallest-mailbox" // Найменш завантажена скринька (Backpressure)
+)
+
+// Hashable - payload з ключем для ConsistentHash (наприклад, ID клієнта).
+// Для інших payload ключем є fmt.Sprint(payload).
+type Hashable interface {
+	HashKey() string
+}
+
+// Resize - змінити розмір пулу (зайві агенти зупиняються, бракуючі породжуються).
+type Resize struct {
+	Size int
+}
+
+// BroadcastMsg - переслати Payload усім агентам пулу, незалежно від стратегії.
+type BroadcastMsg struct {
+	Payload any
+}
+
+// GetRoutees - запит складу пулу; відповідь - Routees.
+type GetRoutees struct{}
+
+// Routees - поточний склад пулу.
+type Routees struct {
+	IDs []string
+}
+
+// Router - агент, що пересилає повідомлення агентам свого пулу.
+// Пересланий запит несе адресу відповіді автора (Envelope.ReplyTo), тож routee відповідає йому напряму (msg.ReplyAddr()).
+type Router struct {
+	mas.BaseAgent
+
+	Strategy Strategy
+	Template mas.Agent // З нього копіюються нові routees (тип має бути зареєстрований mas.RegisterType або gob.Register)
+	Size     int       // Бажаний розмір пулу
+
+	Next    int // Курсор RoundRobin
+	Spawned int // Лічильник для унікальних ID routees
+}
+
+// NewRouter створює маршрутизатор з пулом size копій template.
+func NewRouter(id string, strategy Strategy, size int, template mas.Agent) *Router {
+	return &Router{
+		BaseAgent: mas.BaseAgent{IDVal: id},
+		Strategy:  strategy,
+		Template:  template,
+		Size:      size,
+	}
+}
+
+// Bind реєструє обробники службових повідомлень; все інше маршрутизується.
+func (r *Router) Bind(sys *mas.System, inbox <-chan mas.Envelope, me mas.Agent) {
+	r.BaseAgent.Bind(sys, inbox, me)
+
+	mas.Handle(r, r.onResize)
+	mas.Handle(r, r.onBroadcast)
+	mas.Handle(r, r.onGetRoutees)
+	mas.HandleOther(r, r.onMessage)
+}
+
+// Routees повертає ID агентів пулу (діти маршрутизатора).
+func (r *Router) Routees() []string {
+	return r.Children()
+}
+
+func (r *Router) onResize(ctx context.Context, msg mas.Envelope, req Resize) ([]mas.Action, error) {
+	if req.Size < 0 {
+		return nil, fmt.Errorf("router %s: bad pool size %d", r.IDVal, req.Size)
+	}
+	return []mas.Action{
+		mas.MutateState(func(a any) {
+			a.(*Router).Size = req.Size
+		}),
+		r.reconcile(),
+	}, nil
+}
+
+func (r *Router) onBroadcast(ctx context.Context, msg mas.Envelope, req BroadcastMsg) ([]mas.Action, error) {
+	return []mas.Action{r.reconcile(), r.forward(msg, req.Payload, Broadcast)}, nil
+}
+
+func (r *Router) onGetRoutees(ctx context.Context, msg mas.Envelope, _ GetRoutees) ([]mas.Action, error) {
+	return []mas.Action{mas.Send(msg.ReplyAddr(), Routees{IDs: r.Routees()})}, nil
+}
+
+func (r *Router) onMessage(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
+	// Відповіді NOT_UNDERSTOOD і REFUSE нікуди не пересилаємо: їхній payload - наш же запит
+	if msg.Type == mas.NotUnderstood || msg.Type == mas.Refuse {
+		return nil, nil
+	}
+	return []mas.Action{r.reconcile(), r.forward(msg, msg.Payload, r.Strategy)}, nil
+}
+
+// reconcile - дія: привести пул до розміру Size.
+// Виконується як дія, бо породжувати й зупиняти агентів можна лише поза Plan.
+func (r *Router) reconcile() mas.Action {
+	return func(ctx context.Context, a mas.Agent, sys *mas.System) error {
+		router := a.(*Router)
+		routees := router.Routees()
+
+		for len(routees) > router.Size {
+			last := routees[len(routees)-1]
+			routees = routees[:len(routees)-1]
+			if err := sys.Stop(last); err != nil {
+				return err
+			}
+			router.MarkChanged()
+		}
+
+		for len(routees) < router.Size {
+			if router.Template == nil {
+				return fmt.Errorf("router %s: no template to spawn routees", router.IDVal)
+			}
+			child, err := mas.CloneAgent(router.Template)
+			if err != nil {
+				return err
+			}
+			idSetter, ok := child.(interface{ SetID(string) })
+			if !ok {
+				return fmt.Errorf("router %s: template %T can't change its ID", router.IDVal, child)
+			}
+			router.Spawned++
+			id := fmt.Sprintf("%s-%d", router.IDVal, router.Spawned)
+			idSetter.SetID(id)
+			if err := sys.SpawnChild(router.IDVal, child); err != nil {
+				return err
+			}
+			routees = append(routees, id)
+			router.MarkChanged()
+		}
+		return nil
+	}
+}
+
+// forward - дія: переслати payload обраним агентам; відповідати вони будуть авторові msg.
+func (r *Router) forward(msg mas.Envelope, payload any, strategy Strategy) mas.Action {
+	return func(ctx context.Context, a mas.Agent, sys *mas.System) error {
+		router := a.(*Router)
+		targets := router.pick(sys, payload, strategy)
+		if len(targets) == 0 {
+			log.Printf("Router %s: no routees, message from %s dropped", router.IDVal, msg.From)
+			return nil
+		}
+
+		// Відправник - сам роутер (його ставить System), а автор запиту - адреса відповіді
+		ctx = mas.WithReplyTo(ctx, msg.ReplyAddr())
+		if msg.Type != "" {
+			ctx = mas.WithPerformative(ctx, msg.Type)
+		}
+		// Невдача одного routee не зупиняє розсилку іншим
+		var errs []error
+		for _, id := range targets {
+			if err := sys.Send(ctx, router.IDVal, id, payload); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", id, err))
+			}
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// pick обирає отримувачів за стратегією.
+func (r *Router) pick(sys *mas.System, payload any, strategy Strategy) []string {
+	routees := r.Routees()
+	if len(routees) == 0 {
+		return nil
+	}
+
+	switch strategy {
+	case Broadcast:
+		return routees
+
+	case Random:
+		return []string{routees[rand.IntN(len(routees))]}
+
+	case ConsistentHash:
+		return []string{rendezvous(hashKey(payload), routees)}
+
+	case SmallestMailbox:
+		best, bestLoad := routees[0], 2.0
+		for _, id := range routees {
+			if load, ok := sys.Backpressure(id); ok && load < bestLoad {
+				best, bestLoad = id, load
+			}
+		}
+		return []string{best}
+
+	default: // RoundRobin
+		id := routees[r.Next%len(routees)]
+		r.Next = (r.Next + 1) % len(routees)
+		return []string{id}
+	}
+}
+
+// Load - зворотний тиск для продюсерів: завантаженість агента id, а якщо це Router -
+// ще й його пулу. Скринька самого роутера майже завжди порожня, бо він лише пересилає,
+// тож дивимось на routees: для SmallestMailbox - найвільніший (туди піде повідомлення),
+// для решти стратегій - найзавантаженіший (повідомлення може потрапити до будь-кого).
+func Load(sys *mas.System, id string) (float64, bool) {
+	load, ok := sys.Backpressure(id)
+	if !ok {
+		return 0, false
+	}
+	agent, _ := sys.GetAgent(id)
+	router, isRouter := agent.(*Router)
+	if !isRouter {
+		return load, true
+	}
+
+	var loads []float64
+	for _, rid := range router.Routees() {
+		if l, ok := sys.Backpressure(rid); ok {
+			loads = append(loads, l)
+		}
+	}
+	switch {
+	case len(loads) == 0:
+		return load, true
+	case router.Strategy == SmallestMailbox:
+		return max(load, slices.Min(loads)), true
+	default:
+		return max(load, slices.Max(loads)), true
+	}
+}
+
+func hashKey(payload any) string {
+	if h, ok := payload.(Hashable); ok {
+		return h.HashKey()
+	}
+	return fmt.Sprint(payload)
+}
+
+// rendezvous - консистентне хешування "найбільшої ваги" (HRW):
+// при зміні розміру пулу переїжджають лише ключі зниклих або нових агентів.
+func rendezvous(key string, routees []string) string {
+	var (
+		best   string
+		bestHs uint64
+	)
+	for _, id := range routees {
+		h := fnv.New64a()
+		h.Write([]byte(key))
+		h.Write([]byte{0})
+		h.Write([]byte(id))
+		if hs := h.Sum64(); best == "" || hs > bestHs {
+			best, bestHs = id, hs
+		}
+	}
+	return best
+}
+
+func init() {
+	gob.Register(&Router{})
+	gob.Register(Resize{})
+	gob.Register(BroadcastMsg{})
+	gob.Register(GetRoutees{})
+	gob.Register(Routees{})
+}
@@ -0,0 +1,184 @@
+package routing
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/youryharchenko/go-mas/mas"
+	"github.com/youryharchenko/go-mas/mas/mastest"
+)
+
+// Served - відповідь echoRoutee: хто обробив повідомлення.
+type Served struct {
+	By string
+	N  int
+}
+
+// echoRoutee відповідає авторові запиту, хто його обробив.
+type echoRoutee struct {
+	mas.BaseAgent
+}
+
+func (e *echoRoutee) Plan(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
+	n, _ := msg.Payload.(int)
+	return []mas.Action{mas.Send(msg.ReplyAddr(), Served{By: e.IDVal, N: n})}, nil
+}
+
+// slowRoutee зависає на кожному повідомленні, доки не закриють release.
+// Clone ділить з копіями канал.
+type slowRoutee struct {
+	mas.BaseAgent
+	release chan struct{}
+}
+
+func (s *slowRoutee) Plan(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
+	<-s.release
+	return nil, nil
+}
+
+func (s *slowRoutee) Clone() mas.Agent {
+	return &slowRoutee{BaseAgent: s.CloneBase(), release: s.release}
+}
+
+func init() {
+	mas.RegisterType("routing.echoRoutee", func() mas.Agent { return &echoRoutee{} })
+}
+
+func TestRoundRobin(t *testing.T) {
+	ts := mastest.New(t)
+	ts.Spawn(NewRouter("workers", RoundRobin, 3, &echoRoutee{}))
+
+	for i := range 6 {
+		ts.Send("client", "workers", i)
+	}
+	ts.RunUntilIdle()
+
+	// Відповідь іде авторові напряму, а не роутеру
+	for i := range 6 {
+		ts.ExpectMessage("client", mas.PayloadEquals(Served{By: fmt.Sprintf("workers-%d", i%3+1), N: i}))
+	}
+	ts.ExpectNoMessage("workers", mas.PayloadOf[Served]())
+}
+
+func TestResize(t *testing.T) {
+	ts := mastest.New(t)
+	ts.Spawn(NewRouter("workers", RoundRobin, 3, &echoRoutee{}))
+
+	ts.Send("client", "workers", Resize{Size: 5})
+	ts.Send("client", "workers", GetRoutees{})
+	ts.Send("client", "workers", Resize{Size: 2})
+	ts.Send("client", "workers", GetRoutees{})
+	ts.RunUntilIdle()
+
+	ts.ExpectMessage("client", mas.PayloadEquals(Routees{IDs: []string{"workers-1", "workers-2", "workers-3", "workers-4", "workers-5"}}))
+	ts.ExpectMessage("client", mas.PayloadEquals(Routees{IDs: []string{"workers-1", "workers-2"}}))
+	for _, id := range []string{"workers-3", "workers-4", "workers-5"} {
+		if _, ok := ts.Sys.GetAgent(id); ok {
+			t.Fatalf("%s still running after shrinking the pool", id)
+		}
+	}
+
+	// Нові routees отримують нові ID
+	ts.Send("client", "workers", Resize{Size: 3})
+	ts.Send("client", "workers", GetRoutees{})
+	ts.RunUntilIdle()
+	ts.ExpectMessage("client", mas.PayloadEquals(Routees{IDs: []string{"workers-1", "workers-2", "workers-6"}}))
+
+	if _, err := ts.Plan("workers", mas.Envelope{Payload: Resize{Size: -1}}); err == nil {
+		t.Fatal("negative size accepted")
+	}
+}
+
+// slowPool - система з маршрутизатором id на два slowRoutee; в скриньці першого routee черга з 4 повідомлень.
+func slowPool(t *testing.T, id string, strategy Strategy) (*mas.System, *Router) {
+	t.Helper()
+	sys := mas.NewSystem()
+	release := make(chan struct{})
+	t.Cleanup(func() {
+		close(release)
+		sys.Shutdown(context.Background())
+	})
+	router := NewRouter(id, strategy, 2, &slowRoutee{release: release})
+	sys.Spawn(router)
+
+	sys.Send(context.Background(), "main", id, Resize{Size: 2})
+	waitLoad(t, sys, id+"-1", func(float64) bool { return len(router.Routees()) == 2 })
+
+	// Перший routee зайнятий першим повідомленням, решта чекає в скриньці
+	for range 5 {
+		sys.Send(context.Background(), "main", id+"-1", "BUSY")
+	}
+	waitLoad(t, sys, id+"-1", func(l float64) bool { return l == 0.04 })
+	return sys, router
+}
+
+// waitLoad чекає, доки завантаженість агента id не задовольнить ok.
+func waitLoad(t *testing.T, sys *mas.System, id string, ok func(float64) bool) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+		l, _ := sys.Backpressure(id)
+		if ok(l) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%s load %v", id, l)
+		}
+	}
+}
+
+func TestSmallestMailbox(t *testing.T) {
+	sys, _ := slowPool(t, "workers", SmallestMailbox)
+
+	for i := range 3 {
+		sys.Send(context.Background(), "main", "workers", i)
+	}
+	// Усі три - вільнішому workers-2: одне в обробці, два в скриньці
+	waitLoad(t, sys, "workers-2", func(l float64) bool { return l >= 0.02 })
+	if l, _ := sys.Backpressure("workers-1"); l != 0.04 {
+		t.Fatalf("routed to the busy workers-1 (load %v)", l)
+	}
+}
+
+func TestLoadLooksAtRoutees(t *testing.T) {
+	// Скринька самого роутера порожня: важать скриньки routees
+	sys, _ := slowPool(t, "smallest", SmallestMailbox)
+	if l, ok := Load(sys, "smallest"); !ok || l != 0 {
+		t.Fatalf("smallest-mailbox Load = %v, %v; want the free routee's 0", l, ok)
+	}
+
+	sys, _ = slowPool(t, "rr", RoundRobin)
+	if l, ok := Load(sys, "rr"); !ok || l != 0.04 {
+		t.Fatalf("round-robin Load = %v, %v; want the busiest routee's 0.04", l, ok)
+	}
+	if l, ok := Load(sys, "rr-1"); !ok || l != 0.04 {
+		t.Fatalf("plain agent Load = %v, %v; want its own 0.04", l, ok)
+	}
+	if _, ok := Load(sys, "nobody"); ok {
+		t.Fatal("Load found a missing agent")
+	}
+}
+
+func TestBroadcastSurvivesFailedRoutee(t *testing.T) {
+	ts := mastest.New(t)
+	ts.Spawn(NewRouter("workers", RoundRobin, 3, &echoRoutee{}))
+	ts.Send("client", "workers", GetRoutees{})
+	ts.RunUntilIdle()
+	ts.Sys.Deny("workers", "workers-1", "*")
+
+	ts.Send("client", "workers", BroadcastMsg{Payload: 7})
+	ts.RunUntilIdle()
+
+	// workers-1 недоступний, але решта пулу отримала розсилку
+	ts.ExpectMessage("client", mas.PayloadEquals(Served{By: "workers-2", N: 7}))
+	ts.ExpectMessage("client", mas.PayloadEquals(Served{By: "workers-3", N: 7}))
+	ts.ExpectNoMessage("client", mas.PayloadOf[Served]())
+
+	// REFUSE від workers-1 роутер не пересилає пулу знову
+	ts.ExpectMessage("workers", mas.AllOf(mas.PayloadEquals(7), func(env mas.Envelope) bool { return env.Type == mas.Refuse }))
+	for _, id := range []string{"workers-2", "workers-3"} {
+		ts.ExpectMessage(id, mas.PayloadEquals(7))
+		ts.ExpectNoMessage(id, mas.PayloadEquals(7))
+	}
+}
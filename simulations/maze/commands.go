@@ -0,0 +1,82 @@
+package maze
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/youryharchenko/go-mas/command"
+)
+
+// PolicyNames - назви стратегій волкера (для POLICY і SPAWN), відсортовані.
+func PolicyNames() []string {
+	names := make([]string, 0, len(policies))
+	for name := range policies {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Commands - команди консолі для агентів лабіринту.
+func Commands() *command.Language {
+	return command.NewLanguage(
+		command.Word("NEW", "новий лабіринт (агент лабіринту)"),
+		command.Command{
+			Name: "SPAWN", Args: []string{"count", "policy"},
+			Help:     "породити волкерів зі стратегією " + strings.Join(PolicyNames(), "|"),
+			Build:    buildSpawn,
+			Complete: completePolicy(1),
+		},
+		command.Word("DESPAWN", "зупинити породжених волкерів"),
+		command.Word("RESET", "повернути волкера на старт"),
+		command.Word("TICK", "зробити крок"),
+		command.Command{
+			Name: "POLICY", Args: []string{"policy"},
+			Help:     "змінити стратегію волкера",
+			Build:    func(args []string) (any, error) { return policyPayload(args[0]) },
+			Complete: completePolicy(0),
+		},
+	)
+}
+
+func buildSpawn(args []string) (any, error) {
+	count, err := strconv.Atoi(args[0])
+	if err != nil || count <= 0 {
+		return nil, fmt.Errorf("count must be a positive number, got %q", args[0])
+	}
+	policy, err := checkPolicy(args[1])
+	if err != nil {
+		return nil, err
+	}
+	return fmt.Sprintf("SPAWN:%d:%s", count, policy), nil
+}
+
+func policyPayload(name string) (any, error) {
+	policy, err := checkPolicy(name)
+	if err != nil {
+		return nil, err
+	}
+	return "POLICY:" + policy, nil
+}
+
+// checkPolicy повертає канонічну назву стратегії (регістр не важливий).
+func checkPolicy(name string) (string, error) {
+	for _, policy := range PolicyNames() {
+		if strings.EqualFold(policy, name) {
+			return policy, nil
+		}
+	}
+	return "", fmt.Errorf("unknown policy %q (known: %s)", name, strings.Join(PolicyNames(), ", "))
+}
+
+// completePolicy доповнює назву стратегії в аргументі номер arg.
+func completePolicy(arg int) func(int, string) []string {
+	return func(i int, prefix string) []string {
+		if i != arg {
+			return nil
+		}
+		return PolicyNames()
+	}
+}
@@ -5,6 +5,16 @@ import "math/rand/v2"
 // GenerateMaze створює випадковий лабіринт
 // width, height мають бути непарними (наприклад, 11, 11)
 func GenerateMaze(width, height int) []string {
+	return generateMaze(width, height, rand.Shuffle)
+}
+
+// GenerateMazeSeeded - GenerateMaze з власним генератором:
+// той самий seed дає той самий лабіринт (для відтворюваних експериментів).
+func GenerateMazeSeeded(width, height int, seed uint64) []string {
+	return generateMaze(width, height, rand.New(rand.NewPCG(seed, seed)).Shuffle)
+}
+
+func generateMaze(width, height int, shuffle func(n int, swap func(i, j int))) []string {
 	// 1. Ініціалізуємо сітку стінами
 	grid := make([][]rune, height)
 	for y := 0; y < height; y++ {
@@ -21,7 +31,7 @@ func GenerateMaze(width, height int) []string {
 
 		// Напрямки (вгору, вниз, вліво, вправо) у випадковому порядку
 		dirs := []struct{ dx, dy int }{{0, -2}, {0, 2}, {-2, 0}, {2, 0}}
-		rand.Shuffle(len(dirs), func(i, j int) { dirs[i], dirs[j] = dirs[j], dirs[i] })
+		shuffle(len(dirs), func(i, j int) { dirs[i], dirs[j] = dirs[j], dirs[i] })
 
 		for _, d := range dirs {
 			nx, ny := x+d.dx, y+d.dy
@@ -3,6 +3,10 @@ package maze
 import (
 	"context"
 	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/youryharchenko/go-mas/mas"
 	"github.com/youryharchenko/go-mas/planning"
@@ -40,6 +44,30 @@ type MazeAgent struct {
 	WalkerID string // ID агента, якого ми "спінимо" при ресеті
 	//
 	WalkerPos MazeState
+
+	// Додаткові волкери, яких лабіринт породив сам (SPAWN)
+	Positions map[string]MazeState
+	Spawned   int // Лічильник для унікальних ID
+}
+
+// positionOf повертає позицію волкера (основного або породженого).
+func (m *MazeAgent) positionOf(walkerID string) MazeState {
+	if walkerID == m.WalkerID {
+		return m.WalkerPos
+	}
+	if pos, ok := m.Positions[walkerID]; ok {
+		return pos
+	}
+	return MazeState{X: 1, Y: 1}
+}
+
+// Clone - глибока копія для System.Snapshot.
+func (m *MazeAgent) Clone() mas.Agent {
+	c := *m
+	c.BaseAgent = m.CloneBase()
+	c.Grid = slices.Clone(m.Grid)
+	c.Positions = maps.Clone(m.Positions)
+	return &c
 }
 
 // --- Реалізація інтерфейсу planning.Domain[MazeState] ---
@@ -109,84 +137,175 @@ func (m *MazeAgent) StepCost(from MazeState, action planning.Action, to MazeStat
 
 // --- Реалізація інтерфейсу mas.Agent (Логіка Агента) ---
 
-func (m *MazeAgent) Plan(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
+// Bind реєструє обробники повідомлень (після Startup і Migrate - заново).
+func (m *MazeAgent) Bind(sys *mas.System, inbox <-chan mas.Envelope, me mas.Agent) {
+	m.BaseAgent.Bind(sys, inbox, me)
 
-	// 1. GENERATION (Створення нового рівня)
-	if msg.Payload == "NEW" {
-		// Генеруємо лабіринт
-		m.Grid = GenerateMaze(m.Width, m.Height)
-		// Скидаємо позицію гравця на старт (зазвичай 1,1)
-		m.WalkerPos.X, m.WalkerPos.Y = 1, 1
+	mas.HandleValue(m, "NEW", m.onNew)
+	mas.HandleMatch(m, mas.PayloadHasPrefix("SPAWN:"), func(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
+		return m.planSpawn(strings.TrimPrefix(msg.Payload.(string), "SPAWN:"))
+	})
+	mas.HandleValue(m, "DESPAWN", m.onDespawn)
+	mas.Handle(m, m.onMove)
+}
 
-		return []mas.Action{
-			mas.Send("console", "Maze: New map generated."),
-			// Наказуємо воркеру забути минуле
-			func(a mas.Agent, sys *mas.System) error {
-				return sys.Send(ctx, m.IDVal, m.WalkerID, "RESET")
-			},
-		}, nil
+// 1. GENERATION (Створення нового рівня)
+func (m *MazeAgent) onNew(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
+	// Генеруємо лабіринт
+	m.Grid = GenerateMaze(m.Width, m.Height)
+	// Скидаємо позицію гравця на старт (зазвичай 1,1)
+	m.WalkerPos.X, m.WalkerPos.Y = 1, 1
+
+	for id := range m.Positions {
+		m.Positions[id] = MazeState{X: 1, Y: 1}
 	}
+	m.MarkChanged() // Стан змінено прямо в обробнику, без MutateState
 
-	// 2. MOVEMENT (Обробка реального руху)
-	if req, ok := msg.Payload.(MoveRequest); ok {
-		// Якщо карти немає - нічого не робимо
-		if len(m.Grid) == 0 {
-			return nil, nil
-		}
+	actions := []mas.Action{
+		mas.Send("console", "Maze: New map generated."),
+		// Наказуємо воркеру забути минуле
+		mas.Send(m.WalkerID, "RESET"),
+	}
+	// Породжені волкери теж починають спочатку
+	for _, id := range m.Children() {
+		actions = append(actions, mas.Send(id, "RESET"))
+	}
+	return actions, nil
+}
 
-		newX, newY := m.WalkerPos.X, m.WalkerPos.Y
-		switch req.Dir {
-		case DirUp:
-			newY--
-		case DirDown:
-			newY++
-		case DirLeft:
-			newX--
-		case DirRight:
-			newX++
-		}
+// DESPAWN - зупиняємо всіх породжених волкерів
+func (m *MazeAgent) onDespawn(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
+	var actions []mas.Action
+	for _, id := range m.Children() {
+		actions = append(actions, mas.StopChild(id))
+	}
+	return append(actions,
+		mas.MutateState(func(a any) {
+			a.(*MazeAgent).Positions = nil
+		}),
+		mas.Send("console", fmt.Sprintf("Maze: %d walkers stopped.", len(actions))),
+	), nil
+}
 
-		// Перевірка колізій (чи не врізався у стіну насправді)
-		hitWall := false
-		// Перевірка меж
-		if newY < 0 || newY >= len(m.Grid) || newX < 0 || newX >= len(m.Grid[0]) {
-			hitWall = true
-		} else if m.Grid[newY][newX] == '#' {
-			hitWall = true
-		}
+// 2. MOVEMENT (Обробка реального руху)
+func (m *MazeAgent) onMove(ctx context.Context, msg mas.Envelope, req MoveRequest) ([]mas.Action, error) {
+	pos := m.positionOf(msg.From)
 
-		if hitWall {
-			// Врізався! Повертаємо помилку і СТАРІ координати
-			return []mas.Action{
-				mas.Send(msg.From, MoveResult{
-					Success: false,
-					Message: "BONK!",
-					State:   m.WalkerPos,
-				}),
-			}, nil
-		}
+	// Якщо карти немає - ходити нікуди, але волкер чекає на відповідь
+	if len(m.Grid) == 0 {
+		return []mas.Action{
+			mas.Send(msg.From, MoveResult{
+				Success: false,
+				Message: "No map.",
+				State:   pos,
+			}),
+		}, nil
+	}
 
-		// Успішний рух
-		isWin := m.Grid[newY][newX] == 'E'
+	newX, newY := pos.X, pos.Y
+	switch req.Dir {
+	case DirUp:
+		newY--
+	case DirDown:
+		newY++
+	case DirLeft:
+		newX--
+	case DirRight:
+		newX++
+	}
+
+	// Перевірка колізій (чи не врізався у стіну насправді)
+	hitWall := false
+	// Перевірка меж
+	if newY < 0 || newY >= len(m.Grid) || newX < 0 || newX >= len(m.Grid[0]) {
+		hitWall = true
+	} else if m.Grid[newY][newX] == '#' {
+		hitWall = true
+	}
 
+	if hitWall {
+		// Врізався! Повертаємо помилку і СТАРІ координати
 		return []mas.Action{
-			// Оновлюємо стан середовища
-			mas.MutateState(func(a any) {
-				maze := a.(*MazeAgent)
-				maze.WalkerPos = MazeState{X: newX, Y: newY}
-				//maze.CurrentX = newX
-				//maze.CurrentY = newY
-			}),
-			// Відповідаємо агенту з НОВИМИ координатами
 			mas.Send(msg.From, MoveResult{
-				Success:    true,
-				IsFinished: isWin,
-				State:      MazeState{X: newX, Y: newY},
-				//NewX:       newX,
-				//NewY:       newY,
+				Success: false,
+				Message: "BONK!",
+				State:   pos,
 			}),
 		}, nil
 	}
 
-	return nil, nil
+	// Успішний рух
+	isWin := m.Grid[newY][newX] == 'E'
+
+	return []mas.Action{
+		// Оновлюємо стан середовища
+		mas.MutateState(func(a any) {
+			maze := a.(*MazeAgent)
+			if msg.From != maze.WalkerID {
+				if maze.Positions == nil {
+					maze.Positions = make(map[string]MazeState)
+				}
+				maze.Positions[msg.From] = MazeState{X: newX, Y: newY}
+				return
+			}
+			maze.WalkerPos = MazeState{X: newX, Y: newY}
+			//maze.CurrentX = newX
+			//maze.CurrentY = newY
+		}),
+		// Відповідаємо агенту з НОВИМИ координатами
+		mas.Send(msg.From, MoveResult{
+			Success:    true,
+			IsFinished: isWin,
+			State:      MazeState{X: newX, Y: newY},
+			//NewX:       newX,
+			//NewY:       newY,
+		}),
+	}, nil
+}
+
+// planSpawn розбирає "<n>:<policy>" і породжує n волкерів з потрібною стратегією.
+func (m *MazeAgent) planSpawn(args string) ([]mas.Action, error) {
+	countStr, policy, _ := strings.Cut(args, ":")
+	count, err := strconv.Atoi(countStr)
+	if err != nil || count <= 0 {
+		return []mas.Action{
+			mas.Send("console", "Maze: use SPAWN:<count>:<policy>"),
+		}, nil
+	}
+	if policy == "" {
+		policy = "DFS"
+	}
+
+	var actions []mas.Action
+	ids := make([]string, 0, count)
+	for i := 1; i <= count; i++ {
+		id := fmt.Sprintf("%s-walker-%d", m.IDVal, m.Spawned+i)
+		ids = append(ids, id)
+		actions = append(actions,
+			mas.SpawnChild(&PlannerWalker{
+				BaseAgent:    mas.BaseAgent{IDVal: id},
+				CurrentState: MazeState{X: 1, Y: 1},
+				MazeID:       m.IDVal,
+			}),
+			mas.Send(id, "POLICY:"+policy),
+		)
+	}
+
+	// Реєструємо позиції до того, як волкери почнуть ходити
+	actions = append([]mas.Action{
+		mas.MutateState(func(a any) {
+			maze := a.(*MazeAgent)
+			if maze.Positions == nil {
+				maze.Positions = make(map[string]MazeState)
+			}
+			for _, id := range ids {
+				maze.Positions[id] = MazeState{X: 1, Y: 1}
+			}
+			maze.Spawned += count
+		}),
+	}, actions...)
+
+	return append(actions,
+		mas.Send("console", fmt.Sprintf("Maze: spawned %d walkers with %s.", count, policy)),
+	), nil
 }
@@ -1,6 +1,11 @@
 package maze
 
 import (
+	"bytes"
+	"cmp"
+	"encoding/gob"
+	"encoding/json"
+	"slices"
 	"sync"
 )
 
@@ -44,3 +49,50 @@ func (m *MazeMemory) Clear() {
 func (m *MazeMemory) Range(f func(key, value any) bool) {
 	m.visited.Range(f)
 }
+
+// Clone повертає незалежну копію пам'яті (для знімків стану).
+func (m *MazeMemory) Clone() *MazeMemory {
+	c := NewMazeMemory()
+	m.visited.Range(func(key, value any) bool {
+		c.visited.Store(key, value)
+		return true
+	})
+	return c
+}
+
+// States повертає запам'ятовані стани, впорядковані (Y, потім X).
+func (m *MazeMemory) States() []MazeState {
+	var states []MazeState
+	m.visited.Range(func(key, _ any) bool {
+		states = append(states, key.(MazeState))
+		return true
+	})
+	slices.SortFunc(states, func(a, b MazeState) int {
+		return cmp.Or(cmp.Compare(a.Y, b.Y), cmp.Compare(a.X, b.X))
+	})
+	return states
+}
+
+// GobEncode зберігає пам'ять як список станів: sync.Map GOB не серіалізує
+// (потрібно для Export/Import і запису повідомлень replay).
+func (m *MazeMemory) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(m.States())
+	return buf.Bytes(), err
+}
+
+func (m *MazeMemory) GobDecode(data []byte) error {
+	var states []MazeState
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&states); err != nil {
+		return err
+	}
+	for _, s := range states {
+		m.Remember(s)
+	}
+	return nil
+}
+
+// MarshalJSON - той самий впорядкований список (знімки /agents, хеші стану replay).
+func (m *MazeMemory) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.States())
+}
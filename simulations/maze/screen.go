@@ -2,8 +2,8 @@ package maze
 
 import (
 	"context"
-	"encoding/gob"
 	"fmt"
+	"log"
 	"time"
 
 	"fyne.io/fyne/v2"
@@ -26,95 +26,93 @@ func NewScreen(parentSys *mas.System, logData binding.String) fyne.CanvasObject
 	board := NewMazeBoard()
 
 	wolkerID := "walker-1"
-	if _, ok := mazeSys.GetAgent("maze-1"); !ok {
-		initialMap := GenerateMaze(15, 15)
-		mazeSys.Spawn(&MazeAgent{
-			BaseAgent: mas.BaseAgent{IDVal: "maze-1"},
-			Grid:      initialMap,
-			Width:     15,
-			Height:    15,
-			WalkerPos: MazeState{X: 1, Y: 1},
-			//CurrentX:  1, // Координати S
-			//CurrentY:  1,
-			WalkerID: wolkerID,
-		})
+	world := World{MazeID: "maze-1", WalkerID: wolkerID, Width: 15, Height: 15}
+	if err := world.Spawn(mazeSys); err != nil {
+		log.Println(err)
 	}
 
-	// 2. Створюємо Шукача
-	if _, ok := mazeSys.GetAgent(wolkerID); !ok {
-		mazeSys.Spawn(&PlannerWalker{
-			BaseAgent:    mas.BaseAgent{IDVal: wolkerID},
-			CurrentState: MazeState{X: 1, Y: 1},
-			//CurrentX:  1,
-			//CurrentY:  1,
-			MazeID: "maze-1",
-		})
-	}
+	// Тікер не повинен засипати волкера, який застряг: зайві TICK просто викидаємо
+	// (скільки їх - видно в mazeSys.Metrics())
+	mazeSys.SetSenderRateLimit("admin", wolkerID, mas.Limit{Rate: 2, Burst: 4, Policy: mas.LimitDrop})
 
 	// --- UI LOOP (Оновлення графіки) ---
-	// Запускаємо горутину, яка синхронізує стан агентів з UI
-	// Це найпростіший спосіб без зміни коду агентів
-	go func() {
-		ticker := time.NewTicker(100 * time.Millisecond) // 10 FPS
-		for range ticker.C {
-			// Отримуємо доступ до агентів (через GetAgent, який ми додали вчора)
-			// (Тут треба привести типи до конкретних структур)
-
-			// ПРИКЛАД:
-			aMaze, ok1 := mazeSys.GetAgent("maze-1")
-			aWalker, ok2 := mazeSys.GetAgent("walker-1")
-
-			if ok1 && ok2 {
-				realMaze := aMaze.(*MazeAgent)
-				realWalker := aWalker.(*PlannerWalker)
-
-				// Оновлюємо віджет
-				// Важливо робити це в потоці UI, але Refresh() thread-safe
-				//board.UpdateState(realMaze.Grid, realWalker.CurrentX, realWalker.CurrentY)
-				//log.Println("realWalker:", realWalker.CurrentX, realWalker.CurrentY)
-				//log.Println("realMaze:", realMaze.WalkerPosX, realMaze.WalkerPosY)
-
-				// Підготовка даних пам'яті для UI
-				uiVisited := make(map[string]bool)
-
-				// Читаємо з sync.Map через наш helper Range
-				if realWalker.Memory != nil {
-					realWalker.Memory.Range(func(key, value any) bool {
-						// Ключ у мапі тепер MazeState {X,Y}
-						if s, ok := key.(MazeState); ok {
-							k := fmt.Sprintf("%d,%d", s.X, s.Y)
-							uiVisited[k] = true
-						}
-						return true
-					})
+	// Перемальовуємо тільки тоді, коли лабіринт або волкер змінили стан (System.Watch),
+	// замість того щоб опитувати агентів 10 разів на секунду
+	redraw := func() {
+		// Беремо знімки стану: агенти продовжують працювати у своїх горутинах,
+		// а ми читаємо власні копії без гонок даних
+		aMaze, err1 := mazeSys.Snapshot("maze-1")
+		aWalker, err2 := mazeSys.Snapshot("walker-1")
+		if err1 != nil || err2 != nil {
+			return
+		}
+
+		realMaze := aMaze.(*MazeAgent)
+		realWalker := aWalker.(*PlannerWalker)
+
+		// Підготовка даних пам'яті для UI
+		uiVisited := make(map[string]bool)
+
+		// Читаємо з sync.Map через наш helper Range
+		if realWalker.Memory != nil {
+			realWalker.Memory.Range(func(key, value any) bool {
+				// Ключ у мапі тепер MazeState {X,Y}
+				if s, ok := key.(MazeState); ok {
+					k := fmt.Sprintf("%d,%d", s.X, s.Y)
+					uiVisited[k] = true
 				}
+				return true
+			})
+		}
 
-				board.UpdateState(realMaze.Grid, realWalker.CurrentState.X, realWalker.CurrentState.Y, uiVisited)
-			}
+		board.UpdateState(realMaze.Grid, realWalker.CurrentState.X, realWalker.CurrentState.Y, uiVisited)
+	}
+
+	mazeChanges, stopMazeWatch := mazeSys.Watch("maze-1")
+	walkerChanges, stopWalkerWatch := mazeSys.Watch(wolkerID)
+	go func() {
+		defer stopMazeWatch()
+		defer stopWalkerWatch()
 
+		redraw() // Перший кадр
+		for {
+			select {
+			case <-mazeChanges:
+			case <-walkerChanges:
+			case <-mazeSys.Context().Done(): // Підсистема зупиняється
+				return
+			}
+			redraw()
 		}
 	}()
 
 	// Глобальний таймер світу
 	go func() {
 		ticker := time.NewTicker(500 * time.Millisecond) // 2 ходи на секунду
+		defer ticker.Stop()
 		for {
 			select {
+			case <-mazeSys.Context().Done():
+				return
 			case <-ticker.C:
-				// Пінаємо волкера, щоб він думав
-				mazeSys.Send(context.Background(), "admin", wolkerID, "TICK")
+				// Пінаємо волкера, щоб він думав (пропускаємо тік, якщо він не встигає)
+				mazeSys.TrySend(mazeSys.Context(), "admin", wolkerID, "TICK")
+				// І всіх волкерів, яких породив сам лабіринт (SPAWN)
+				for _, id := range mazeSys.Children("maze-1") {
+					mazeSys.TrySend(mazeSys.Context(), "admin", id, "TICK")
+				}
 			}
 		}
 	}()
 
 	// Кнопка "Новий лабіринт"
 	btnNew := widget.NewButton("New Maze", func() {
-		mazeSys.Send(context.Background(), "gui", "maze-1", "NEW")
+		mazeSys.SendWithTimeout(context.Background(), "gui", "maze-1", "NEW", time.Second)
 	})
 
 	// Кнопка "Скинути пам'ять"
 	btnResetWalker := widget.NewButton("Reset Memory", func() {
-		mazeSys.Send(context.Background(), "gui", "walker-1", "RESET")
+		mazeSys.SendWithTimeout(context.Background(), "gui", "walker-1", "RESET", time.Second)
 	})
 
 	// --- НОВЕ: Вибір Стратегії ---
@@ -122,7 +120,7 @@ func NewScreen(parentSys *mas.System, logData binding.String) fyne.CanvasObject
 		// Відправляємо команду агенту змінити мозок
 		// Формат payload: "POLICY:DFS"
 		cmd := "POLICY:" + selected
-		mazeSys.Send(context.Background(), "gui", "walker-1", cmd)
+		mazeSys.SendWithTimeout(context.Background(), "gui", "walker-1", cmd, time.Second)
 	})
 	policySelect.SetSelected("DFS") // Значення за замовчуванням
 
@@ -144,10 +142,5 @@ func NewScreen(parentSys *mas.System, logData binding.String) fyne.CanvasObject
 		board,   // Center
 	)
 
-	gob.Register(&MoveRequest{})
-	gob.Register(&MoveResult{})
-	gob.Register(&MazeAgent{})
-	gob.Register(&PlannerWalker{})
-
 	return content
 }
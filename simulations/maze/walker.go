@@ -12,6 +12,26 @@ import (
 	"github.com/youryharchenko/go-mas/planning"
 )
 
+// MoveTimeout - скільки волкер чекає на MoveResult, перш ніж знову приймати TICK.
+const MoveTimeout = 2 * time.Second
+
+// moveExpired - волкер надсилає собі через MoveTimeout після MoveRequest номер Move.
+type moveExpired struct {
+	Move int
+}
+
+// waitingForMove - що волкер приймає, поки чекає на відповідь лабіринту.
+// Все інше відкладається і повертається після MoveResult або moveExpired.
+// TICK не відкладається: onTick сам відкидає його, поки хід не завершено,
+// інакше за повільного лабіринту stash заповнювався б тіками.
+var waitingForMove = []mas.Matcher{
+	mas.PayloadEquals("TICK"),
+	mas.PayloadOf[MoveResult](),
+	mas.PayloadOf[moveExpired](),
+	mas.PayloadEquals("RESET"),
+	mas.PayloadHasPrefix("POLICY:"),
+}
+
 type PlannerWalker struct {
 	mas.BaseAgent
 	MazeID string
@@ -26,6 +46,10 @@ type PlannerWalker struct {
 	CurrentState MazeState
 	Steps        int
 	Solved       bool
+
+	brainName string // Яка стратегія зараз у Brain (не зберігається)
+	moves     int    // Номер останнього MoveRequest (для moveExpired)
+	awaiting  bool   // MoveRequest надіслано, відповіді ще немає
 }
 
 func (w *PlannerWalker) Bind(sys *mas.System, inbox <-chan mas.Envelope, me mas.Agent) {
@@ -37,15 +61,71 @@ func (w *PlannerWalker) Bind(sys *mas.System, inbox <-chan mas.Envelope, me mas.
 		w.Memory = NewMazeMemory()
 	}
 	if w.Brain == nil {
-		w.Brain = ai.NewDFS[MazeState]()
+		w.useBrain("DFS")
 	}
 
+	// Обробники повідомлень (реєструються заново після кожного Bind)
+	mas.HandleValue(w, "TICK", w.onTick)
+	mas.Handle(w, w.onMoveResult)
+	mas.Handle(w, w.onMoveExpired)
+	mas.HandleValue(w, "RESET", w.onReset)
+	mas.HandleMatch(w, mas.PayloadHasPrefix("POLICY:"), w.onPolicy)
+
 	// ВАЖЛИВО: Нам треба отримати доступ до Domain (MazeAgent).
 	// Оскільки ми в одному процесі, ми можемо знайти його через System.
 	// Це трохи порушує чисту акторну модель, але необхідно для Planner-абстракції.
 	// Ми зробимо це ліниво (Lazy) у методі Plan, або тут, якщо Maze вже існує.
 }
 
+// policies - стратегії, між якими волкер перемикається командою POLICY:<name>.
+var policies = map[string]func() planning.Policy[MazeState]{
+	"DFS":    func() planning.Policy[MazeState] { return ai.NewDFS[MazeState]() },
+	"BFS":    func() planning.Policy[MazeState] { return ai.NewBFS[MazeState]() },
+	"AStar":  func() planning.Policy[MazeState] { return ai.NewAStar[MazeState]() },
+	"Random": func() planning.Policy[MazeState] { return ai.NewRandom[MazeState]() },
+}
+
+// walkerBehaviour - ім'я поведінки (mas.Become) для стратегії.
+func walkerBehaviour(policy string) string {
+	return "walker:" + policy
+}
+
+// useBrain ставить мозок потрібної стратегії, якщо зараз стоїть інший.
+func (w *PlannerWalker) useBrain(policy string) {
+	if w.brainName == policy && w.Brain != nil {
+		return
+	}
+	if newBrain, ok := policies[policy]; ok {
+		w.Brain = newBrain()
+		w.brainName = policy
+	}
+}
+
+func init() {
+	// Кожна стратегія - окрема поведінка: вона гарантує відповідний мозок
+	// (після Startup він не відновлюється з GOB) і далі працює як звичайний Plan.
+	for policy := range policies {
+		mas.RegisterReceive(walkerBehaviour(policy), func(ctx context.Context, self mas.Agent, msg mas.Envelope) ([]mas.Action, error) {
+			w := self.(*PlannerWalker)
+			w.useBrain(policy)
+			return w.Plan(ctx, msg)
+		})
+	}
+}
+
+// Clone - глибока копія для System.Snapshot.
+// Мозок і домен не копіюються: вони потрібні лише для прийняття рішень.
+func (w *PlannerWalker) Clone() mas.Agent {
+	c := *w
+	c.BaseAgent = w.CloneBase()
+	c.Brain = nil
+	c.Domain = nil
+	if mem, ok := w.Memory.(*MazeMemory); ok {
+		c.Memory = mem.Clone()
+	}
+	return &c
+}
+
 func (w *PlannerWalker) Plan(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
 
 	// Ініціалізація Домену (Лінива)
@@ -60,154 +140,182 @@ func (w *PlannerWalker) Plan(ctx context.Context, msg mas.Envelope) ([]mas.Actio
 		}
 	}
 
-	// 1. ОБРОБКА TICK (Прийняття рішень)
-	if msg.Payload == "TICK" {
-		if w.Solved || w.Domain == nil {
-			return nil, nil
-		}
-
-		// --- OODA LOOP: Decide ---
-		// Агент запитує у Політики: "Що робити?"
-		log.Println("CurrentState:", w.CurrentState)
-		actionName, err := w.Brain.Decide(ctx, w.CurrentState, w.Domain, w.Memory)
+	return w.Dispatch(ctx, msg)
+}
 
-		if err == ai.ErrGoalReached {
-			// Якщо Політика каже, що ми прийшли - фіксуємо перемогу
-			// (Хоча технічно це має статися після Action, але DFS знає це заздалегідь)
-			return nil, nil // Чекаємо, поки MoveResult підтвердить це
-		}
+// 1. ОБРОБКА TICK (Прийняття рішень)
+func (w *PlannerWalker) onTick(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
+	if w.Solved || w.Domain == nil {
+		return nil, nil
+	}
+	// Попередній хід ще не завершено: цей тік зайвий
+	if w.awaiting {
+		return nil, nil
+	}
 
-		if err != nil {
-			// Глухий кут або помилка
-			// mas.SayLog("Brain error: %v", err)
-			return nil, nil
-		}
+	// --- OODA LOOP: Decide ---
+	// Агент запитує у Політики: "Що робити?"
+	log.Println("CurrentState:", w.CurrentState)
+	actionName, err := w.Brain.Decide(ctx, w.CurrentState, w.Domain, w.Memory)
 
-		// --- Act ---
-		// Конвертуємо planning.Action (string) у MoveRequest (struct)
-		var dir Direction
-		switch actionName {
-		case "UP":
-			dir = DirUp
-		case "DOWN":
-			dir = DirDown
-		case "LEFT":
-			dir = DirLeft
-		case "RIGHT":
-			dir = DirRight
-		}
+	if err == ai.ErrGoalReached {
+		// Якщо Політика каже, що ми прийшли - фіксуємо перемогу
+		// (Хоча технічно це має статися після Action, але DFS знає це заздалегідь)
+		return nil, nil // Чекаємо, поки MoveResult підтвердить це
+	}
 
-		return []mas.Action{
-			func(a mas.Agent, sys *mas.System) error {
-				req := MoveRequest{Dir: dir}
-				log.Println("MoveRequest:", req)
-				return sys.Send(ctx, w.IDVal, w.MazeID, req)
-			},
-		}, nil
+	if err != nil {
+		// Глухий кут або помилка
+		// mas.SayLog("Brain error: %v", err)
+		return nil, nil
 	}
 
-	// 2. ОБРОБКА РЕЗУЛЬТАТУ (Сприйняття)
-	if res, ok := msg.Payload.(MoveResult); ok {
-		log.Println("MoveResult:", res)
-		if res.Success {
-			// Оновлюємо стан тільки якщо хід успішний
-			return []mas.Action{
-				mas.MutateState(func(a any) {
-					walker := a.(*PlannerWalker)
-					walker.Steps++
-
-					// ОНОВЛЮЄМО КООРДИНАТИ З ПОВІДОМЛЕННЯ
-					walker.CurrentState.X = res.State.X
-					walker.CurrentState.Y = res.State.Y
-
-					// Запам'ятовуємо новий стан
-					walker.Memory.Remember(walker.CurrentState)
-
-				}),
-				func(a mas.Agent, sys *mas.System) error {
-					walker := a.(*PlannerWalker)
-					if res.IsFinished {
-						walker.Solved = true
-						sys.Send(ctx, w.IDVal, "console", fmt.Sprintf("VICTORY in %d steps!", walker.Steps))
-
-						// Авто-рестарт
-						go func() {
-							time.Sleep(3 * time.Second)
-							sys.Send(context.Background(), w.IDVal, w.MazeID, "NEW")
-						}()
-					} else {
-						// Оновлюємо пам'ять про новий стан
-						// (Це важливо! Ми додаємо у пам'ять тільки ФАКТИЧНО відвідані стани)
-
-						// !!! ТУТ ПРОБЛЕМА: Ми не знаємо нових координат без MoveResult !!!
-						// Треба оновити MoveResult у models.go
-					}
-					return nil
-				},
-			}, nil
-		}
+	// --- Act ---
+	// Конвертуємо planning.Action (string) у MoveRequest (struct)
+	var dir Direction
+	switch actionName {
+	case "UP":
+		dir = DirUp
+	case "DOWN":
+		dir = DirDown
+	case "LEFT":
+		dir = DirLeft
+	case "RIGHT":
+		dir = DirRight
 	}
 
-	// 3. ОБРОБКА RESET
-	if msg.Payload == "RESET" {
-		return []mas.Action{
-			mas.MutateState(func(a any) {
-				w := a.(*PlannerWalker)
-				w.Memory.Clear()
-				w.Steps = 0
-				w.Solved = false
-				w.CurrentState = MazeState{X: 1, Y: 1} // Start
-
-				// Скидаємо мозок
-				/* if dfs, ok := w.Brain.(*ai.DFSPolicy[MazeState]); ok {
-					dfs.Reset()
-				} */
-				w.Brain.Reset()
-				// Стартова точка в пам'ять
-				w.Memory.Remember(w.CurrentState)
-			}),
-		}, nil
+	return []mas.Action{
+		func(ctx context.Context, a mas.Agent, sys *mas.System) error {
+			walker := a.(*PlannerWalker)
+			req := MoveRequest{Dir: dir}
+			log.Println("MoveRequest:", req)
+			if err := sys.Send(ctx, walker.IDVal, walker.MazeID, req); err != nil {
+				// Запит не пішов - відповіді не буде, наступний TICK спробує знову
+				return err
+			}
+
+			// Поки чекаємо на MoveResult, нові TICK відкидаємо:
+			// інакше агент планував би ще раз на застарілому стані.
+			// Якщо лабіринт так і не відповість, фільтр зніме moveExpired.
+			walker.moves++
+			walker.awaiting = true
+			walker.AcceptOnly(waitingForMove...)
+			return mas.SendAfter(MoveTimeout, walker.IDVal, moveExpired{Move: walker.moves})(ctx, a, sys)
+		},
+	}, nil
+}
+
+// onMoveExpired - лабіринт не відповів на хід за MoveTimeout: знову приймаємо TICK.
+// Тайм-аут ходу, на який відповідь уже прийшла, нічого не змінює.
+func (w *PlannerWalker) onMoveExpired(ctx context.Context, msg mas.Envelope, exp moveExpired) ([]mas.Action, error) {
+	if exp.Move != w.moves {
+		return nil, nil
 	}
+	log.Printf("Walker %s: no answer from %s for move %d", w.IDVal, w.MazeID, exp.Move)
+	return []mas.Action{moveDone()}, nil
+}
 
-	// --- НОВЕ: ЗМІНА ПОЛІТИКИ ---
-	// Перевіряємо, чи це рядок і чи починається він з "POLICY:"
-	if str, ok := msg.Payload.(string); ok && strings.HasPrefix(str, "POLICY:") {
-		policyName := strings.TrimPrefix(str, "POLICY:")
+// moveDone - дія: хід завершено (або відповіді вже не буде), знову приймаємо все.
+func moveDone() mas.Action {
+	return func(ctx context.Context, a mas.Agent, sys *mas.System) error {
+		a.(*PlannerWalker).awaiting = false
+		return mas.AcceptAll()(ctx, a, sys)
+	}
+}
 
+// 2. ОБРОБКА РЕЗУЛЬТАТУ (Сприйняття)
+func (w *PlannerWalker) onMoveResult(ctx context.Context, msg mas.Envelope, res MoveResult) ([]mas.Action, error) {
+	log.Println("MoveResult:", res)
+	if res.Success {
+		// Оновлюємо стан тільки якщо хід успішний
 		return []mas.Action{
+			moveDone(), // Хід завершено - повертаємо відкладені повідомлення
 			mas.MutateState(func(a any) {
 				walker := a.(*PlannerWalker)
+				walker.Steps++
 
-				// 1. Міняємо мозок
-				switch policyName {
-				case "DFS":
-					walker.Brain = ai.NewDFS[MazeState]()
-				case "BFS":
-					walker.Brain = ai.NewBFS[MazeState]() // Коли напишете BFS
-				case "AStar": // <--- НОВЕ
-					walker.Brain = ai.NewAStar[MazeState]()
-				case "Random":
-					// Можна швидко зробити заглушку або реальний RandomPolicy
-					// walker.Brain = policies.NewRandom[MazeState]()
-					// Поки скинемо на DFS, якщо іншого нема
-					walker.Brain = ai.NewRandom[MazeState]()
-				default:
-					// Якщо не знаємо, залишаємо як є або DFS
-				}
+				// ОНОВЛЮЄМО КООРДИНАТИ З ПОВІДОМЛЕННЯ
+				walker.CurrentState.X = res.State.X
+				walker.CurrentState.Y = res.State.Y
 
-				// 2. Скидаємо стан (Reset)
-				// Дублюємо логіку RESET, щоб агент почав нове життя з новим мозком
-				walker.Memory.Clear()
-				walker.Steps = 0
-				walker.Solved = false
-				walker.CurrentState = MazeState{X: 1, Y: 1}
+				// Запам'ятовуємо новий стан
 				walker.Memory.Remember(walker.CurrentState)
+
 			}),
-			mas.Send("console", fmt.Sprintf("Planner: Switched brain to %s", policyName)),
+			func(ctx context.Context, a mas.Agent, sys *mas.System) error {
+				walker := a.(*PlannerWalker)
+				if res.IsFinished {
+					walker.Solved = true
+					sys.Send(ctx, w.IDVal, "console", fmt.Sprintf("VICTORY in %d steps!", walker.Steps))
+
+					// Авто-рестарт (за годинником системи, щоб працювало і з фейковим часом)
+					return mas.SendAfter(3*time.Second, w.MazeID, "NEW")(ctx, a, sys)
+				} else {
+					// Оновлюємо пам'ять про новий стан
+					// (Це важливо! Ми додаємо у пам'ять тільки ФАКТИЧНО відвідані стани)
+
+					// !!! ТУТ ПРОБЛЕМА: Ми не знаємо нових координат без MoveResult !!!
+					// Треба оновити MoveResult у models.go
+				}
+				return nil
+			},
 		}, nil
 	}
 
-	return nil, nil
+	// BONK! Хід не вдався, але відповідь отримано
+	return []mas.Action{moveDone()}, nil
+}
+
+// 3. ОБРОБКА RESET
+func (w *PlannerWalker) onReset(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
+	return []mas.Action{
+		moveDone(),
+		mas.MutateState(func(a any) {
+			w := a.(*PlannerWalker)
+			w.Memory.Clear()
+			w.Steps = 0
+			w.Solved = false
+			w.CurrentState = MazeState{X: 1, Y: 1} // Start
+
+			// Скидаємо мозок
+			/* if dfs, ok := w.Brain.(*ai.DFSPolicy[MazeState]); ok {
+				dfs.Reset()
+			} */
+			w.Brain.Reset()
+			// Стартова точка в пам'ять
+			w.Memory.Remember(w.CurrentState)
+		}),
+	}, nil
+}
+
+// --- ЗМІНА ПОЛІТИКИ (Become) ---
+func (w *PlannerWalker) onPolicy(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
+	policyName := strings.TrimPrefix(msg.Payload.(string), "POLICY:")
+	if _, known := policies[policyName]; !known {
+		return []mas.Action{
+			mas.Send("console", fmt.Sprintf("Planner: Unknown policy %s", policyName)),
+		}, nil
+	}
+
+	return []mas.Action{
+		moveDone(),
+		// 1. Міняємо мозок: наступні повідомлення обробляє поведінка "walker:<policy>".
+		// Ім'я поведінки зберігається разом з агентом, тож після Startup мозок той самий.
+		mas.Become(walkerBehaviour(policyName)),
+		mas.MutateState(func(a any) {
+			walker := a.(*PlannerWalker)
+			walker.useBrain(policyName)
+
+			// 2. Скидаємо стан (Reset)
+			// Дублюємо логіку RESET, щоб агент почав нове життя з новим мозком
+			walker.Memory.Clear()
+			walker.Steps = 0
+			walker.Solved = false
+			walker.CurrentState = MazeState{X: 1, Y: 1}
+			walker.Memory.Remember(walker.CurrentState)
+		}),
+		mas.Send("console", fmt.Sprintf("Planner: Switched brain to %s", policyName)),
+	}, nil
 }
 
 // Sys повертає посилання на систему (helper)
@@ -0,0 +1,145 @@
+package maze
+
+import (
+	"testing"
+
+	"github.com/youryharchenko/go-mas/mas"
+	"github.com/youryharchenko/go-mas/mas/mastest"
+	"github.com/youryharchenko/go-mas/planning"
+)
+
+// corridor - нескінченний коридор праворуч: волкер завжди має куди ходити.
+type corridor struct{}
+
+func (corridor) Actions(s MazeState) []planning.Action { return []planning.Action{"RIGHT"} }
+func (corridor) Result(s MazeState, a planning.Action) MazeState {
+	return MazeState{X: s.X + 1, Y: s.Y}
+}
+func (corridor) IsGoal(s MazeState) bool { return false }
+func (corridor) StepCost(s1 MazeState, a planning.Action, s2 MazeState) float64 {
+	return 1
+}
+func (corridor) Heuristic(s MazeState) float64 { return 0 }
+
+func newTestWalker(ts *mastest.TestSystem) *PlannerWalker {
+	w := &PlannerWalker{
+		BaseAgent:    mas.BaseAgent{IDVal: "walker-1"},
+		MazeID:       "maze-1",
+		CurrentState: Start,
+		Domain:       corridor{},
+	}
+	ts.Spawn(w)
+	return w
+}
+
+func TestWalkerMoveTimeoutLiftsFilter(t *testing.T) {
+	ts := mastest.New(t)
+	w := newTestWalker(ts) // maze-1 немає: на хід ніхто не відповість
+
+	ts.Send("main", "walker-1", "TICK")
+	ts.RunUntilIdle()
+	ts.ExpectMessage("maze-1", mas.PayloadOf[MoveRequest]())
+
+	// Поки чекаємо на відповідь, TICK відкидається і не накопичується
+	for range 3 {
+		ts.Send("main", "walker-1", "TICK")
+	}
+	ts.RunUntilIdle()
+	ts.ExpectNoMessage("maze-1", mas.PayloadOf[MoveRequest]())
+	if w.Stashed() != 0 {
+		t.Fatalf("stashed = %d, want 0", w.Stashed())
+	}
+
+	// Тайм-аут ходу знімає фільтр, і наступний TICK робить новий хід
+	ts.Advance(MoveTimeout)
+	ts.ExpectNoMessage("maze-1", mas.PayloadOf[MoveRequest]())
+	ts.Send("main", "walker-1", "TICK")
+	ts.RunUntilIdle()
+	ts.ExpectMessage("maze-1", mas.PayloadOf[MoveRequest]())
+}
+
+func TestWalkerKeepsOtherMessagesWhileWaiting(t *testing.T) {
+	ts := mastest.New(t)
+	w := newTestWalker(ts)
+
+	ts.Send("main", "walker-1", "TICK")
+	ts.Send("main", "walker-1", "TICK")
+	ts.Send("main", "walker-1", "HELLO")
+	ts.RunUntilIdle()
+	if w.Stashed() != 1 {
+		t.Fatalf("stashed = %d, want 1 (only HELLO)", w.Stashed())
+	}
+
+	// RESET завершує очікування: відкладене повертається, TICK знову ходить
+	ts.Send("main", "walker-1", "RESET")
+	ts.RunUntilIdle()
+	if w.Stashed() != 0 {
+		t.Fatalf("stashed = %d after RESET, want 0", w.Stashed())
+	}
+	ts.Send("main", "walker-1", "TICK")
+	ts.RunUntilIdle()
+	ts.ExpectMessage("maze-1", mas.PayloadOf[MoveRequest]())
+	ts.ExpectMessage("maze-1", mas.PayloadOf[MoveRequest]())
+}
+
+func TestWalkerFailedSendDoesNotWait(t *testing.T) {
+	ts := mastest.New(t)
+	w := newTestWalker(ts)
+	ts.Sys.Deny("walker-1", "maze-1", "MoveRequest")
+
+	ts.Send("main", "walker-1", "TICK")
+	ts.RunUntilIdle()
+	ts.Send("main", "walker-1", "TICK")
+	ts.RunUntilIdle()
+
+	if w.Stashed() != 0 {
+		t.Fatalf("stashed = %d, want 0: walker waits for a move that was never sent", w.Stashed())
+	}
+	if ts.Clock.Pending() != 0 {
+		t.Fatalf("move timeout armed for a failed send")
+	}
+}
+
+func TestMazeAnswersWithoutGrid(t *testing.T) {
+	ts := mastest.New(t)
+	w := newTestWalker(ts)
+	ts.Spawn(&MazeAgent{BaseAgent: mas.BaseAgent{IDVal: "maze-1"}, WalkerID: "walker-1", WalkerPos: Start})
+
+	ts.Send("main", "walker-1", "TICK")
+	ts.RunUntilIdle()
+	res := ts.ExpectMessage("walker-1", mas.PayloadOf[MoveResult]())
+	if r, _ := mas.PayloadAs[MoveResult](res); r.Success {
+		t.Fatalf("move on an empty maze succeeded: %+v", r)
+	}
+
+	// Відповідь отримано - наступний TICK знову ходить
+	ts.Send("main", "walker-1", "TICK")
+	ts.RunUntilIdle()
+	if w.Stashed() != 0 {
+		t.Fatalf("stashed = %d, want 0", w.Stashed())
+	}
+	ts.ExpectMessage("maze-1", mas.PayloadOf[MoveRequest]())
+	ts.ExpectMessage("maze-1", mas.PayloadOf[MoveRequest]())
+}
+
+func TestCloneDoesNotShareBecomeStack(t *testing.T) {
+	for _, a := range []interface {
+		mas.Agent
+		BecomeStacked(string)
+		Become(string)
+		Behaviour() string
+	}{
+		&MazeAgent{BaseAgent: mas.BaseAgent{IDVal: "maze-1"}},
+		&PlannerWalker{BaseAgent: mas.BaseAgent{IDVal: "walker-1"}},
+	} {
+		a.BecomeStacked("idle")
+		a.BecomeStacked("busy")
+		c := a.(interface{ Clone() mas.Agent }).Clone().(interface{ Behaviour() string })
+
+		// Become переписує верхівку стека на місці
+		a.Become("broken")
+		if got := c.Behaviour(); got != "busy" {
+			t.Fatalf("%s: clone behaviour %q after Become on the original, want busy", a.ID(), got)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package maze
+
+import (
+	"encoding/gob"
+	"fmt"
+
+	"github.com/youryharchenko/go-mas/mas"
+)
+
+// World - один світ лабіринту: агент-лабіринт і його основний волкер.
+// Той самий світ будують і вкладка лабораторії (NewScreen), і mazerun без UI.
+type World struct {
+	MazeID   string
+	WalkerID string
+	Width    int // Непарні розміри (див. GenerateMaze)
+	Height   int
+	Grid     []string // Готова карта; nil - згенерувати випадкову
+}
+
+// Start - клітинка старту волкера.
+var Start = MazeState{X: 1, Y: 1}
+
+// Spawn породжує в sys лабіринт і волкера (тих, кого там ще немає, наприклад після Startup).
+func (w World) Spawn(sys *mas.System) error {
+	if _, ok := sys.GetAgent(w.MazeID); !ok {
+		grid := w.Grid
+		if grid == nil {
+			grid = GenerateMaze(w.Width, w.Height)
+		}
+		err := sys.Spawn(&MazeAgent{
+			BaseAgent: mas.BaseAgent{IDVal: w.MazeID},
+			Grid:      grid,
+			Width:     w.Width,
+			Height:    w.Height,
+			WalkerPos: Start,
+			WalkerID:  w.WalkerID,
+		})
+		if err != nil {
+			return fmt.Errorf("maze world: %w", err)
+		}
+	}
+
+	if _, ok := sys.GetAgent(w.WalkerID); !ok {
+		err := sys.Spawn(&PlannerWalker{
+			BaseAgent:    mas.BaseAgent{IDVal: w.WalkerID},
+			CurrentState: Start,
+			MazeID:       w.MazeID,
+		})
+		if err != nil {
+			return fmt.Errorf("maze world: %w", err)
+		}
+	}
+	return nil
+}
+
+func init() {
+	gob.Register(MoveRequest{})
+	gob.Register(MoveResult{})
+	gob.Register(moveExpired{})
+	gob.Register(&MazeMemory{}) // Memory волкера - інтерфейс
+	mas.RegisterType("MazeAgent", func() mas.Agent { return &MazeAgent{WalkerPos: Start} })
+	mas.RegisterType("PlannerWalker", func() mas.Agent { return &PlannerWalker{CurrentState: Start} })
+}
@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2/data/binding"
+	"github.com/youryharchenko/go-mas/command"
+	"github.com/youryharchenko/go-mas/mas"
+)
+
+// UseCommands підключає мову команд до поля вводу консолі:
+// Enter виконує рядок від імені cred (mas.WithTrustedIdentity), Tab доповнює ID агентів і команди.
+// Відправлені повідомлення, довідка й помилки пишуться в журнал out.
+func (e *HistoryEntry) UseCommands(sys *mas.System, cred mas.Credential, lang *command.Language, out binding.String) {
+	from := cred.ID()
+	ctx, err := sys.Authenticate(context.Background(), cred)
+	if err != nil {
+		// Без довіри консоль працює анонімно: правила доступу для from її не пропустять
+		AppendLog(out, fmt.Sprintf("[Error]: %v", err))
+		ctx = context.Background()
+	}
+
+	e.OnSubmitted = func(text string) {
+		if strings.TrimSpace(text) == "" {
+			return
+		}
+		e.AddCommand(text)
+
+		lines, err := lang.Execute(ctx, sys, from, text)
+		for _, line := range lines {
+			AppendLog(out, line)
+		}
+		if err != nil {
+			AppendLog(out, fmt.Sprintf("[Error]: %v", err))
+			return
+		}
+		e.SetText("")
+	}
+
+	e.Completer = func(text string) []string {
+		return lang.Complete(text, sys.AgentIDs())
+	}
+	e.OnCandidates = func(candidates []string) {
+		AppendLog(out, strings.Join(candidates, "  "))
+	}
+}
+
+// AppendLog безпечно додає рядок до журналу, обрізаючи надто довгий початок.
+func AppendLog(data binding.String, text string) {
+	current, _ := data.Get()
+	if len(current) > 5000 {
+		current = current[len(current)-4000:] // залишаємо останні 4000 символів
+	}
+	data.Set(current + "\n" + text)
+}
@@ -3,6 +3,7 @@ package ui
 import (
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/widget"
+	"github.com/youryharchenko/go-mas/command"
 )
 
 // HistoryEntry - поле вводу, що пам'ятає історію команд
@@ -10,6 +11,11 @@ type HistoryEntry struct {
 	widget.Entry
 	history []string // Список попередніх команд
 	pointer int      // Вказівник на поточну позицію в історії
+
+	// Completer повертає варіанти доповнення рядка для Tab (повні рядки).
+	// Якщо варіантів кілька, вони передаються в OnCandidates.
+	Completer    func(text string) []string
+	OnCandidates func(candidates []string)
 }
 
 func NewHistoryEntry() *HistoryEntry {
@@ -63,12 +69,43 @@ func (e *HistoryEntry) TypedKey(key *fyne.KeyEvent) {
 			e.setTextAndMoveCursor("")
 		}
 
+	case fyne.KeyTab:
+		if e.Completer == nil {
+			e.Entry.TypedKey(key)
+			return
+		}
+		e.complete()
+
 	default:
 		// Для всіх інших клавіш працюємо як звичайний Entry
 		e.Entry.TypedKey(key)
 	}
 }
 
+// AcceptsTab - з Completer Tab доповнює рядок, а не переводить фокус.
+func (e *HistoryEntry) AcceptsTab() bool {
+	return e.Completer != nil
+}
+
+// complete доповнює рядок: єдиний варіант - повністю (з пробілом у кінці),
+// кілька - до спільного початку, а самі варіанти показує через OnCandidates.
+func (e *HistoryEntry) complete() {
+	candidates := e.Completer(e.Text)
+	switch len(candidates) {
+	case 0:
+		return
+	case 1:
+		e.setTextAndMoveCursor(candidates[0] + " ")
+	default:
+		if prefix := command.CommonPrefix(candidates); len(prefix) > len(e.Text) {
+			e.setTextAndMoveCursor(prefix)
+		}
+		if e.OnCandidates != nil {
+			e.OnCandidates(candidates)
+		}
+	}
+}
+
 // setTextAndMoveCursor ставить текст і переміщує курсор в кінець
 func (e *HistoryEntry) setTextAndMoveCursor(text string) {
 	e.SetText(text)
@@ -29,6 +29,9 @@ func NewLogWindowAgent(id string, data binding.String) *LogWindowAgent {
 func (g *LogWindowAgent) Plan(ctx context.Context, msg mas.Envelope) ([]mas.Action, error) {
 	// Всі повідомлення, що приходять, ми просто відображаємо
 	text := fmt.Sprintf("[%s -> %s]: %+v", msg.From, g.IDVal, msg.Payload)
+	if msg.Type != "" {
+		text = fmt.Sprintf("[%s -> %s] %s: %+v", msg.From, g.IDVal, msg.Type, msg.Payload)
+	}
 
 	// Оновлюємо GUI через binding (це Thread-Safe у Fyne)
 	current, _ := g.output.Get()
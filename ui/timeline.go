@@ -0,0 +1,188 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"github.com/youryharchenko/go-mas/mas"
+	"github.com/youryharchenko/go-mas/replay"
+)
+
+// Timeline - стрічка часу для replay.Replayer: покрокове відтворення запису,
+// точки зупину, перехід до будь-якого запису і стан агента в цій точці.
+type Timeline struct {
+	widget.BaseWidget
+
+	rep *replay.Replayer
+
+	status *widget.Label
+	slider *widget.Slider
+	list   *widget.List
+	breaks *widget.Entry
+	state  *widget.Entry
+
+	content fyne.CanvasObject
+}
+
+func NewTimeline(rep *replay.Replayer) *Timeline {
+	t := &Timeline{rep: rep}
+	t.ExtendBaseWidget(t)
+
+	t.status = widget.NewLabel("")
+	t.state = widget.NewMultiLineEntry()
+	t.state.TextStyle = fyne.TextStyle{Monospace: true}
+	t.state.Wrapping = fyne.TextWrapOff
+
+	t.slider = widget.NewSlider(0, float64(max(len(rep.Records), 1)))
+	t.slider.Step = 1
+	t.slider.OnChangeEnded = func(v float64) {
+		t.rep.Seek(int(v))
+		t.update()
+	}
+
+	t.list = widget.NewList(
+		func() int { return len(t.rep.Records) },
+		func() fyne.CanvasObject {
+			l := widget.NewLabel("")
+			l.TextStyle = fyne.TextStyle{Monospace: true}
+			return l
+		},
+		func(i widget.ListItemID, o fyne.CanvasObject) {
+			o.(*widget.Label).SetText(t.row(i))
+		},
+	)
+	// Вибір рядка - перехід у стан одразу після цього запису
+	t.list.OnSelected = func(i widget.ListItemID) {
+		if t.rep.Pos() != i+1 {
+			t.rep.Seek(i + 1)
+		}
+		t.update()
+	}
+
+	t.breaks = widget.NewEntry()
+	t.breaks.PlaceHolder = "Breakpoints: walker-* MoveRequest #42"
+	t.breaks.OnSubmitted = func(string) { t.setBreakpoints() }
+
+	toolbar := container.NewHBox(
+		widget.NewButton("Reset", func() {
+			t.rep.Reset()
+			t.update()
+		}),
+		widget.NewButton("Step", func() {
+			t.rep.Step()
+			t.update()
+		}),
+		widget.NewButton("Continue", func() {
+			t.setBreakpoints()
+			t.rep.Continue()
+			t.update()
+		}),
+		t.status,
+	)
+
+	top := container.NewVBox(toolbar, t.slider, t.breaks)
+	split := container.NewHSplit(t.list, t.state)
+	split.Offset = 0.6
+	t.content = container.NewBorder(top, nil, nil, nil, split)
+
+	t.update()
+	return t
+}
+
+func (t *Timeline) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(t.content)
+}
+
+// setBreakpoints розбирає поле точок зупину: "#N" - запис N,
+// інше - маска ID агента або payload (як у правилах доступу).
+func (t *Timeline) setBreakpoints() {
+	t.rep.ClearBreakpoints()
+	for _, field := range strings.Fields(t.breaks.Text) {
+		if seq, err := strconv.Atoi(strings.TrimPrefix(field, "#")); err == nil && strings.HasPrefix(field, "#") {
+			t.rep.Break(replay.AtSeq(seq))
+			continue
+		}
+		onAgent := replay.OnAgent(field)
+		onMessage := replay.OnMessage(mas.PayloadLike(field))
+		t.rep.Break(func(rec replay.Record) bool { return onAgent(rec) || onMessage(rec) })
+	}
+}
+
+// row - рядок списку: позначка кроку, номер, агент, відправник і payload.
+func (t *Timeline) row(i int) string {
+	rec := t.rep.Records[i]
+	mark := " "
+	if steps := t.rep.Steps(); i < len(steps) {
+		switch {
+		case steps[i].Skipped != "":
+			mark = "-"
+		case steps[i].Diverged():
+			mark = "!"
+		default:
+			mark = "✓"
+		}
+	}
+	if i == t.rep.Pos() {
+		mark = ">"
+	}
+	return fmt.Sprintf("%s %4d %-12s <- %-12s %v", mark, rec.Seq, rec.Agent, rec.Envelope.From, rec.Envelope.Payload)
+}
+
+// update оновлює статус, повзунок, список і стан агента останнього відтвореного запису.
+func (t *Timeline) update() {
+	pos, total := t.rep.Pos(), len(t.rep.Records)
+	status := fmt.Sprintf("%d/%d", pos, total)
+	if n := len(t.rep.Divergences()); n > 0 {
+		status += fmt.Sprintf(", diverged: %d", n)
+	}
+	if pos > 0 {
+		if step := t.rep.Steps()[pos-1]; step.Skipped != "" {
+			status += ", skipped: " + step.Skipped
+		}
+	}
+	t.status.SetText(status)
+	t.slider.SetValue(float64(pos))
+	t.list.Refresh()
+
+	if pos == 0 {
+		t.state.SetText("")
+		return
+	}
+	t.state.SetText(t.describe(pos - 1))
+}
+
+// describe - хеші запису і JSON поточного стану його агента.
+func (t *Timeline) describe(i int) string {
+	rec := t.rep.Records[i]
+	step := t.rep.Steps()[i]
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "#%d %s %s\n", rec.Seq, rec.Time.Format("15:04:05.000"), rec.Agent)
+	fmt.Fprintf(&b, "recorded: %s -> %s\n", rec.Pre, rec.Post)
+	fmt.Fprintf(&b, "replayed: %s -> %s\n", step.Pre, step.Post)
+	if step.Diverged() {
+		b.WriteString("DIVERGED\n")
+	}
+	for _, env := range step.Sent {
+		fmt.Fprintf(&b, "sent: %s %v\n", env.To, env.Payload)
+	}
+
+	agent, err := t.rep.State(rec.Agent)
+	if err != nil {
+		fmt.Fprintf(&b, "\n%v\n", err)
+		return b.String()
+	}
+	data, err := json.MarshalIndent(agent, "", "  ")
+	if err != nil {
+		fmt.Fprintf(&b, "\n%v\n", err)
+		return b.String()
+	}
+	b.WriteString("\n")
+	b.Write(data)
+	return b.String()
+}